@@ -14,6 +14,10 @@ type Input struct {
 	AccountType string
 	RcDBRoot    string
 	Output      string
+	DBCount     int
+	DBType      string
+	Format      string
+	Input       string
 }
 
 const (
@@ -24,6 +28,9 @@ const (
 	RCDBRootUsage    = "path of RC DB"
 	HelpMsgUsage     = "Print help message"
 	OutputUsage      = "Path of output file"
+	DBCountUsage     = "New number of account DB (MAX:256)"
+	DBTypeUsage      = "Backend of RC DB"
+	FormatUsage      = "Format of account file. jsonl or csv"
 )
 
 func InitManageInput(flagSet *flag.FlagSet) *Input {
@@ -141,6 +148,54 @@ func InitIScoreInput(flagSet *flag.FlagSet) *Input {
 	return input
 }
 
+func InitReshardInput(flagSet *flag.FlagSet) *Input {
+	input := new(Input)
+	flagSet.StringVar(&input.RcDBRoot, "dbroot", "", RCDBRootUsage)
+	flagSet.StringVar(&input.RcDBRoot, "d", "", RCDBRootUsage)
+	flagSet.IntVar(&input.DBCount, "count", 0, DBCountUsage)
+	flagSet.IntVar(&input.DBCount, "c", 0, DBCountUsage)
+	flagSet.StringVar(&input.DBType, "dbtype", "goleveldb", DBTypeUsage)
+	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
+	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
+	return input
+}
+
+func InitExportInput(flagSet *flag.FlagSet) *Input {
+	input := new(Input)
+	AccountExportTypeUsage := "Type of account DB to export. query or calculate"
+	flagSet.StringVar(&input.RcDBRoot, "dbroot", "", RCDBRootUsage)
+	flagSet.StringVar(&input.RcDBRoot, "d", "", RCDBRootUsage)
+	flagSet.StringVar(&input.AccountType, "type", "query", AccountExportTypeUsage)
+	flagSet.StringVar(&input.AccountType, "t", "query", AccountExportTypeUsage)
+	flagSet.StringVar(&input.Format, "format", "jsonl", FormatUsage)
+	flagSet.StringVar(&input.Format, "f", "jsonl", FormatUsage)
+	flagSet.StringVar(&input.Output, "output", "", OutputUsage)
+	flagSet.StringVar(&input.Output, "o", "", OutputUsage)
+	flagSet.StringVar(&input.DBType, "dbtype", "goleveldb", DBTypeUsage)
+	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
+	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
+	return input
+}
+
+func InitImportInput(flagSet *flag.FlagSet) *Input {
+	input := new(Input)
+	InputUsage := "Path of account file to import"
+	ImportDBRootUsage := "path of new RC DB"
+	ImportDBCountUsage := "The number of account DB of new RC DB (MAX:256)"
+	flagSet.StringVar(&input.RcDBRoot, "dbroot", "", ImportDBRootUsage)
+	flagSet.StringVar(&input.RcDBRoot, "d", "", ImportDBRootUsage)
+	flagSet.StringVar(&input.Input, "input", "", InputUsage)
+	flagSet.StringVar(&input.Input, "i", "", InputUsage)
+	flagSet.StringVar(&input.Format, "format", "jsonl", FormatUsage)
+	flagSet.StringVar(&input.Format, "f", "jsonl", FormatUsage)
+	flagSet.IntVar(&input.DBCount, "count", 2, ImportDBCountUsage)
+	flagSet.IntVar(&input.DBCount, "c", 2, ImportDBCountUsage)
+	flagSet.StringVar(&input.DBType, "dbtype", "goleveldb", DBTypeUsage)
+	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
+	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
+	return input
+}
+
 func ValidateInput(flagSet *flag.FlagSet, err error, flag bool) {
 	if err != nil {
 		flagSet.PrintDefaults()
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"time"
+
+	"github.com/icon-project/rewardcalculator/core"
 )
 
 const (
@@ -51,6 +53,8 @@ func (cli *CLI) Run() {
 
 	createDBCount := createCmd.Int("db", 16, "The number of RC Account DB. (MAX:256)")
 	createAccountCount := createCmd.Int("account", 10000, "The account number of RC Account DB")
+	createNetwork := createCmd.String("network", core.NetworkMainnet,
+		fmt.Sprintf("Network profile of IISS constants %v", core.GetNetworkList()))
 	queryAddress := queryCmd.String("address", "", "Account address")
 	queryTXHash := queryCmd.String("txHash", "", "Transaction hash in hex string.(Optional)")
 	calculateBlockHeight := calculateCmd.Uint64("block", 0, "Block height to calculate, Set 0 if you want current block +1")
@@ -101,7 +105,7 @@ func (cli *CLI) Run() {
 		start := time.Now()
 
 		// create
-		cli.create(dbName, *createDBCount, *createAccountCount)
+		cli.create(dbName, *createDBCount, *createAccountCount, *createNetwork)
 
 		end := time.Now()
 		diff := end.Sub(start)
@@ -9,7 +9,7 @@ import (
 func (cli *CLI) calculate(dbName string, blockHeight uint64, batchCount uint64) {
 	log.Printf("Start calculate DB. name: %s, block height: %d, batch count: %d\n", dbName, blockHeight, batchCount)
 
-	ctx, err := core.NewContext(DBDir, DBType, dbName, 0, "")
+	ctx, err := core.NewContext(DBDir, DBType, dbName, 0, "", nil)
 	if nil != err {
 		log.Printf("Failed to initialize IScore DB")
 		return
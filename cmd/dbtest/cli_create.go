@@ -30,7 +30,7 @@ func createAddress(prefix []byte) (*common.Address, error) {
 	return addr, nil
 }
 
-func createIScoreData(prefix []byte, pRepList []*core.PRepCandidate) *core.IScoreAccount {
+func createIScoreData(prefix []byte, pRepList []*core.PRepCandidate, network *core.NetworkProfile) *core.IScoreAccount {
 	addr, err := createAddress(prefix)
 	if err != nil {
 		fmt.Printf("Failed to create Address err=%+v\n", err)
@@ -43,7 +43,7 @@ func createIScoreData(prefix []byte, pRepList []*core.PRepCandidate) *core.IScor
 	for i := 0; i < core.NumDelegate; i++ {
 		dg := new (core.DelegateData)
 		dg.Address = pRepList[i].Address
-		dg.Delegate.SetUint64(core.MinDelegation)
+		dg.Delegate.SetUint64(network.MinDelegation())
 		ia.Delegations = append(ia.Delegations, dg)
 	}
 	ia.Address = *addr
@@ -53,7 +53,7 @@ func createIScoreData(prefix []byte, pRepList []*core.PRepCandidate) *core.IScor
 	return ia
 }
 
-func createData(bucket db.Bucket, prefix []byte, count int, ctx *core.Context) int {
+func createData(bucket db.Bucket, prefix []byte, count int, ctx *core.Context, network *core.NetworkProfile) int {
 	pRepList := make([]*core.PRepCandidate, core.NumDelegate)
 	i := 0
 	for _, v := range ctx.PRepCandidates {
@@ -66,7 +66,7 @@ func createData(bucket db.Bucket, prefix []byte, count int, ctx *core.Context) i
 
 	// Account
 	for i := 0; i < count; i++ {
-		data := createIScoreData(prefix, pRepList)
+		data := createIScoreData(prefix, pRepList, network)
 		if data == nil {
 			return i
 		}
@@ -78,7 +78,7 @@ func createData(bucket db.Bucket, prefix []byte, count int, ctx *core.Context) i
 }
 
 
-func createAccountDB(dbDir string, dbCount int, entryCount int, ctx *core.Context) {
+func createAccountDB(dbDir string, dbCount int, entryCount int, ctx *core.Context, network *core.NetworkProfile) {
 	dbEntryCount := entryCount / dbCount
 	totalCount := 0
 
@@ -90,7 +90,7 @@ func createAccountDB(dbDir string, dbCount int, entryCount int, ctx *core.Contex
 		go func(index int) {
 			bucket, _ := aDBList[index].GetBucket(db.PrefixIScore)
 
-			count := createData(bucket, []byte(strconv.FormatInt(int64(index), 16)), dbEntryCount, ctx)
+			count := createData(bucket, []byte(strconv.FormatInt(int64(index), 16)), dbEntryCount, ctx, network)
 
 			fmt.Printf("Create DB %d with %d entries.\n", index, count)
 			totalCount += count
@@ -104,8 +104,14 @@ func createAccountDB(dbDir string, dbCount int, entryCount int, ctx *core.Contex
 	fmt.Printf("Create %d DBs with total %d/%d entries.\n", dbCount, totalCount, entryCount)
 }
 
-func (cli *CLI) create(dbName string, dbCount int, entryCount int) {
-	fmt.Printf("Start create DB. name: %s, DB count: %d, Account count: %d\n", dbName, dbCount, entryCount)
+func (cli *CLI) create(dbName string, dbCount int, entryCount int, networkName string) {
+	fmt.Printf("Start create DB. name: %s, DB count: %d, Account count: %d, network: %s\n",
+		dbName, dbCount, entryCount, networkName)
+	network, err := core.GetNetworkProfile(networkName)
+	if err != nil {
+		fmt.Printf("Failed to get network profile. %v\n", err)
+		return
+	}
 	dbDir := filepath.Join(DBDir, dbName)
 	os.MkdirAll(dbDir, os.ModePerm)
 
@@ -115,8 +121,8 @@ func (cli *CLI) create(dbName string, dbCount int, entryCount int) {
 	gvList := make([]*core.GovernanceVariable, 0)
 	gv := new(core.GovernanceVariable)
 	gv.BlockHeight = 0
-	gv.MainPRepCount.SetUint64(core.NumMainPRep)
-	gv.SubPRepCount.SetUint64(core.NumSubPRep)
+	gv.MainPRepCount.SetUint64(network.NumMainPRep)
+	gv.SubPRepCount.SetUint64(network.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(1)
 	gv.RewardRep.SetUint64(1)
 	gvList = append(gvList, gv)
@@ -166,8 +172,12 @@ func (cli *CLI) create(dbName string, dbCount int, entryCount int) {
 
 	lvlDB.Close()
 
-	ctx, _ := core.NewContext(DBDir, DBType, dbName, dbCount, "")
+	ctx, err := core.NewContext(DBDir, DBType, dbName, dbCount, "", network)
+	if err != nil {
+		fmt.Printf("Failed to initialize IScore DB. %v\n", err)
+		return
+	}
 
 	// create account DB
-	createAccountDB(dbDir, dbCount, entryCount, ctx)
+	createAccountDB(dbDir, dbCount, entryCount, ctx, network)
 }
@@ -12,7 +12,7 @@ func (cli *CLI) query(dbName string, key string, txHash []byte) {
 	fmt.Printf("Query account. DB name: %s Address: %s TXHash: %s\n",
 		dbName, key, hex.EncodeToString(txHash))
 
-	ctx, err := core.NewContext(DBDir, DBType, dbName, 0, "")
+	ctx, err := core.NewContext(DBDir, DBType, dbName, 0, "", nil)
 	if nil != err {
 		log.Printf("Failed to initialize IScore DB")
 		return
@@ -20,6 +20,10 @@ const (
 	DBNameCalcDebugResult = "calcDebug"
 	DBNameIScore          = "iscore"
 
+	CmdReshard = "reshard"
+	CmdExport  = "export"
+	CmdImport  = "import"
+
 	DataTypeGV     = "gv"
 	DataTypePRep   = "prep"
 	DataTypeTX     = "tx"
@@ -45,6 +49,12 @@ func printUsage() {
 		DBNameCalcDebugResult,
 		DBNameIScore,
 	)
+	fmt.Printf("       %s %s [[options]]\n", os.Args[0], CmdReshard)
+	fmt.Printf("       %s %s [[options]]\n", os.Args[0], CmdExport)
+	fmt.Printf("       %s %s [[options]]\n", os.Args[0], CmdImport)
+	fmt.Printf("\t %s     Redistribute accounts to new number of account DB. Stop icon_rc before running it\n", CmdReshard)
+	fmt.Printf("\t %s      Write all accounts with claimable I-Score to JSON Lines or CSV file. Stop icon_rc before running it\n", CmdExport)
+	fmt.Printf("\t %s      Write accounts in JSON Lines or CSV file to new RC DB\n", CmdImport)
 }
 
 func validateArgs() (err error) {
@@ -82,6 +92,9 @@ func Run() (err error) {
 	iissFlagSet := flag.NewFlagSet(DBNameIISS, flag.ExitOnError)
 	calcDebugFlagSet := flag.NewFlagSet(DBNameCalcDebugResult, flag.ExitOnError)
 	iScoreFlagSet := flag.NewFlagSet(DBNameIScore, flag.ExitOnError)
+	reshardFlagSet := flag.NewFlagSet(CmdReshard, flag.ExitOnError)
+	exportFlagSet := flag.NewFlagSet(CmdExport, flag.ExitOnError)
+	importFlagSet := flag.NewFlagSet(CmdImport, flag.ExitOnError)
 
 	manageInput := common.InitManageInput(manageFlagSet)
 	accountInput := common.InitAccountInput(accountFlagSet)
@@ -92,6 +105,9 @@ func Run() (err error) {
 	iissInput := common.InitIISS(iissFlagSet)
 	calcDebugInput := common.InitCalcDebugResult(calcDebugFlagSet)
 	iScoreInput := common.InitIScoreInput(iScoreFlagSet)
+	reshardInput := common.InitReshardInput(reshardFlagSet)
+	exportInput := common.InitExportInput(exportFlagSet)
+	importInput := common.InitImportInput(importFlagSet)
 
 	switch dbName {
 	case DBNameManagement:
@@ -130,6 +146,18 @@ func Run() (err error) {
 		err = iScoreFlagSet.Parse(os.Args[2:])
 		common.ValidateInput(iScoreFlagSet, err, iScoreInput.Help)
 		err = getIScore(*iScoreInput)
+	case CmdReshard:
+		err = reshardFlagSet.Parse(os.Args[2:])
+		common.ValidateInput(reshardFlagSet, err, reshardInput.Help)
+		err = reshardAccountDB(*reshardInput)
+	case CmdExport:
+		err = exportFlagSet.Parse(os.Args[2:])
+		common.ValidateInput(exportFlagSet, err, exportInput.Help)
+		err = exportAccounts(*exportInput)
+	case CmdImport:
+		err = importFlagSet.Parse(os.Args[2:])
+		common.ValidateInput(importFlagSet, err, importInput.Help)
+		err = importAccounts(*importInput)
 	default:
 		printUsage()
 		err = errors.New("invalid dbName")
@@ -27,7 +27,7 @@ func queryManagementDB(input cmdCommon.Input) (err error) {
 			return
 		}
 		fmt.Println("\n============== Governance variables ==============")
-		if err = cmdCommon.IteratePrintDB(qdb, util.BytesPrefix([]byte(db.PrefixGovernanceVariable)), printGV); err != nil {
+		if err = queryGV(qdb); err != nil {
 			return
 		}
 		fmt.Println("\n============== P-Rep ==============")
@@ -43,7 +43,7 @@ func queryManagementDB(input cmdCommon.Input) (err error) {
 			return
 		}
 	case DataTypeGV:
-		if err = cmdCommon.IteratePrintDB(qdb, util.BytesPrefix([]byte(db.PrefixGovernanceVariable)), printGV); err != nil {
+		if err = queryGV(qdb); err != nil {
 			return
 		}
 	case DataTypePRep:
@@ -114,14 +114,21 @@ func getPC(qdb db.Database, address *common.Address) (*core.PRepCandidate, error
 
 }
 
-func printGV(key []byte, value []byte) error {
-	gv := new(core.GovernanceVariable)
-	if err := gv.SetBytes(value); err != nil {
-		fmt.Printf("Error while initialize GovernanceVariable")
+// queryGV prints GVs with rewards of the network profile of I-Score DB
+func queryGV(qdb db.Database) error {
+	np, err := core.ReadNetworkProfile(qdb)
+	if err != nil {
+		fmt.Printf("Error while read network profile")
 		return err
 	}
-	gv.BlockHeight = common.BytesToUint64(key[len(db.PrefixGovernanceVariable):])
-	fmt.Println(gv.String())
+	gvList, err := core.LoadGovernanceVariable(qdb, np)
+	if err != nil {
+		fmt.Printf("Error while load GovernanceVariable")
+		return err
+	}
+	for _, gv := range gvList {
+		fmt.Println(gv.String())
+	}
 	return nil
 }
 
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/icon-project/rewardcalculator/cmd/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+func exportAccounts(input common.Input) error {
+	if input.RcDBRoot == "" {
+		fmt.Println("Enter RC DB root path")
+		return errors.New("invalid db path")
+	}
+	if !db.HasBackend(input.DBType) {
+		fmt.Printf("Enter DB type. %v\n", db.GetBackendList())
+		return errors.New("invalid db type")
+	}
+	outputFile := "accounts." + input.Format
+	if input.Output != "" {
+		outputFile = input.Output
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := core.NewAccountWriter(input.Format, f)
+	if err != nil {
+		return err
+	}
+
+	dir, name := filepath.Split(filepath.Clean(input.RcDBRoot))
+	count, err := core.ExportAccounts(dir, input.DBType, name, input.AccountType, w)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Write %d %s accounts to %s\n", count, input.AccountType, outputFile)
+	return nil
+}
+
+func importAccounts(input common.Input) error {
+	if input.RcDBRoot == "" {
+		fmt.Println("Enter new RC DB root path")
+		return errors.New("invalid db path")
+	}
+	if input.Input == "" {
+		fmt.Println("Enter account file path")
+		return errors.New("invalid input path")
+	}
+	if !db.HasBackend(input.DBType) {
+		fmt.Printf("Enter DB type. %v\n", db.GetBackendList())
+		return errors.New("invalid db type")
+	}
+
+	f, err := os.Open(input.Input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := core.NewAccountReader(input.Format, f)
+	if err != nil {
+		return err
+	}
+
+	dir, name := filepath.Split(filepath.Clean(input.RcDBRoot))
+	count, err := core.ImportAccounts(dir, input.DBType, name, input.DBCount, r)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Write %d accounts in %s to %s\n", count, input.Input, input.RcDBRoot)
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/icon-project/rewardcalculator/cmd/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+func reshardAccountDB(input common.Input) error {
+	if input.RcDBRoot == "" {
+		fmt.Println("Enter RC DB root path")
+		return errors.New("invalid db path")
+	}
+	if !db.HasBackend(input.DBType) {
+		fmt.Printf("Enter DB type. %v\n", db.GetBackendList())
+		return errors.New("invalid db type")
+	}
+
+	dir, name := filepath.Split(filepath.Clean(input.RcDBRoot))
+	if err := core.ReshardAccountDB(dir, input.DBType, name, input.DBCount); err != nil {
+		return err
+	}
+	fmt.Printf("Reshard account DBs in %s to %d account DBs\n", input.RcDBRoot, input.DBCount)
+	return nil
+}
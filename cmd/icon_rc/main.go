@@ -10,8 +10,8 @@ import (
 	"syscall"
 
 	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
 	"github.com/icon-project/rewardcalculator/core"
-	"github.com/natefinch/lumberjack"
 )
 
 var (
@@ -19,46 +19,134 @@ var (
 	build   = "unknown"
 )
 
+// setConfigFlags defines flags for configuration. Current values of cfg are used as default values.
+func setConfigFlags(fs *flag.FlagSet, cfg *core.RcConfig) {
+	fs.StringVar(&cfg.IISSDataDir, "iissdata", cfg.IISSDataDir, "IISS Data directory")
+	fs.StringVar(&cfg.DBDir, "db", cfg.DBDir, "I-Score database directory")
+	fs.StringVar(&cfg.IpcNet, "ipc-net", cfg.IpcNet, "IPC channel network type")
+	fs.StringVar(&cfg.IpcAddr, "ipc-addr", cfg.IpcAddr, "IPC channel address")
+	fs.StringVar(&cfg.IpcTLSCert, "ipc-tls-cert", cfg.IpcTLSCert, "Certificate file of TLS for tcp IPC channel")
+	fs.StringVar(&cfg.IpcTLSKey, "ipc-tls-key", cfg.IpcTLSKey, "Private key file of TLS certificate")
+	fs.StringVar(&cfg.IpcTLSCA, "ipc-tls-ca", cfg.IpcTLSCA, "CA certificate file to verify certificate of peer")
+	fs.StringVar(&cfg.IpcTLSServerName, "ipc-tls-server-name", cfg.IpcTLSServerName,
+		"Name in certificate of ICON Service for client mode. Host of IPC address if empty")
+	fs.StringVar(&cfg.IpcAuthKeys, "ipc-auth-keys", cfg.IpcAuthKeys,
+		"Comma separated public keys of ICON Service. Mutating messages must be signed with them. Disabled if empty")
+	fs.StringVar(&cfg.NodeKey, "node-key", cfg.NodeKey,
+		"File of private key in hex to sign calculation results. Results are not signed if empty")
+	fs.StringVar(&cfg.FileName, "config", cfg.FileName, "Reward Calculator configuration file")
+	fs.BoolVar(&cfg.ClientMode, "client", cfg.ClientMode, "Connect to ICON Service")
+	fs.BoolVar(&cfg.Monitor, "monitor", cfg.Monitor, "Open monitoring channel")
+	fs.IntVar(&cfg.DBCount, "db-count", cfg.DBCount, "The number of Account DB (MAX:256)")
+	fs.StringVar(&cfg.DBType, "db-type", cfg.DBType,
+		fmt.Sprintf("I-Score database backend. %v", db.GetBackendList()))
+	fs.StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "Log file name")
+	fs.IntVar(&cfg.LogMaxSize, "log-max-size", cfg.LogMaxSize, "MAX size of log file in megabytes")
+	fs.IntVar(&cfg.LogMaxBackups, "log-max-backups", cfg.LogMaxBackups, "MAX number of old log files")
+	fs.StringVar(&cfg.CalcDebugConf, "calculate-debug-conf", cfg.CalcDebugConf,
+		"calculation debug config file path")
+	fs.StringVar(&cfg.MetricsAddr, "metrics-addr", cfg.MetricsAddr,
+		"Address to serve metrics for Prometheus. ex) :9090. Disabled if empty")
+	fs.BoolVar(&cfg.RewardLedger, "reward-ledger", cfg.RewardLedger,
+		"Keep Beta1, Beta2 and Beta3 I-Score of each account for each calculation")
+	fs.IntVar(&cfg.BackupCount, "backup-count", cfg.BackupCount,
+		"The number of calculations to keep account DB backup. Rollback is possible within this number of terms")
+	fs.StringVar(&cfg.Network, "network", cfg.Network,
+		fmt.Sprintf("Network profile of IISS constants %v. Set NetworkProfile in configuration file for others",
+			core.GetNetworkList()))
+}
+
+func defaultConfig() core.RcConfig {
+	return core.RcConfig{
+		IISSDataDir:   "./iissdata",
+		DBDir:         ".iscoredb",
+		IpcNet:        "unix",
+		IpcAddr:       "/tmp/icon-rc.sock",
+		FileName:      "rc_config.json",
+		ClientMode:    false,
+		Monitor:       false,
+		DBCount:       2,
+		DBType:        string(db.GoLevelDBBackend),
+		BackupCount:   1,
+		Network:       core.NetworkMainnet,
+		LogFile:       "icon_rc.log",
+		LogMaxSize:    10,
+		LogMaxBackups: 10,
+		CalcDebugConf: "./calculation_debug.json",
+	}
+}
+
+// loadConfig makes configuration with precedence. default < configuration file < environment variable < flag
+func loadConfig(fileName string, optionalFile bool) (*core.RcConfig, error) {
+	cfg := defaultConfig()
+	cfg.FileName = fileName
+
+	// configuration file
+	if err := cfg.Load(fileName); err != nil {
+		if !optionalFile || !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	// environment variable
+	if err := cfg.LoadEnv(); err != nil {
+		return nil, err
+	}
+
+	// flags set explicitly
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	setConfigFlags(fs, &cfg)
+	var err error
+	flag.Visit(func(f *flag.Flag) {
+		if fs.Lookup(f.Name) == nil || err != nil {
+			return
+		}
+		err = fs.Set(f.Name, f.Value.String())
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.FileName = fileName
+
+	return &cfg, cfg.Validate()
+}
+
 func main() {
-	var cfg core.RcConfig
 	var generate bool
 	var optVersion bool
 
-	flag.StringVar(&cfg.IISSDataDir, "iissdata", "./iissdata", "IISS Data directory")
-	flag.StringVar(&cfg.DBDir, "db", ".iscoredb", "I-Score database directory")
-	flag.StringVar(&cfg.IpcNet, "ipc-net", "unix", "IPC channel network type")
-	flag.StringVar(&cfg.IpcAddr, "ipc-addr", "/tmp/icon-rc.sock", "IPC channel address")
-	flag.StringVar(&cfg.FileName, "config", "rc_config.json", "Reward Calculator configuration file")
-	flag.BoolVar(&cfg.ClientMode, "client", false, "Connect to ICON Service")
-	flag.BoolVar(&cfg.Monitor, "monitor", false, "Open monitoring channel")
-	flag.IntVar(&cfg.DBCount, "db-count", 2, "The number of Account DB (MAX:256)")
-	flag.StringVar(&cfg.LogFile, "log-file", "icon_rc.log", "Log file name")
-	flag.IntVar(&cfg.LogMaxSize, "log-max-size", 10, "MAX size of log file in megabytes")
-	flag.IntVar(&cfg.LogMaxBackups, "log-max-backups", 10, "MAX number of old log files")
+	flagCfg := defaultConfig()
+	setConfigFlags(flag.CommandLine, &flagCfg)
 	flag.BoolVar(&generate, "gen", false, "Generate configuration file")
 	flag.BoolVar(&optVersion, "version", false, "Print version information")
-	flag.StringVar(&cfg.CalcDebugConf, "calculate-debug-conf", "./calculation_debug.json",
-		"calculation debug config file path")
 	flag.Parse()
 
-	log.SetFlags(log.Ldate | log.Lmicroseconds | log.Lshortfile)
-	log.SetOutput(&lumberjack.Logger{
-		Filename:   cfg.LogFile,
-		MaxSize:    cfg.LogMaxSize,
-		MaxBackups: cfg.LogMaxBackups,
-		LocalTime:  true,
-	})
-	common.SetLog(cfg.LogFile, cfg.LogMaxSize, cfg.LogMaxBackups, true)
-
 	if optVersion {
 		fmt.Printf("icon_rc %s, %s\n", version, build)
 		os.Exit(0)
 	}
 
-	if generate {
-		if len(cfg.FileName) == 0 {
-			cfg.FileName = "rc_config.json"
+	if len(flagCfg.FileName) == 0 {
+		flagCfg.FileName = "rc_config.json"
+	}
+
+	// configuration file is optional if it was not specified with flag or will be generated
+	optionalFile := true
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			optionalFile = generate
 		}
+	})
+
+	cfg, err := loadConfig(flagCfg.FileName, optionalFile)
+	if err != nil {
+		fmt.Printf("Invalid configuration. %v\n", err)
+		os.Exit(1)
+	}
+
+	common.SetLog(cfg.LogFile, cfg.LogMaxSize, cfg.LogMaxBackups, true)
+
+	if generate {
 		f, err := os.OpenFile(cfg.FileName,
 			os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
 		if err != nil {
@@ -67,7 +155,7 @@ func main() {
 
 		enc := json.NewEncoder(f)
 		enc.SetIndent("", "  ")
-		if err := enc.Encode(&cfg); err != nil {
+		if err := enc.Encode(cfg); err != nil {
 			log.Panicf("Failed to generate JSON for %+v", cfg)
 		}
 		f.Close()
@@ -77,27 +165,37 @@ func main() {
 	log.Printf("Version : %s", version)
 	log.Printf("Build   : %s", build)
 
+	log.Printf("Configuration file : %s", cfg.FileName)
 	cfg.Print()
 
-	if cfg.DBCount > core.MaxDBCount {
-		fmt.Printf("Too large -db-count %d. MAX: %d\n", cfg.DBCount, core.MaxDBCount)
-	}
-
-	rcm, err := core.InitManager(&cfg)
+	rcm, err := core.InitManager(cfg)
 	sigs := make(chan os.Signal, 1)
 	done := make(chan bool, 1)
 
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	if err != nil {
 		log.Panicf("Failed to start RewardCalculator manager. %+v", err)
 	}
 
 	go func() {
-		sig := <-sigs
-		log.Println("Catch ", sig, "signal")
-		rcm.Close()
-		done <- true
+		for sig := range sigs {
+			log.Println("Catch ", sig, "signal")
+			if sig == syscall.SIGHUP {
+				newCfg, err := loadConfig(cfg.FileName, optionalFile)
+				if err != nil {
+					log.Printf("Failed to reload configuration. %v", err)
+					continue
+				}
+				if err = rcm.Reload(newCfg); err != nil {
+					log.Printf("Failed to reload configuration. %v", err)
+				}
+				continue
+			}
+			rcm.Close()
+			done <- true
+			return
+		}
 	}()
 
 	go rcm.Loop()
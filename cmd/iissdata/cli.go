@@ -58,12 +58,15 @@ func (cli *CLI) Run() {
 	headerVersion := headerCmd.Uint64("version", core.IISSDataVersion, "Version of IISS data")
 	headerRevision := headerCmd.Uint64("revision", core.IISSDataRevisionDefault, "Revision of ICON Service")
 	headerBlockHeight := headerCmd.Uint64("blockheight", 1, "Block height of IISS data")
+	headerRevisionBlockHeight := headerCmd.Uint64("revisionblockheight", 0,
+		"Block height from which revision applies. 0 applies revision to the whole term")
 
 	gvBlockHeight := gvCmd.Uint64("blockheight", 0, "Block height of Governance variable")
 	gvIncentive := gvCmd.Uint64("incentive", 1, "P-Rep incentive in %")
 	gvReward := gvCmd.Uint64("reward", 1, "P-Rep reward in %")
-	gvMainPRepCount := gvCmd.Uint64("mainprepcount", core.NumMainPRep, "Main P-Rep count")
-	gvSubPRepCount := gvCmd.Uint64("subprepcount", core.NumSubPRep, "Sub P-Rep count")
+	mainnet, _ := core.GetNetworkProfile(core.NetworkMainnet)
+	gvMainPRepCount := gvCmd.Uint64("mainprepcount", mainnet.NumMainPRep, "Main P-Rep count")
+	gvSubPRepCount := gvCmd.Uint64("subprepcount", mainnet.NumSubPRep, "Sub P-Rep count")
 
 	bpBlockHeight := bpCmd.Uint64("blockheight", 0, "Block height of Block produce Info.")
 	bpGenerator := bpCmd.String("generator", "", "Address of block generator")
@@ -156,7 +159,7 @@ func (cli *CLI) Run() {
 	defer cli.DB.Close()
 
 	if headerCmd.Parsed() {
-		cli.header(*headerVersion, *headerBlockHeight, *headerRevision)
+		cli.header(*headerVersion, *headerBlockHeight, *headerRevision, *headerRevisionBlockHeight)
 		return
 	}
 
@@ -6,13 +6,14 @@ import (
 	"github.com/icon-project/rewardcalculator/core"
 )
 
-func (cli *CLI) header(version uint64, blockHeight uint64, revision uint64) {
+func (cli *CLI) header(version uint64, blockHeight uint64, revision uint64, revisionBlockHeight uint64) {
 	bucket, _ := cli.DB.GetBucket(db.PrefixIISSHeader)
 
 	header := new(core.IISSHeader)
 	header.Version = version
 	header.BlockHeight = blockHeight
 	header.Revision = revision
+	header.RevisionBlockHeight = revisionBlockHeight
 
 	key := []byte("")
 	value, _ := header.Bytes()
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	"github.com/icon-project/rewardcalculator/common/crypto"
 	"github.com/icon-project/rewardcalculator/common/ipc"
 	"github.com/icon-project/rewardcalculator/core"
 )
@@ -33,8 +37,13 @@ func (cli *CLI) printUsage() {
 	fmt.Printf("\t prepcandidate                 Read P-Rep Candidate list\n")
 	fmt.Printf("\t gv                            Read governance variable\n")
 	fmt.Printf("\t calculate                     Query Calculation status or result\n")
+	fmt.Printf("\t calculate_progress [INTERVAL] Query calculation progress. Watch it every INTERVAL seconds\n")
 	fmt.Printf("\t logctx                        Log context information\n")
 	fmt.Printf("\t calculate_debug               Config calculation debugging\n")
+	fmt.Printf("\t dry_run IISS_DATA_PATH        Calculate with IISS data without writing to DB\n")
+	fmt.Printf("\t connections                   Read live IPC connections\n")
+	fmt.Printf("\t verify-result BLOCK_HEIGHT NODE_PUBLIC_KEY [PUBLIC_KEY:SIGNATURE ...]\n")
+	fmt.Printf("\t                               Verify signature of calculation result and signatures of other RCs\n")
 }
 
 func (cli *CLI) validateArgs() {
@@ -70,6 +79,12 @@ func (cli *CLI) Run() {
 		}
 	}
 
+	// negotiate to get StateRoot and signed result fields
+	if err = cli.negotiate(); err != nil {
+		fmt.Printf("Failed to negotiate IPC version. %v\n", err)
+		os.Exit(1)
+	}
+
 	// Send message to server
 	switch cmd {
 	case "stats":
@@ -93,10 +108,40 @@ func (cli *CLI) Run() {
 			}
 		}
 		err = cli.calculate(blockHeight)
+	case "calculate_progress":
+		interval := uint64(0)
+		if len(os.Args) == 3 {
+			interval, err = strconv.ParseUint(os.Args[2], 10, 64)
+			if err != nil {
+				fmt.Printf("Invalid interval. (%+v)\n", err)
+				os.Exit(1)
+			}
+		}
+		err = cli.calculateProgress(interval)
 	case "logctx":
 		err = cli.logCtx()
 	case "calculate_debug":
 		err = cli.calculateDebug(os.Args[2:])
+	case "dry_run":
+		if len(os.Args) != 3 {
+			cli.printUsage()
+			os.Exit(1)
+		}
+		err = cli.dryRun(os.Args[2])
+	case "connections":
+		err = cli.connections()
+	case "verify-result":
+		if len(os.Args) < 4 {
+			cli.printUsage()
+			os.Exit(1)
+		}
+		var blockHeight uint64
+		blockHeight, err = strconv.ParseUint(os.Args[2], 10, 64)
+		if err != nil {
+			fmt.Printf("Invalid block height. (%+v)\n", err)
+			os.Exit(1)
+		}
+		err = cli.verifyResult(blockHeight, os.Args[3], os.Args[4:])
 	default:
 		cli.printUsage()
 		os.Exit(1)
@@ -104,6 +149,7 @@ func (cli *CLI) Run() {
 
 	if err != nil {
 		fmt.Printf("Failed to handle command. (%+v)\n", err)
+		os.Exit(1)
 	}
 }
 
@@ -195,9 +241,153 @@ func (cli *CLI) calculate(blockHeight uint64) error {
 	return err
 }
 
+func (cli *CLI) calculateProgress(interval uint64) error {
+	req := core.QueryCalculateProgress{NotifyInterval: interval}
+	var resp core.CalculateProgress
+
+	err := cli.conn.SendAndReceive(core.MsgQueryCalculateProgress, cli.id, &req, &resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("QUERY_CALCULATE_PROGRESS command get response:\n%s\n", Display(resp))
+	if interval == 0 {
+		return nil
+	}
+
+	// print CALCULATE_PROGRESS until calculation ends
+	for resp.Status == core.CalculationDoing {
+		resp = core.CalculateProgress{}
+		msg, _, err := cli.conn.Receive(&resp)
+		if err != nil {
+			return err
+		}
+		if msg == core.MsgCalculateProgress {
+			fmt.Printf("%s\n", resp.String())
+		}
+	}
+	return nil
+}
+
 func (cli *CLI) logCtx() error {
 	var req core.DebugMessage
 	req.Cmd = core.DebugLogCTX
 
 	return cli.conn.Send(core.MsgDebug, cli.id, req)
 }
+
+func (cli *CLI) dryRun(path string) error {
+	var req core.DebugMessage
+	req.Cmd = core.DebugDryRun
+	var resp core.ResponseDebugDryRun
+
+	// Reward Calculator opens IISS data with the path
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	req.Path = absPath
+
+	err = cli.conn.SendAndReceive(core.MsgDebug, cli.id, req, &resp)
+	if err == nil {
+		fmt.Printf("dry_run command get response:\n%s\n", Display(resp))
+	}
+
+	return err
+}
+
+func (cli *CLI) connections() error {
+	var req core.DebugMessage
+	req.Cmd = core.DebugConnections
+	var resp core.ResponseDebugConnections
+
+	err := cli.conn.SendAndReceive(core.MsgDebug, cli.id, req, &resp)
+	if err == nil {
+		fmt.Printf("connections command get response:\n%s\n", Display(resp))
+	}
+
+	return err
+}
+
+func (cli *CLI) negotiate() error {
+	req := core.VersionRequest{
+		Version:      core.IPCVersion,
+		MinVersion:   core.IPCMinVersion,
+		Capabilities: core.SupportedCapabilities(),
+	}
+	var resp core.ResponseVersionNegotiation
+	if err := cli.conn.SendAndReceive(core.MsgVersion, cli.id, &req, &resp); err != nil {
+		return err
+	}
+	if resp.Version == 0 {
+		return fmt.Errorf("refused by Reward Calculator. %s", resp.String())
+	}
+	return nil
+}
+
+func parsePublicKey(s string) (*crypto.PublicKey, error) {
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s. %v", s, err)
+	}
+	pubKey, err := crypto.ParsePublicKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key %s. %v", s, err)
+	}
+	return pubKey, nil
+}
+
+// verifyResult verifies that calculation result is signed with nodeKey and checks that other RCs signed the same result
+func (cli *CLI) verifyResult(blockHeight uint64, nodeKey string, peers []string) error {
+	nodePubKey, err := parsePublicKey(nodeKey)
+	if err != nil {
+		return err
+	}
+
+	var resp core.QueryCalculateResultResponse
+	err = cli.conn.SendAndReceive(core.MsgQueryCalculateResult, cli.id, &blockHeight, &resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("QUERY_CALCULATE_RESULT command get response: %s\n", resp.String())
+	if resp.StatusString() != "Succeeded" {
+		return fmt.Errorf("no calculation result of %d. %s", blockHeight, resp.StatusString())
+	}
+
+	ra := resp.Attestation()
+	signer, err := ra.Signer(resp.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature of calculation result. %v", err)
+	}
+	if !signer.Equal(nodePubKey) {
+		return fmt.Errorf("calculation result of %d is signed by %s, not by %s",
+			blockHeight, signer.String(), nodePubKey.String())
+	}
+	fmt.Printf("Signed by %s\n", signer.String())
+
+	agreed := true
+	for _, peer := range peers {
+		ss := strings.SplitN(peer, ":", 2)
+		if len(ss) != 2 {
+			return fmt.Errorf("invalid argument %s. Use PUBLIC_KEY:SIGNATURE", peer)
+		}
+		pubKey, err := parsePublicKey(ss[0])
+		if err != nil {
+			return err
+		}
+		sig, err := hex.DecodeString(strings.TrimPrefix(ss[1], "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid signature %s. %v", ss[1], err)
+		}
+
+		if peerSigner, err := ra.Signer(sig); err == nil && peerSigner.Equal(pubKey) {
+			fmt.Printf("%s: agreed\n", pubKey.String())
+		} else {
+			fmt.Printf("%s: disagreed\n", pubKey.String())
+			agreed = false
+		}
+	}
+	if !agreed {
+		return fmt.Errorf("calculation result of %d is not agreed", blockHeight)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/icon-project/rewardcalculator/common/ipc"
@@ -31,6 +32,10 @@ func (cli *CLI) printUsage() {
 	fmt.Printf("\t version                   Send a VERSION message\n")
 	fmt.Printf("\t init                      Send a INIT message\n")
 	fmt.Printf("\t query                     Send a QUERY message to query I-Score\n")
+	fmt.Printf("\t query_batch               Send a QUERY_BATCH message to query I-Score of many accounts\n")
+	fmt.Printf("\t query_proof               Send a QUERY_PROOF message to get inclusion proof of account in state root\n")
+	fmt.Printf("\t query_estimate            Send a QUERY_ESTIMATE message to estimate I-Score at future block height\n")
+	fmt.Printf("\t query_reward_ledger       Send a QUERY_REWARD_LEDGER message to query Beta1/Beta2/Beta3 I-Score\n")
 	fmt.Printf("\t claim                     Send a CLAIM message to claim I-Score\n")
 	fmt.Printf("\t commitclaim               Send a COMMIT_CLAIM message to commit CLAIM message\n")
 	fmt.Printf("\t commitblock               Send a COMMIT_BLOCK message to commit block\n")
@@ -64,6 +69,22 @@ func (cli *CLI) Run() {
 	queryAddress := queryCmd.String("address", "", "Account address")
 	queryTXHash := queryCmd.String("txHash", "", "Transaction hash in hex string.(Optional)")
 
+	queryBatchCmd := flag.NewFlagSet("query_batch", flag.ExitOnError)
+	queryBatchAddresses := queryBatchCmd.String("addresses", "", "Comma separated account addresses. Query all accounts page by page if it is empty")
+	queryBatchCursor := queryBatchCmd.String("cursor", "", "Next of the previous page in hex.(Optional)")
+	queryBatchLimit := queryBatchCmd.Uint64("limit", 0, "The number of accounts in a page. Set 0 for MAX")
+
+	queryProofCmd := flag.NewFlagSet("query_proof", flag.ExitOnError)
+	queryProofAddress := queryProofCmd.String("address", "", "Account address(Required)")
+
+	queryEstimateCmd := flag.NewFlagSet("query_estimate", flag.ExitOnError)
+	queryEstimateAddress := queryEstimateCmd.String("address", "", "Account address(Required)")
+	queryEstimateBlockHeight := queryEstimateCmd.Uint64("blockheight", 0, "Block height to estimate I-Score(Required)")
+
+	queryRLCmd := flag.NewFlagSet("query_reward_ledger", flag.ExitOnError)
+	queryRLAddress := queryRLCmd.String("address", "", "Account address(Required)")
+	queryRLBlockHeight := queryRLCmd.Uint64("blockheight", 0, "Block height of calculation. Set 0 for the latest calculation")
+
 	claimCmd := flag.NewFlagSet("claim", flag.ExitOnError)
 	claimAddress := claimCmd.String("address", "", "Account address")
 	claimBlockHeight := claimCmd.Uint64("blockheight", 0, "Block height")
@@ -127,6 +148,30 @@ func (cli *CLI) Run() {
 			queryCmd.PrintDefaults()
 			os.Exit(1)
 		}
+	case "query_batch":
+		err := queryBatchCmd.Parse(os.Args[3:])
+		if err != nil {
+			queryBatchCmd.PrintDefaults()
+			os.Exit(1)
+		}
+	case "query_proof":
+		err := queryProofCmd.Parse(os.Args[3:])
+		if err != nil {
+			queryProofCmd.PrintDefaults()
+			os.Exit(1)
+		}
+	case "query_estimate":
+		err := queryEstimateCmd.Parse(os.Args[3:])
+		if err != nil {
+			queryEstimateCmd.PrintDefaults()
+			os.Exit(1)
+		}
+	case "query_reward_ledger":
+		err := queryRLCmd.Parse(os.Args[3:])
+		if err != nil {
+			queryRLCmd.PrintDefaults()
+			os.Exit(1)
+		}
 	case "claim":
 		err := claimCmd.Parse(os.Args[3:])
 		if err != nil {
@@ -265,6 +310,48 @@ func (cli *CLI) Run() {
 		cli.query(conn, *queryAddress, txHash)
 	}
 
+	if queryBatchCmd.Parsed() {
+		var addresses []string
+		if *queryBatchAddresses != "" {
+			addresses = strings.Split(*queryBatchAddresses, ",")
+		}
+		cursor, err := hex.DecodeString(strings.TrimPrefix(*queryBatchCursor, "0x"))
+		if err != nil {
+			fmt.Printf("Invalid cursor %s. %v\n", *queryBatchCursor, err)
+			queryBatchCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		// send QUERY_BATCH message
+		cli.queryBatch(conn, addresses, cursor, *queryBatchLimit)
+	}
+
+	if queryProofCmd.Parsed() {
+		if *queryProofAddress == "" {
+			queryProofCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		// send QUERY_PROOF message
+		cli.queryProof(conn, *queryProofAddress)
+	}
+
+	if queryEstimateCmd.Parsed() {
+		if *queryEstimateAddress == "" || *queryEstimateBlockHeight == 0 {
+			queryEstimateCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		// send QUERY_ESTIMATE message
+		cli.queryEstimate(conn, *queryEstimateAddress, *queryEstimateBlockHeight)
+	}
+
+	if queryRLCmd.Parsed() {
+		if *queryRLAddress == "" {
+			queryRLCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		// send QUERY_REWARD_LEDGER message
+		cli.queryRewardLedger(conn, *queryRLAddress, *queryRLBlockHeight)
+	}
+
 	if calculateCmd.Parsed() {
 		if *calculateIISSData == "" {
 			calculateCmd.PrintDefaults()
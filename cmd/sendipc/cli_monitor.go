@@ -10,6 +10,7 @@ import (
 
 	"github.com/icon-project/rewardcalculator/common"
 	"github.com/icon-project/rewardcalculator/common/ipc"
+	"github.com/icon-project/rewardcalculator/core"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
 )
@@ -50,15 +51,28 @@ func (cli *CLI) monitor(conn ipc.Connection, configFile string, url string) {
 func (cli *CLI) readAndPush(conn ipc.Connection, targets []monitorTarget, url string) {
 	pusher := push.New(url, "icon_rc")
 
-	for _, target := range targets {
+	for start := 0; start < len(targets); start += core.MaxQueryBatchCount {
+		end := start + core.MaxQueryBatchCount
+		if end > len(targets) {
+			end = len(targets)
+		}
+		addresses := make([]string, 0, end-start)
+		for _, target := range targets[start:end] {
+			addresses = append(addresses, target.Address.String())
+		}
+
 		// read IScore from RC
-		resp := cli.query(conn, target.Address.String(), nil)
-		target.IScore.Set(&resp.IScore.Int)
+		resp := cli.queryBatch(conn, addresses, nil, 0)
+		for i, target := range targets[start:end] {
+			if i < len(resp.Results) {
+				target.IScore.Set(&resp.Results[i].IScore.Int)
+			}
 
-		// set metric
-		temp := prometheus.NewGauge(prometheus.GaugeOpts{Name: target.Name})
-		temp.Set(float64(target.IScore.Int.Uint64()))
-		pusher.Collector(temp)
+			// set metric
+			temp := prometheus.NewGauge(prometheus.GaugeOpts{Name: target.Name})
+			temp.Set(float64(target.IScore.Int.Uint64()))
+			pusher.Collector(temp)
+		}
 	}
 
 	if err := pusher.Push(); err != nil {
@@ -20,5 +20,69 @@ func (cli *CLI) query(conn ipc.Connection, address string, txHash []byte) *core.
 	conn.SendAndReceive(core.MsgQuery, cli.id, req, resp)
 	fmt.Printf("QUERY command get response: %s\n", resp.String())
 
+	return resp
+}
+
+func (cli *CLI) queryBatch(conn ipc.Connection, addresses []string, cursor []byte, limit uint64) *core.ResponseQueryBatch {
+	req := &core.QueryBatch{
+		Addresses: make([]common.Address, len(addresses)),
+		Cursor:    cursor,
+		Limit:     limit,
+	}
+	for i, address := range addresses {
+		req.Addresses[i] = *common.NewAddressFromString(address)
+	}
+	resp := new(core.ResponseQueryBatch)
+
+	conn.SendAndReceive(core.MsgQueryBatch, cli.id, req, resp)
+	for _, result := range resp.Results {
+		fmt.Printf("%s\n", result.String())
+	}
+	fmt.Printf("QUERY_BATCH command get response: %s\n", resp.String())
+
+	return resp
+}
+
+func (cli *CLI) queryProof(conn ipc.Connection, address string) *core.ResponseQueryProof {
+	req := &core.QueryProof{
+		Address: *common.NewAddressFromString(address),
+	}
+	resp := new(core.ResponseQueryProof)
+
+	conn.SendAndReceive(core.MsgQueryProof, cli.id, req, resp)
+	for _, node := range resp.Proof {
+		fmt.Printf("%s\n", node.String())
+	}
+	fmt.Printf("QUERY_PROOF command get response: %s, verified: %t\n", resp.String(), resp.Verify())
+
+	return resp
+}
+
+func (cli *CLI) queryEstimate(conn ipc.Connection, address string, blockHeight uint64) *core.ResponseQueryEstimate {
+	req := &core.QueryEstimate{
+		Address:     *common.NewAddressFromString(address),
+		BlockHeight: blockHeight,
+	}
+	resp := new(core.ResponseQueryEstimate)
+
+	conn.SendAndReceive(core.MsgQueryEstimate, cli.id, req, resp)
+	for _, dg := range resp.Delegations {
+		fmt.Printf("Delegation: %s, %s\n", dg.Address.String(), dg.Delegate.String())
+	}
+	fmt.Printf("QUERY_ESTIMATE command get response: %s\n", resp.String())
+
+	return resp
+}
+
+func (cli *CLI) queryRewardLedger(conn ipc.Connection, address string, blockHeight uint64) *core.ResponseQueryRewardLedger {
+	req := &core.QueryRewardLedger{
+		Address:     *common.NewAddressFromString(address),
+		BlockHeight: blockHeight,
+	}
+	resp := new(core.ResponseQueryRewardLedger)
+
+	conn.SendAndReceive(core.MsgQueryRewardLedger, cli.id, req, resp)
+	fmt.Printf("QUERY_REWARD_LEDGER command get response: %s\n", resp.String())
+
 	return resp
 }
\ No newline at end of file
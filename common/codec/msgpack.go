@@ -29,4 +29,7 @@ func init() {
 	mh.StructToArray = true
 	mh.Canonical = true
 	mpCodecObject.handle = mh
+
+	// initialize the handle before it is used by goroutines concurrently
+	ugorji.NewEncoderBytes(new([]byte), mh)
 }
@@ -1,9 +1,12 @@
 package db
 
 import (
+	"bytes"
+	"os"
 	"path/filepath"
 
 	"github.com/dgraph-io/badger"
+	"github.com/dgraph-io/badger/options"
 )
 
 func init() {
@@ -19,8 +22,14 @@ func NewBadgerDB(name string, dir string) (*BadgerDB, error) {
 	opts := badger.DefaultOptions
 	opts.Dir = dbPath
 	opts.ValueDir = dbPath
+	// Reward Calculator opens many DBs. Do not map all files to memory
+	opts.TableLoadingMode = options.FileIO
+	opts.ValueLogLoadingMode = options.FileIO
 
-	// TODO : badger.openDatabase() use os.Mkdir(). parent dirs must be created
+	// badger.openDatabase() use os.Mkdir(). parent dirs must be created
+	if err := os.MkdirAll(dbPath, 0755); err != nil {
+		return nil, err
+	}
 	db, err := badger.Open(opts)
 
 	if err != nil {
@@ -51,15 +60,21 @@ func (db *BadgerDB) GetBucket(id BucketID) (Bucket, error) {
 }
 
 func (db *BadgerDB) GetIterator() (Iterator, error) {
-	return nil, nil
+	return &badgerIterator{
+		db: db.db,
+	}, nil
 }
 
 func (db *BadgerDB) GetBatch() (Batch, error) {
-	return nil, nil
+	return &badgerBatch{
+		db: db.db,
+	}, nil
 }
 
 func (db *BadgerDB) GetSnapshot() (Snapshot, error) {
-	return nil, nil
+	return &badgerSnapshot{
+		db: db.db,
+	}, nil
 }
 
 func (db *BadgerDB) Close() error {
@@ -77,17 +92,26 @@ type badgerBucket struct {
 	db *badger.DB
 }
 
+func badgerGet(txn *badger.Txn, key []byte) ([]byte, error) {
+	item, err := txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	value, err := item.ValueCopy(nil)
+	if err == nil && value == nil {
+		value = []byte{}
+	}
+	return value, err
+}
+
 func (bucket *badgerBucket) Get(key []byte) ([]byte, error) {
 	ikey := internalKey(bucket.id, key)
 	var value []byte
 	err := bucket.db.View(func(txn *badger.Txn) error {
-		item, err := txn.Get(ikey)
-		if err != nil {
-			if err == badger.ErrKeyNotFound {
-				return nil
-			}
-		}
-		value, err = item.Value()
+		var err error
+		value, err = badgerGet(txn, ikey)
 		return err
 	})
 	return value, err
@@ -115,3 +139,220 @@ func (bucket *badgerBucket) Delete(key []byte) error {
 		return txn.Delete(ikey)
 	})
 }
+
+//----------------------------------------
+// DBIterator
+
+// badgerCursor iterates keys in [start, limit) with read-only transaction
+type badgerCursor struct {
+	iter    *badger.Iterator
+	start   []byte
+	limit   []byte
+	started bool
+	key     []byte
+	value   []byte
+	err     error
+}
+
+func (c *badgerCursor) reset(txn *badger.Txn, start []byte, limit []byte) {
+	c.iter = txn.NewIterator(badger.DefaultIteratorOptions)
+	if start == nil {
+		limit = nil
+	}
+	c.start = start
+	c.limit = limit
+	c.started = false
+	c.key = nil
+	c.value = nil
+	c.err = nil
+}
+
+func (c *badgerCursor) next() bool {
+	if !c.started {
+		c.started = true
+		if c.start == nil {
+			c.iter.Rewind()
+		} else {
+			c.iter.Seek(c.start)
+		}
+	} else if c.key != nil {
+		c.iter.Next()
+	}
+
+	if !c.iter.Valid() {
+		c.key = nil
+		c.value = nil
+		return false
+	}
+	item := c.iter.Item()
+	if c.limit != nil && bytes.Compare(item.Key(), c.limit) >= 0 {
+		c.key = nil
+		c.value = nil
+		return false
+	}
+	c.key = item.KeyCopy(nil)
+	c.value, c.err = item.ValueCopy(nil)
+	return c.err == nil
+}
+
+func (c *badgerCursor) close() {
+	if c.iter != nil {
+		c.iter.Close()
+		c.iter = nil
+	}
+}
+
+var _ Iterator = (*badgerIterator)(nil)
+
+type badgerIterator struct {
+	db  *badger.DB
+	txn *badger.Txn
+	c   badgerCursor
+}
+
+func (i *badgerIterator) New(start []byte, limit []byte) {
+	i.txn = i.db.NewTransaction(false)
+	i.c.reset(i.txn, start, limit)
+}
+
+func (i *badgerIterator) Next() bool {
+	return i.c.next()
+}
+
+func (i *badgerIterator) Key() []byte {
+	return i.c.key
+}
+
+func (i *badgerIterator) Value() []byte {
+	return i.c.value
+}
+
+func (i *badgerIterator) Release() {
+	i.c.close()
+	if i.txn != nil {
+		i.txn.Discard()
+		i.txn = nil
+	}
+}
+
+func (i *badgerIterator) Error() error {
+	return i.c.err
+}
+
+//----------------------------------------
+// Batch
+
+var _ Batch = (*badgerBatch)(nil)
+
+type badgerBatchOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+type badgerBatch struct {
+	db  *badger.DB
+	ops []badgerBatchOp
+}
+
+func (b *badgerBatch) New() {
+	b.ops = make([]badgerBatchOp, 0)
+}
+
+func (b *badgerBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *badgerBatch) Set(key, value []byte) {
+	b.ops = append(b.ops, badgerBatchOp{
+		key:   append([]byte(nil), key...),
+		value: append([]byte(nil), value...),
+	})
+}
+
+func (b *badgerBatch) Delete(key []byte) {
+	b.ops = append(b.ops, badgerBatchOp{key: append([]byte(nil), key...), delete: true})
+}
+
+// Write commits operations with transaction. Big batch is split into several transactions.
+func (b *badgerBatch) Write() error {
+	txn := b.db.NewTransaction(true)
+	defer func() {
+		txn.Discard()
+	}()
+
+	for _, op := range b.ops {
+		for {
+			var err error
+			if op.delete {
+				err = txn.Delete(op.key)
+			} else {
+				err = txn.Set(op.key, op.value)
+			}
+			if err == nil {
+				break
+			}
+			if err != badger.ErrTxnTooBig {
+				return err
+			}
+
+			// commit and retry with new transaction
+			if err = txn.Commit(nil); err != nil {
+				return err
+			}
+			txn = b.db.NewTransaction(true)
+		}
+	}
+	return txn.Commit(nil)
+}
+
+func (b *badgerBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+//----------------------------------------
+// Snapshot
+
+var _ Snapshot = (*badgerSnapshot)(nil)
+
+type badgerSnapshot struct {
+	db  *badger.DB
+	txn *badger.Txn
+	c   badgerCursor
+}
+
+func (s *badgerSnapshot) New() error {
+	s.txn = s.db.NewTransaction(false)
+	return nil
+}
+
+func (s *badgerSnapshot) Get(key []byte) ([]byte, error) {
+	return badgerGet(s.txn, key)
+}
+
+func (s *badgerSnapshot) NewIterator(start []byte, limit []byte) {
+	s.c.reset(s.txn, start, limit)
+}
+
+func (s *badgerSnapshot) IterNext() bool {
+	return s.c.next()
+}
+
+func (s *badgerSnapshot) IterKey() []byte {
+	return s.c.key
+}
+
+func (s *badgerSnapshot) IterValue() []byte {
+	return s.c.value
+}
+
+func (s *badgerSnapshot) ReleaseIterator() {
+	s.c.close()
+}
+
+func (s *badgerSnapshot) Release() {
+	if s.txn != nil {
+		s.txn.Discard()
+		s.txn = nil
+	}
+}
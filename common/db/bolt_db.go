@@ -1,11 +1,24 @@
 package db
 
 import (
+	"bytes"
+	"os"
 	"path/filepath"
 
 	bolt "go.etcd.io/bbolt"
 )
 
+const (
+	boltDBFile = "bolt.db"
+
+	// Write transaction waits for read transactions to grow memory map. Reserve enough memory map,
+	// so iterators and snapshots don't block writing to DB
+	boltInitialMmapSize = 1 << 30
+)
+
+// All buckets are stored in one bolt bucket with internal key to iterate keys like other backends
+var boltRootBucket = []byte("root")
+
 func init() {
 	dbCreator := func(name string, dir string) (Database, error) {
 		return NewBoltDB(name, dir)
@@ -14,11 +27,22 @@ func init() {
 }
 
 func NewBoltDB(name string, dir string) (*BoltDB, error) {
-	dbPath := filepath.Join(dir, name+".db")
-	db, err := bolt.Open(dbPath, 0644, nil)
+	dbPath := filepath.Join(dir, name)
+	if err := os.MkdirAll(dbPath, 0755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(dbPath, boltDBFile), 0644, &bolt.Options{InitialMmapSize: boltInitialMmapSize})
 	if err != nil {
 		return nil, err
 	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltRootBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 	database := &BoltDB{
 		db: db,
 	}
@@ -39,24 +63,25 @@ type BoltDB struct {
 }
 
 func (db *BoltDB) GetBucket(id BucketID) (Bucket, error) {
-	// create bucket
-	err := db.db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(id))
-		return err
-	})
-	return &boltBucket{db: db.db, id: id}, err
+	return &boltBucket{db: db.db, id: id}, nil
 }
 
 func (db *BoltDB) GetIterator() (Iterator, error) {
-	return nil, nil
+	return &boltIterator{
+		db: db.db,
+	}, nil
 }
 
 func (db *BoltDB) GetBatch() (Batch, error) {
-	return nil, nil
+	return &boltBatch{
+		db: db.db,
+	}, nil
 }
 
 func (db *BoltDB) GetSnapshot() (Snapshot, error) {
-	return nil, nil
+	return &boltSnapshot{
+		db: db.db,
+	}, nil
 }
 
 func (db *BoltDB) Close() error {
@@ -77,7 +102,11 @@ type boltBucket struct {
 func (bucket *boltBucket) Get(key []byte) ([]byte, error) {
 	var value []byte
 	err := bucket.db.View(func(tx *bolt.Tx) error {
-		value = tx.Bucket([]byte(bucket.id)).Get(key)
+		v := tx.Bucket(boltRootBucket).Get(internalKey(bucket.id, key))
+		if v != nil {
+			value = make([]byte, len(v))
+			copy(value, v)
+		}
 		return nil
 	})
 	return value, err
@@ -93,18 +122,216 @@ func (bucket *boltBucket) Has(key []byte) bool {
 
 func (bucket *boltBucket) Set(key []byte, value []byte) error {
 	err := bucket.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(bucket.id))
-		err := bucket.Put(key, value)
-		return err
+		return tx.Bucket(boltRootBucket).Put(internalKey(bucket.id, key), value)
 	})
 	return err
 }
 
 func (bucket *boltBucket) Delete(key []byte) error {
 	err := bucket.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(bucket.id))
-		err := bucket.Delete(key)
-		return err
+		return tx.Bucket(boltRootBucket).Delete(internalKey(bucket.id, key))
 	})
 	return err
 }
+
+//----------------------------------------
+// DBIterator
+
+// boltCursor iterates keys in [start, limit) with read-only transaction.
+// Do not write to the same DB before release. It may block.
+type boltCursor struct {
+	cursor  *bolt.Cursor
+	start   []byte
+	limit   []byte
+	started bool
+	key     []byte
+	value   []byte
+}
+
+func (c *boltCursor) reset(tx *bolt.Tx, start []byte, limit []byte) {
+	c.cursor = tx.Bucket(boltRootBucket).Cursor()
+	if start == nil {
+		limit = nil
+	}
+	c.start = start
+	c.limit = limit
+	c.started = false
+	c.key = nil
+	c.value = nil
+}
+
+func (c *boltCursor) next() bool {
+	var k, v []byte
+	if !c.started {
+		c.started = true
+		if c.start == nil {
+			k, v = c.cursor.First()
+		} else {
+			k, v = c.cursor.Seek(c.start)
+		}
+	} else if c.key != nil {
+		k, v = c.cursor.Next()
+	}
+
+	if k == nil || (c.limit != nil && bytes.Compare(k, c.limit) >= 0) {
+		c.key = nil
+		c.value = nil
+		return false
+	}
+	c.key = k
+	c.value = v
+	return true
+}
+
+var _ Iterator = (*boltIterator)(nil)
+
+type boltIterator struct {
+	db  *bolt.DB
+	tx  *bolt.Tx
+	c   boltCursor
+	err error
+}
+
+func (i *boltIterator) New(start []byte, limit []byte) {
+	i.tx, i.err = i.db.Begin(false)
+	if i.err != nil {
+		return
+	}
+	i.c.reset(i.tx, start, limit)
+}
+
+func (i *boltIterator) Next() bool {
+	if i.tx == nil {
+		return false
+	}
+	return i.c.next()
+}
+
+func (i *boltIterator) Key() []byte {
+	return i.c.key
+}
+
+func (i *boltIterator) Value() []byte {
+	return i.c.value
+}
+
+func (i *boltIterator) Release() {
+	if i.tx != nil {
+		i.tx.Rollback()
+		i.tx = nil
+	}
+}
+
+func (i *boltIterator) Error() error {
+	return i.err
+}
+
+//----------------------------------------
+// Batch
+
+var _ Batch = (*boltBatch)(nil)
+
+type boltBatchOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+type boltBatch struct {
+	db  *bolt.DB
+	ops []boltBatchOp
+}
+
+func (b *boltBatch) New() {
+	b.ops = make([]boltBatchOp, 0)
+}
+
+func (b *boltBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *boltBatch) Set(key, value []byte) {
+	b.ops = append(b.ops, boltBatchOp{
+		key:   append([]byte(nil), key...),
+		value: append([]byte(nil), value...),
+	})
+}
+
+func (b *boltBatch) Delete(key []byte) {
+	b.ops = append(b.ops, boltBatchOp{key: append([]byte(nil), key...), delete: true})
+}
+
+func (b *boltBatch) Write() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltRootBucket)
+		for _, op := range b.ops {
+			var err error
+			if op.delete {
+				err = bucket.Delete(op.key)
+			} else {
+				err = bucket.Put(op.key, op.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+//----------------------------------------
+// Snapshot
+
+var _ Snapshot = (*boltSnapshot)(nil)
+
+type boltSnapshot struct {
+	db *bolt.DB
+	tx *bolt.Tx
+	c  boltCursor
+}
+
+func (s *boltSnapshot) New() error {
+	var err error
+	s.tx, err = s.db.Begin(false)
+	return err
+}
+
+func (s *boltSnapshot) Get(key []byte) ([]byte, error) {
+	v := s.tx.Bucket(boltRootBucket).Get(key)
+	if v == nil {
+		return nil, nil
+	}
+	value := make([]byte, len(v))
+	copy(value, v)
+	return value, nil
+}
+
+func (s *boltSnapshot) NewIterator(start []byte, limit []byte) {
+	s.c.reset(s.tx, start, limit)
+}
+
+func (s *boltSnapshot) IterNext() bool {
+	return s.c.next()
+}
+
+func (s *boltSnapshot) IterKey() []byte {
+	return s.c.key
+}
+
+func (s *boltSnapshot) IterValue() []byte {
+	return s.c.value
+}
+
+func (s *boltSnapshot) ReleaseIterator() {
+}
+
+func (s *boltSnapshot) Release() {
+	if s.tx != nil {
+		s.tx.Rollback()
+		s.tx = nil
+	}
+}
@@ -22,6 +22,9 @@ const (
 	// For claim DB
 	PrefixClaim BucketID              = ""
 
+	// For reward ledger DB
+	PrefixRewardLedger BucketID       = ""
+
 	// For global DB
 
 	// Information for management
@@ -36,6 +39,21 @@ const (
 	// Main/Sub P-Rep list
 	PrefixPRep BucketID               = "PR"
 
+	// Calculation history
+	PrefixCalcHistory BucketID        = "CH"
+
+	// Journal of account DB operation
+	PrefixJournal BucketID            = "JN"
+
+	// Checkpoint of calculation
+	PrefixCalcCheckpoint BucketID     = "CK"
+
+	// Network profile
+	PrefixNetworkProfile BucketID     = "NP"
+
+	// Reward policy switch
+	PrefixRewardPolicySwitch BucketID = "RP"
+
 	// FOR IISS data DB
 	// Header
 	PrefixIISSHeader BucketID         = "HD"
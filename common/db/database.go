@@ -2,6 +2,9 @@ package db
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -27,6 +30,14 @@ const (
 	MapDBBackend     BackendType = "mapdb"
 )
 
+// backendFiles are files which backends make in the directory of database
+var backendFiles = map[BackendType]string{
+	BadgerDBBackend:  "MANIFEST",
+	GoLevelDBBackend: "CURRENT",
+	BoltDBBackend:    boltDBFile,
+	MapDBBackend:     mapDBIDFile,
+}
+
 type dbCreator func(name string, dir string) (Database, error)
 
 var backends = map[BackendType]dbCreator{}
@@ -39,6 +50,40 @@ func registerDBCreator(backend BackendType, creator dbCreator, force bool) {
 	backends[backend] = creator
 }
 
+// GetBackendList returns names of registered backends in alphabetical order
+func GetBackendList() []string {
+	keys := make([]string, 0, len(backends))
+	for k := range backends {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func HasBackend(dbtype string) bool {
+	_, ok := backends[BackendType(dbtype)]
+	return ok
+}
+
+// IsTestBackend returns true for the backend which does not keep data after the process exits
+func IsTestBackend(dbtype string) bool {
+	return BackendType(dbtype) == MapDBBackend
+}
+
+// DetectBackend returns the backend which made the database in dir. false if there is no database
+func DetectBackend(dir, name string) (BackendType, bool) {
+	for _, backend := range GetBackendList() {
+		file, ok := backendFiles[BackendType(backend)]
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, name, file)); err == nil {
+			return BackendType(backend), true
+		}
+	}
+	return "", false
+}
+
 func Open(dir, dbtype, name string) Database {
 	return openDatabase(BackendType(dbtype), name, dir)
 }
@@ -46,13 +91,8 @@ func Open(dir, dbtype, name string) Database {
 func openDatabase(backend BackendType, name string, dir string) Database {
 	dbCreator, ok := backends[backend]
 	if !ok {
-		keys := make([]string, len(backends))
-		i := 0
-		for k := range backends {
-			keys[i] = string(k)
-			i++
-		}
-		panic(fmt.Sprintf("Unknown db_backend %s, expected either %s", backend, strings.Join(keys, " or ")))
+		panic(fmt.Sprintf("Unknown db_backend %s, expected either %s", backend,
+			strings.Join(GetBackendList(), " or ")))
 	}
 
 	db, err := dbCreator(name, dir)
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Conformance tests for all registered backends
+
+func forEachBackend(t *testing.T, f func(t *testing.T, dir string, backend string)) {
+	for _, backend := range GetBackendList() {
+		t.Run(backend, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", backend)
+			if err != nil {
+				panic(err)
+			}
+			defer os.RemoveAll(dir)
+
+			f(t, dir, backend)
+		})
+	}
+}
+
+func TestDatabase_Bucket(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dir string, backend string) {
+		testDB := Open(dir, backend, "test")
+		defer testDB.Close()
+
+		key := []byte("key")
+		bucket1, _ := testDB.GetBucket("B1")
+		bucket2, _ := testDB.GetBucket("")
+
+		assert.NoError(t, bucket1.Set(key, []byte("value1")))
+		assert.NoError(t, bucket2.Set(key, []byte("value2")))
+		assert.NoError(t, bucket2.Set([]byte("empty"), []byte{}))
+
+		value, err := bucket1.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("value1"), value)
+		value, err = bucket2.Get(key)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("value2"), value)
+		assert.True(t, bucket2.Has([]byte("empty")))
+
+		// not found
+		value, err = bucket1.Get([]byte("none"))
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+		assert.False(t, bucket1.Has([]byte("none")))
+
+		assert.NoError(t, bucket1.Delete(key))
+		value, _ = bucket1.Get(key)
+		assert.Nil(t, value)
+		value, _ = bucket2.Get(key)
+		assert.Equal(t, []byte("value2"), value)
+	})
+}
+
+func writeTestEntries(bucket Bucket, prefix string, count int) {
+	for i := 0; i < count; i++ {
+		bucket.Set([]byte(fmt.Sprintf("%s%02d", prefix, i)), []byte(fmt.Sprintf("value%02d", i)))
+	}
+}
+
+func TestDatabase_Iterator(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dir string, backend string) {
+		testDB := Open(dir, backend, "test")
+		defer testDB.Close()
+
+		bucket, _ := testDB.GetBucket("")
+		writeTestEntries(bucket, "a", 3)
+		writeTestEntries(bucket, "key", 10)
+		writeTestEntries(bucket, "z", 3)
+		gvBucket, _ := testDB.GetBucket(PrefixGovernanceVariable)
+		writeTestEntries(gvBucket, "", 2)
+
+		// prefix
+		iter, err := testDB.GetIterator()
+		assert.NoError(t, err)
+		prefix := util.BytesPrefix([]byte("key"))
+		iter.New(prefix.Start, prefix.Limit)
+		count := 0
+		for iter.Next() {
+			assert.Equal(t, []byte(fmt.Sprintf("key%02d", count)), iter.Key())
+			assert.Equal(t, []byte(fmt.Sprintf("value%02d", count)), iter.Value())
+			count++
+		}
+		iter.Release()
+		assert.NoError(t, iter.Error())
+		assert.Equal(t, 10, count)
+
+		// prefix of bucket
+		prefix = util.BytesPrefix([]byte(PrefixGovernanceVariable))
+		iter.New(prefix.Start, prefix.Limit)
+		count = 0
+		for iter.Next() {
+			assert.Equal(t, []byte(fmt.Sprintf("%s%02d", PrefixGovernanceVariable, count)), iter.Key())
+			count++
+		}
+		iter.Release()
+		assert.Equal(t, 2, count)
+
+		// all
+		iter.New(nil, nil)
+		count = 0
+		var prev []byte
+		for iter.Next() {
+			if prev != nil {
+				assert.True(t, string(prev) < string(iter.Key()))
+			}
+			prev = append([]byte(nil), iter.Key()...)
+			count++
+		}
+		iter.Release()
+		assert.Equal(t, 18, count)
+	})
+}
+
+func TestDatabase_Batch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dir string, backend string) {
+		testDB := Open(dir, backend, "test")
+		defer testDB.Close()
+
+		bucket, _ := testDB.GetBucket(PrefixManagement)
+		bucket.Set([]byte("delete"), []byte("value"))
+
+		batch, err := testDB.GetBatch()
+		assert.NoError(t, err)
+		batch.New()
+		for i := 0; i < 1000; i++ {
+			batch.Set([]byte(fmt.Sprintf("%skey%04d", PrefixManagement, i)), []byte("value"))
+		}
+		batch.Delete([]byte(string(PrefixManagement) + "delete"))
+		assert.Equal(t, 1001, batch.Len())
+
+		// not written before Write()
+		assert.False(t, bucket.Has([]byte("key0000")))
+
+		assert.NoError(t, batch.Write())
+		batch.Reset()
+		assert.Equal(t, 0, batch.Len())
+
+		assert.True(t, bucket.Has([]byte("key0000")))
+		assert.True(t, bucket.Has([]byte("key0999")))
+		assert.False(t, bucket.Has([]byte("delete")))
+	})
+}
+
+func TestDatabase_Snapshot(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dir string, backend string) {
+		testDB := Open(dir, backend, "test")
+		defer testDB.Close()
+
+		bucket, _ := testDB.GetBucket("")
+		writeTestEntries(bucket, "key", 3)
+
+		snapshot, err := testDB.GetSnapshot()
+		assert.NoError(t, err)
+		assert.NoError(t, snapshot.New())
+
+		// write after snapshot
+		bucket.Set([]byte("key00"), []byte("new"))
+		bucket.Set([]byte("key03"), []byte("value03"))
+
+		value, err := snapshot.Get([]byte("key00"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("value00"), value)
+		value, err = snapshot.Get([]byte("key03"))
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+
+		prefix := util.BytesPrefix([]byte("key"))
+		snapshot.NewIterator(prefix.Start, prefix.Limit)
+		count := 0
+		for snapshot.IterNext() {
+			assert.Equal(t, []byte(fmt.Sprintf("key%02d", count)), snapshot.IterKey())
+			assert.Equal(t, []byte(fmt.Sprintf("value%02d", count)), snapshot.IterValue())
+			count++
+		}
+		snapshot.ReleaseIterator()
+		snapshot.Release()
+		assert.Equal(t, 3, count)
+
+		value, _ = bucket.Get([]byte("key00"))
+		assert.Equal(t, []byte("new"), value)
+	})
+}
+
+func TestDatabase_Reopen(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dir string, backend string) {
+		testDB := Open(dir, backend, "test")
+		bucket, _ := testDB.GetBucket("")
+		bucket.Set([]byte("key"), []byte("value"))
+		testDB.Close()
+
+		// database is a directory which can be renamed
+		assert.NoError(t, os.Rename(filepath.Join(dir, "test"), filepath.Join(dir, "renamed")))
+
+		testDB = Open(dir, backend, "renamed")
+		bucket, _ = testDB.GetBucket("")
+		value, _ := bucket.Get([]byte("key"))
+		assert.Equal(t, []byte("value"), value)
+		testDB.Close()
+
+		// removed database is empty
+		assert.NoError(t, os.RemoveAll(filepath.Join(dir, "renamed")))
+		testDB = Open(dir, backend, "renamed")
+		bucket, _ = testDB.GetBucket("")
+		value, _ = bucket.Get([]byte("key"))
+		assert.Nil(t, value)
+		testDB.Close()
+	})
+}
+
+func TestDatabase_DetectBackend(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, dir string, backend string) {
+		_, ok := DetectBackend(dir, "test")
+		assert.False(t, ok)
+
+		testDB := Open(dir, backend, "test")
+		testDB.Close()
+		detected, ok := DetectBackend(dir, "test")
+		assert.True(t, ok)
+		assert.Equal(t, BackendType(backend), detected)
+	})
+}
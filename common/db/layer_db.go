@@ -1,8 +1,11 @@
 package db
 
 import (
-	"github.com/pkg/errors"
+	"bytes"
+	"sort"
 	"sync"
+
+	"github.com/pkg/errors"
 )
 
 type layerBucket struct {
@@ -118,7 +121,11 @@ func (ldb *layerDB) GetBucket(id BucketID) (Bucket, error) {
 }
 
 func (ldb *layerDB) GetIterator() (Iterator, error) {
-	return nil, nil
+	realIter, err := ldb.real.GetIterator()
+	if err != nil {
+		return nil, err
+	}
+	return &layerIterator{ldb: ldb, real: realIter}, nil
 }
 
 func (db *layerDB) GetBatch() (Batch, error) {
@@ -152,3 +159,101 @@ func NewLayerDB(dbase Database) LayerDB {
 		buckets: make(map[string]*layerBucket),
 	}
 }
+
+// layerIterator iterates the real DB and the layer in key order. Data in the layer precedes data in the real DB.
+type layerIterator struct {
+	ldb  *layerDB
+	real Iterator
+
+	realValid bool
+	entries   []mapEntry // sorted layer data. nil value is deleted
+	index     int
+	key       []byte
+	value     []byte
+	keyBuf    []byte // copy of the real DB data
+	valueBuf  []byte
+}
+
+func (i *layerIterator) New(start []byte, limit []byte) {
+	if start == nil {
+		limit = nil
+	}
+	i.entries = i.ldb.layerEntries(start, limit)
+	i.index = 0
+	i.key = nil
+	i.value = nil
+	i.real.New(start, limit)
+	i.realValid = i.real.Next()
+}
+
+func (i *layerIterator) Next() bool {
+	for {
+		var entry *mapEntry
+		if i.index < len(i.entries) {
+			entry = &i.entries[i.index]
+		}
+		if !i.realValid && entry == nil {
+			return false
+		}
+
+		if entry == nil || (i.realValid && bytes.Compare(i.real.Key(), entry.key) < 0) {
+			i.keyBuf = append(i.keyBuf[:0], i.real.Key()...)
+			i.valueBuf = append(i.valueBuf[:0], i.real.Value()...)
+			i.key, i.value = i.keyBuf, i.valueBuf
+			i.realValid = i.real.Next()
+			return true
+		}
+
+		if i.realValid && bytes.Equal(i.real.Key(), entry.key) {
+			i.realValid = i.real.Next()
+		}
+		i.index++
+		if entry.value == nil {
+			continue
+		}
+		i.key = entry.key
+		i.value = entry.value
+		return true
+	}
+}
+
+func (i *layerIterator) Key() []byte {
+	return i.key
+}
+
+func (i *layerIterator) Value() []byte {
+	return i.value
+}
+
+func (i *layerIterator) Release() {
+	i.real.Release()
+	i.entries = nil
+}
+
+func (i *layerIterator) Error() error {
+	return i.real.Error()
+}
+
+// layerEntries returns data of all layer buckets in [start, limit) with bucket ID prefixed keys
+func (ldb *layerDB) layerEntries(start []byte, limit []byte) []mapEntry {
+	ldb.lock.Lock()
+	defer ldb.lock.Unlock()
+
+	entries := make([]mapEntry, 0)
+	for id, bk := range ldb.buckets {
+		bk.lock.Lock()
+		for k, v := range bk.data {
+			key := []byte(id + k)
+			if start != nil && bytes.Compare(key, start) < 0 {
+				continue
+			}
+			if limit != nil && bytes.Compare(key, limit) >= 0 {
+				continue
+			}
+			entries = append(entries, mapEntry{key: key, value: v})
+		}
+		bk.lock.Unlock()
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+	return entries
+}
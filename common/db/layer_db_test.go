@@ -0,0 +1,54 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayerDB_Iterator(t *testing.T) {
+	realDB := openDatabase(MapDBBackend, "layer", "")
+	defer realDB.Close()
+
+	bucket, _ := realDB.GetBucket("a")
+	bucket.Set([]byte("1"), []byte("real1"))
+	bucket.Set([]byte("2"), []byte("real2"))
+	bucket.Set([]byte("4"), []byte("real4"))
+	bucket, _ = realDB.GetBucket("b")
+	bucket.Set([]byte("1"), []byte("realb1"))
+
+	ldb := NewLayerDB(realDB)
+	bucket, _ = ldb.GetBucket("a")
+	bucket.Set([]byte("0"), []byte("layer0"))
+	bucket.Set([]byte("2"), []byte("layer2"))
+	bucket.Set([]byte("3"), []byte("layer3"))
+	bucket.Delete([]byte("4"))
+	bucket, _ = ldb.GetBucket("c")
+	bucket.Set([]byte("1"), []byte("layerc1"))
+
+	read := func(start []byte, limit []byte) []string {
+		iter, _ := ldb.GetIterator()
+		iter.New(start, limit)
+		result := make([]string, 0)
+		for iter.Next() {
+			result = append(result, string(iter.Key())+"="+string(iter.Value()))
+		}
+		iter.Release()
+		assert.NoError(t, iter.Error())
+		return result
+	}
+
+	assert.Equal(t, []string{"a0=layer0", "a1=real1", "a2=layer2", "a3=layer3", "b1=realb1", "c1=layerc1"},
+		read(nil, nil))
+	assert.Equal(t, []string{"a1=real1", "a2=layer2", "a3=layer3"}, read([]byte("a1"), []byte("b")))
+
+	// real DB is not changed
+	bucket, _ = realDB.GetBucket("a")
+	value, _ := bucket.Get([]byte("2"))
+	assert.Equal(t, []byte("real2"), value)
+	assert.True(t, bucket.Has([]byte("4")))
+
+	// discard layer
+	ldb.Flush(false)
+	assert.Equal(t, []string{"a1=real1", "a2=real2", "a4=real4", "b1=realb1"}, read(nil, nil))
+}
@@ -1,22 +1,48 @@
 package db
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
-
-	"github.com/pkg/errors"
+	"time"
 )
 
 const (
 	configLogMapDB = false
+
+	// mapDBIDFile is the name of file which has ID of map DB. Directory of map DB is a handle of in-memory data,
+	// so it can be renamed or removed like directory of other backends.
+	mapDBIDFile = "MAPDB"
 )
 
+// in-memory data of map DBs which have directory
+var mapStores = struct {
+	sync.Mutex
+	m     map[string]*mapStore
+	count int
+}{m: make(map[string]*mapStore)}
+
 func init() {
 	dbCreator := func(name string, dir string) (Database, error) {
+		if len(dir) == 0 {
+			return &mapDatabase{
+				name:  name,
+				store: newMapStore(),
+			}, nil
+		}
+
+		store, err := openMapStore(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
 		return &mapDatabase{
-			name: name,
-			bks:  map[BucketID]*mapBucket{},
+			name:  name,
+			store: store,
 		}, nil
 	}
 	registerDBCreator(MapDBBackend, dbCreator, false)
@@ -24,44 +50,80 @@ func init() {
 
 func NewMapDB() Database {
 	dbase := &mapDatabase{
-		bks: map[BucketID]*mapBucket{},
+		store: newMapStore(),
 	}
 	dbase.name = fmt.Sprintf("%p", dbase)
 	return dbase
 }
 
+type mapStore struct {
+	mutex sync.Mutex
+	real  map[string]string
+}
+
+func newMapStore() *mapStore {
+	return &mapStore{real: make(map[string]string)}
+}
+
+// openMapStore returns in-memory data of map DB in dbPath. Makes new one if there is no ID file in dbPath
+func openMapStore(dbPath string) (*mapStore, error) {
+	mapStores.Lock()
+	defer mapStores.Unlock()
+
+	idPath := filepath.Join(dbPath, mapDBIDFile)
+	if bs, err := ioutil.ReadFile(idPath); err == nil {
+		if store, ok := mapStores.m[string(bs)]; ok {
+			return store, nil
+		}
+	}
+
+	if err := os.MkdirAll(dbPath, 0755); err != nil {
+		return nil, err
+	}
+	mapStores.count++
+	id := fmt.Sprintf("%d-%d-%d", os.Getpid(), time.Now().UnixNano(), mapStores.count)
+	if err := ioutil.WriteFile(idPath, []byte(id), 0644); err != nil {
+		return nil, err
+	}
+	store := newMapStore()
+	mapStores.m[id] = store
+	return store, nil
+}
+
 //----------------------------------------
 // DB
 
 var _ Database = (*mapDatabase)(nil)
 
 type mapDatabase struct {
-	name string
-	bks  map[BucketID]*mapBucket
+	name  string
+	store *mapStore
 }
 
 func (t *mapDatabase) GetBucket(id BucketID) (Bucket, error) {
-	if bk, ok := t.bks[id]; ok {
-		return bk, nil
-	}
-	bk := &mapBucket{
-		id:   fmt.Sprintf("%s:%s", t.name, id),
-		real: make(map[string]string),
-	}
-	t.bks[id] = bk
-	return bk, nil
+	return &mapBucket{
+		id:    fmt.Sprintf("%s:%s", t.name, id),
+		key:   id,
+		store: t.store,
+	}, nil
 }
 
 func (t *mapDatabase) GetIterator() (Iterator, error) {
-	return nil, nil
+	return &mapIterator{
+		store: t.store,
+	}, nil
 }
 
 func (db *mapDatabase) GetBatch() (Batch, error) {
-	return nil, nil
+	return &mapBatch{
+		store: db.store,
+	}, nil
 }
 
 func (db *mapDatabase) GetSnapshot() (Snapshot, error) {
-	return nil, nil
+	return &mapSnapshot{
+		store: db.store,
+	}, nil
 }
 
 func (t *mapDatabase) Close() error {
@@ -75,14 +137,14 @@ var _ Bucket = (*mapBucket)(nil)
 
 type mapBucket struct {
 	id    string
-	real  map[string]string
-	mutex sync.Mutex
+	key   BucketID
+	store *mapStore
 }
 
 func (t *mapBucket) Get(k []byte) ([]byte, error) {
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	v, ok := t.real[string(k)]
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	v, ok := t.store.real[string(internalKey(t.key, k))]
 	if ok {
 		bytes := []byte(v)
 		if configLogMapDB {
@@ -97,9 +159,9 @@ func (t *mapBucket) Get(k []byte) ([]byte, error) {
 }
 
 func (t *mapBucket) Has(k []byte) bool {
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	_, ok := t.real[string(k)]
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	_, ok := t.store.real[string(internalKey(t.key, k))]
 	if configLogMapDB {
 		log.Printf("mapBucket[%s].Has(%x) -> %v", t.id, k, ok)
 	}
@@ -107,15 +169,12 @@ func (t *mapBucket) Has(k []byte) bool {
 }
 
 func (t *mapBucket) Set(k, v []byte) error {
-	if len(k) == 0 {
-		return errors.Errorf("Illegal Key:%x", k)
-	}
 	if configLogMapDB {
 		log.Printf("mapBucket[%s].Set(%x,%x)", t.id, k, v)
 	}
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	t.real[string(k)] = string(v)
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	t.store.real[string(internalKey(t.key, k))] = string(v)
 	return nil
 }
 
@@ -123,8 +182,175 @@ func (t *mapBucket) Delete(k []byte) error {
 	if configLogMapDB {
 		log.Printf("mapBucket[%s].Delete(%x)", t.id, k)
 	}
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	delete(t.real, string(k))
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+	delete(t.store.real, string(internalKey(t.key, k)))
+	return nil
+}
+
+//----------------------------------------
+// DBIterator
+
+type mapEntry struct {
+	key   []byte
+	value []byte
+}
+
+// sortedEntries returns entries in [start, limit) in key order. limit is unlimited if it is nil
+func sortedEntries(real map[string]string, start []byte, limit []byte) []mapEntry {
+	entries := make([]mapEntry, 0)
+	for k, v := range real {
+		key := []byte(k)
+		if start != nil && bytes.Compare(key, start) < 0 {
+			continue
+		}
+		if limit != nil && bytes.Compare(key, limit) >= 0 {
+			continue
+		}
+		entries = append(entries, mapEntry{key: key, value: []byte(v)})
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+	return entries
+}
+
+var _ Iterator = (*mapIterator)(nil)
+
+type mapIterator struct {
+	store   *mapStore
+	entries []mapEntry
+	index   int
+}
+
+func (i *mapIterator) New(start []byte, limit []byte) {
+	i.store.mutex.Lock()
+	defer i.store.mutex.Unlock()
+	if start == nil {
+		limit = nil
+	}
+	i.entries = sortedEntries(i.store.real, start, limit)
+	i.index = -1
+}
+
+func (i *mapIterator) Next() bool {
+	if i.index < len(i.entries) {
+		i.index++
+	}
+	return i.index < len(i.entries)
+}
+
+func (i *mapIterator) Key() []byte {
+	return i.entries[i.index].key
+}
+
+func (i *mapIterator) Value() []byte {
+	return i.entries[i.index].value
+}
+
+func (i *mapIterator) Release() {
+	i.entries = nil
+}
+
+func (i *mapIterator) Error() error {
+	return nil
+}
+
+//----------------------------------------
+// Batch
+
+var _ Batch = (*mapBatch)(nil)
+
+type mapBatch struct {
+	store *mapStore
+	ops   []mapEntry // value is nil for delete
+}
+
+func (b *mapBatch) New() {
+	b.ops = make([]mapEntry, 0)
+}
+
+func (b *mapBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *mapBatch) Set(key, value []byte) {
+	v := make([]byte, len(value))
+	copy(v, value)
+	b.ops = append(b.ops, mapEntry{key: append([]byte(nil), key...), value: v})
+}
+
+func (b *mapBatch) Delete(key []byte) {
+	b.ops = append(b.ops, mapEntry{key: append([]byte(nil), key...), value: nil})
+}
+
+func (b *mapBatch) Write() error {
+	b.store.mutex.Lock()
+	defer b.store.mutex.Unlock()
+	for _, op := range b.ops {
+		if op.value == nil {
+			delete(b.store.real, string(op.key))
+		} else {
+			b.store.real[string(op.key)] = string(op.value)
+		}
+	}
 	return nil
 }
+
+func (b *mapBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+//----------------------------------------
+// Snapshot
+
+var _ Snapshot = (*mapSnapshot)(nil)
+
+type mapSnapshot struct {
+	store *mapStore
+	real  map[string]string
+	iter  mapIterator
+}
+
+func (s *mapSnapshot) New() error {
+	s.store.mutex.Lock()
+	defer s.store.mutex.Unlock()
+	s.real = make(map[string]string, len(s.store.real))
+	for k, v := range s.store.real {
+		s.real[k] = v
+	}
+	return nil
+}
+
+func (s *mapSnapshot) Get(key []byte) ([]byte, error) {
+	if v, ok := s.real[string(key)]; ok {
+		return []byte(v), nil
+	}
+	return nil, nil
+}
+
+func (s *mapSnapshot) NewIterator(start []byte, limit []byte) {
+	if start == nil {
+		limit = nil
+	}
+	s.iter.entries = sortedEntries(s.real, start, limit)
+	s.iter.index = -1
+}
+
+func (s *mapSnapshot) IterNext() bool {
+	return s.iter.Next()
+}
+
+func (s *mapSnapshot) IterKey() []byte {
+	return s.iter.Key()
+}
+
+func (s *mapSnapshot) IterValue() []byte {
+	return s.iter.Value()
+}
+
+func (s *mapSnapshot) ReleaseIterator() {
+	s.iter.Release()
+}
+
+func (s *mapSnapshot) Release() {
+	s.real = nil
+}
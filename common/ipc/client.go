@@ -0,0 +1,240 @@
+package ipc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+)
+
+var ErrClientClosed = errors.New("client closed")
+
+// DefaultRequestTimeout is the timeout of Client.Call
+const DefaultRequestTimeout = 30 * time.Second
+
+// Message is a reply or a notification received by Client
+type Message struct {
+	Msg  uint
+	Id   uint32
+	Data []byte
+}
+
+// Decode decodes data of the message to v
+func (m *Message) Decode(v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	_, err := codec.MP.UnmarshalFromBytes(m.Data, v)
+	return err
+}
+
+type frameReceiver interface {
+	receiveFrame() (uint, uint32, []byte, error)
+}
+
+// Encoder converts data of a request with its message and id before sending. ex) signing
+type Encoder func(msg uint, id uint32, data interface{}) (interface{}, error)
+
+// Client sends requests on a connection from many goroutines concurrently.
+// A dispatcher goroutine routes replies to waiting callers by id and
+// notifications to subscribers.
+type Client struct {
+	conn           Connection
+	receiver       frameReceiver
+	isNotification func(msg uint) bool
+
+	// id is allocated and sent in order, so the peer gets increasing ids
+	sendLock sync.Mutex
+	lastID   uint32
+	encoder  Encoder
+
+	lock        sync.Mutex
+	waiters     map[uint32]chan *Message
+	subscribers map[uint][]chan<- *Message
+	err         error
+	done        chan struct{}
+
+	Timeout time.Duration // timeout of Call
+}
+
+// NewClient starts dispatcher of connection made by Dial or DialTLS.
+// Messages for which isNotification returns true are delivered to subscribers.
+func NewClient(c Connection, isNotification func(msg uint) bool) (*Client, error) {
+	receiver, ok := c.(frameReceiver)
+	if !ok {
+		return nil, errors.New("connection does not support client")
+	}
+	client := &Client{
+		conn:           c,
+		receiver:       receiver,
+		isNotification: isNotification,
+		waiters:        make(map[uint32]chan *Message),
+		subscribers:    make(map[uint][]chan<- *Message),
+		done:           make(chan struct{}),
+		Timeout:        DefaultRequestTimeout,
+	}
+	go client.dispatch()
+	return client, nil
+}
+
+// SetEncoder sets encoder of requests
+func (c *Client) SetEncoder(encoder Encoder) {
+	c.sendLock.Lock()
+	defer c.sendLock.Unlock()
+	c.encoder = encoder
+}
+
+// Subscribe delivers notifications of msg to ch. Notifications are dropped while ch is full,
+// so the dispatcher doesn't wait for a slow subscriber
+func (c *Client) Subscribe(msg uint, ch chan<- *Message) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.subscribers[msg] = append(c.subscribers[msg], ch)
+}
+
+// Call sends a request and decodes its reply to reply with timeout of the client
+func (c *Client) Call(msg uint, data interface{}, reply interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	return c.Request(ctx, msg, data, reply)
+}
+
+// Request sends a request and waits for its reply until ctx is done.
+// The reply which comes after ctx is done is dropped.
+func (c *Client) Request(ctx context.Context, msg uint, data interface{}, reply interface{}) error {
+	ch := make(chan *Message, 1)
+	id, err := c.send(msg, data, ch)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case m := <-ch:
+		return m.Decode(reply)
+	case <-ctx.Done():
+		c.removeWaiter(id)
+		return ctx.Err()
+	case <-c.done:
+		// reply may be delivered before the connection is closed
+		select {
+		case m := <-ch:
+			return m.Decode(reply)
+		default:
+		}
+		c.removeWaiter(id)
+		return c.closeError()
+	}
+}
+
+// Send sends a message which has no reply
+func (c *Client) Send(msg uint, data interface{}) error {
+	_, err := c.send(msg, data, nil)
+	return err
+}
+
+func (c *Client) send(msg uint, data interface{}, ch chan *Message) (uint32, error) {
+	c.sendLock.Lock()
+	defer c.sendLock.Unlock()
+
+	c.lock.Lock()
+	if c.err != nil {
+		c.lock.Unlock()
+		return 0, c.err
+	}
+	// id 0 is for notifications
+	for {
+		c.lastID++
+		if _, ok := c.waiters[c.lastID]; c.lastID != 0 && !ok {
+			break
+		}
+	}
+	id := c.lastID
+	if ch != nil {
+		c.waiters[id] = ch
+	}
+	c.lock.Unlock()
+
+	var err error
+	if c.encoder != nil {
+		data, err = c.encoder(msg, id, data)
+	}
+	if err == nil {
+		err = c.conn.Send(msg, id, data)
+	}
+	if err != nil {
+		c.removeWaiter(id)
+		return 0, err
+	}
+	return id, nil
+}
+
+func (c *Client) removeWaiter(id uint32) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.waiters, id)
+}
+
+func (c *Client) closeError() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.err
+}
+
+func (c *Client) dispatch() {
+	for {
+		msg, id, data, err := c.receiver.receiveFrame()
+		if err != nil {
+			c.lock.Lock()
+			if c.err == nil {
+				c.err = err
+			}
+			c.waiters = make(map[uint32]chan *Message)
+			c.lock.Unlock()
+			close(c.done)
+			return
+		}
+		m := &Message{Msg: msg, Id: id, Data: data}
+
+		if c.isNotification != nil && c.isNotification(msg) {
+			c.lock.Lock()
+			subscribers := c.subscribers[msg]
+			c.lock.Unlock()
+			for _, ch := range subscribers {
+				select {
+				case ch <- m:
+				default:
+					log.Printf("Drop notification. subscriber is full. (msg:%d)", msg)
+				}
+			}
+			continue
+		}
+
+		c.lock.Lock()
+		ch, ok := c.waiters[id]
+		delete(c.waiters, id)
+		c.lock.Unlock()
+		if ok {
+			ch <- m
+		} else {
+			log.Printf("Drop message without request. (msg:%d, id:%d)", msg, id)
+		}
+	}
+}
+
+// Done is closed when the connection is closed
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close closes the connection. Waiting requests fail with ErrClientClosed
+func (c *Client) Close() error {
+	c.lock.Lock()
+	if c.err == nil {
+		c.err = ErrClientClosed
+	}
+	c.lock.Unlock()
+	return c.conn.Close()
+}
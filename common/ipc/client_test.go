@@ -0,0 +1,153 @@
+package ipc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testMsgRequest uint = 1
+	testMsgNotify  uint = 100
+)
+
+func isTestNotification(msg uint) bool {
+	return msg >= testMsgNotify
+}
+
+type testFrame struct {
+	msg  uint
+	id   uint32
+	data []byte
+}
+
+// newTestClient returns client and peer connection of it
+func newTestClient(t *testing.T) (*Client, *connection) {
+	c1, c2 := net.Pipe()
+	client, err := NewClient(connectionFromConn(c1), isTestNotification)
+	assert.NoError(t, err)
+	return client, connectionFromConn(c2)
+}
+
+func receiveFrames(t *testing.T, peer *connection, n int) []testFrame {
+	frames := make([]testFrame, n)
+	for i := range frames {
+		msg, id, data, err := peer.receiveFrame()
+		assert.NoError(t, err)
+		frames[i] = testFrame{msg: msg, id: id, data: data}
+	}
+	return frames
+}
+
+func TestClient_Request(t *testing.T) {
+	const n = 10
+	client, peer := newTestClient(t)
+	defer client.Close()
+
+	notifications := make(chan *Message, 1)
+	client.Subscribe(testMsgNotify, notifications)
+
+	// requests from goroutines
+	wg := new(sync.WaitGroup)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var reply int
+			assert.NoError(t, client.Call(testMsgRequest, i, &reply))
+			assert.Equal(t, i*10, reply)
+		}(i)
+	}
+
+	// ids are unique and increasing
+	frames := receiveFrames(t, peer, n)
+	for i := 1; i < n; i++ {
+		assert.True(t, frames[i-1].id < frames[i].id)
+	}
+
+	// notification between replies and replies in reverse order
+	assert.NoError(t, peer.Send(testMsgNotify, 0, "notify"))
+	for i := n - 1; i >= 0; i-- {
+		var v int
+		assert.NoError(t, (&Message{Data: frames[i].data}).Decode(&v))
+		assert.NoError(t, peer.Send(frames[i].msg, frames[i].id, v*10))
+	}
+	wg.Wait()
+
+	m := <-notifications
+	var s string
+	assert.NoError(t, m.Decode(&s))
+	assert.Equal(t, "notify", s)
+}
+
+func TestClient_timeout(t *testing.T) {
+	client, peer := newTestClient(t)
+	defer client.Close()
+
+	done := make(chan error)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	go func() {
+		done <- client.Request(ctx, testMsgRequest, 1, nil)
+	}()
+	frame := receiveFrames(t, peer, 1)[0]
+	assert.Equal(t, context.DeadlineExceeded, <-done)
+
+	// late reply is dropped and the next request gets its reply
+	go func() {
+		var reply int
+		done <- client.Call(testMsgRequest, 2, &reply)
+	}()
+	next := receiveFrames(t, peer, 1)[0]
+	assert.NoError(t, peer.Send(frame.msg, frame.id, 10))
+	assert.NoError(t, peer.Send(next.msg, next.id, 20))
+	assert.NoError(t, <-done)
+}
+
+func TestClient_fullSubscriber(t *testing.T) {
+	client, peer := newTestClient(t)
+	defer client.Close()
+
+	notifications := make(chan *Message, 1)
+	client.Subscribe(testMsgNotify, notifications)
+
+	done := make(chan error)
+	go func() {
+		var reply int
+		done <- client.Call(testMsgRequest, 1, &reply)
+	}()
+	frame := receiveFrames(t, peer, 1)[0]
+
+	// notifications to the full subscriber are dropped and the reply is delivered
+	assert.NoError(t, peer.Send(testMsgNotify, 0, "first"))
+	assert.NoError(t, peer.Send(testMsgNotify, 0, "second"))
+	assert.NoError(t, peer.Send(frame.msg, frame.id, 10))
+	assert.NoError(t, <-done)
+
+	var s string
+	assert.NoError(t, (<-notifications).Decode(&s))
+	assert.Equal(t, "first", s)
+	assert.Equal(t, 0, len(notifications))
+}
+
+func TestClient_Close(t *testing.T) {
+	client, peer := newTestClient(t)
+
+	done := make(chan error)
+	go func() {
+		done <- client.Call(testMsgRequest, 1, nil)
+	}()
+	receiveFrames(t, peer, 1)
+
+	// waiting request fails when the connection is closed
+	peer.Close()
+	assert.Error(t, <-done)
+	<-client.Done()
+	assert.Error(t, client.Call(testMsgRequest, 2, nil))
+
+	client.Close()
+}
@@ -77,6 +77,15 @@ func (c *connection) Receive(buffer interface{}) (uint, uint32, error) {
 	return m.Msg, m.Id, nil
 }
 
+// receiveFrame reads a message without decoding its data
+func (c *connection) receiveFrame() (uint, uint32, []byte, error) {
+	var m messageToReceive
+	if err := codec.MP.Unmarshal(c.conn, &m); err != nil {
+		return 0, 0, nil, err
+	}
+	return m.Msg, m.Id, m.Data, nil
+}
+
 func (c *connection) SendAndReceive(msg uint, id uint32, data interface{}, buffer interface{}) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -1,16 +1,22 @@
 package ipc
 
 import (
+	"crypto/tls"
+	"fmt"
 	"log"
 	"net"
 	"os"
 	"sync"
+	"time"
 )
 
 type Server interface {
 	// Listen specified port to watch.
 	Listen(net, addr string) error
 
+	// ListenTLS listens tcp port with TLS. Clients must have certificate signed by CA in config.
+	ListenTLS(net, addr string, config *TLSConfig) error
+
 	// SetHandler set handler for connection. The handler can add message
 	// handler for the connection, and clean-up resource on close.
 	SetHandler(handler ConnectionHandler)
@@ -51,11 +57,37 @@ func (s *server) Listen(network, address string) error {
 	return nil
 }
 
+func (s *server) ListenTLS(network, address string, config *TLSConfig) error {
+	if !IsTCP(network) {
+		return fmt.Errorf("TLS is not supported for %s network", network)
+	}
+	tlsConfig, err := config.ServerConfig()
+	if err != nil {
+		return err
+	}
+	listener, err := tls.Listen(network, address, tlsConfig)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	return nil
+}
+
 func (s *server) SetHandler(handler ConnectionHandler) {
 	s.handler = handler
 }
 
 func (s *server) handleConnection(conn net.Conn) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		tlsConn.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+		if err := tlsConn.Handshake(); err != nil {
+			log.Printf("Fail on TLS handshake with %s err=%+v", conn.RemoteAddr(), err)
+			conn.Close()
+			return
+		}
+		tlsConn.SetDeadline(time.Time{})
+	}
+
 	co := connectionFromConn(conn)
 	handler := s.handler
 	if handler != nil {
@@ -0,0 +1,98 @@
+package ipc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// tlsHandshakeTimeout is the limit of connection and TLS handshake. A peer can't hold a connection without handshake
+var tlsHandshakeTimeout = 10 * time.Second
+
+// TLSConfig has files of TLS for tcp network.
+// Server and client verify the certificate of the peer with CA certificates in CAFile.
+type TLSConfig struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string
+	ServerName string // name in server certificate for client. host of address if it is empty
+}
+
+func IsTCP(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *TLSConfig) load() (*tls.Certificate, *x509.CertPool, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load certificate %s. %v", c.CertFile, err)
+	}
+
+	pem, err := ioutil.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CA certificate %s. %v", c.CAFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, nil, fmt.Errorf("no CA certificate in %s", c.CAFile)
+	}
+	return &cert, pool, nil
+}
+
+// ServerConfig returns configuration of server which requires certificate of client
+func (c *TLSConfig) ServerConfig() (*tls.Config, error) {
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// ClientConfig returns configuration of client which connects to address
+func (c *TLSConfig) ClientConfig(address string) (*tls.Config, error) {
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	serverName := c.ServerName
+	if len(serverName) == 0 {
+		if serverName, _, err = net.SplitHostPort(address); err != nil {
+			return nil, err
+		}
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		RootCAs:      pool,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// DialTLS connects to server with TLS. network must be tcp
+func DialTLS(network, address string, config *TLSConfig) (Connection, error) {
+	if !IsTCP(network) {
+		return nil, fmt.Errorf("TLS is not supported for %s network", network)
+	}
+	tlsConfig, err := config.ClientConfig(address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: tlsHandshakeTimeout}, network, address, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return connectionFromConn(conn), nil
+}
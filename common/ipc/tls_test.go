@@ -0,0 +1,185 @@
+package ipc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) write(t *testing.T, dir, name string) (string, string) {
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	assert.NoError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	assert.NoError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+	return certFile, keyFile
+}
+
+type echoHandler struct{}
+
+func (h *echoHandler) OnConnect(c Connection) error {
+	c.SetHandler(1, h)
+	return nil
+}
+
+func (h *echoHandler) OnClose(c Connection) error {
+	return nil
+}
+
+func (h *echoHandler) HandleMessage(c Connection, msg uint, id uint32, data []byte) error {
+	var s string
+	if _, err := codec.MP.UnmarshalFromBytes(data, &s); err != nil {
+		return err
+	}
+	return c.Send(msg, id, s)
+}
+
+func TestTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	serverCert, serverKey := newTestCert(t, "server", ca).write(t, dir, "server")
+	clientCert, clientKey := newTestCert(t, "client", ca).write(t, dir, "client")
+
+	srv := NewServer()
+	err = srv.ListenTLS("tcp", "127.0.0.1:0",
+		&TLSConfig{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+	assert.NoError(t, err)
+	srv.SetHandler(new(echoHandler))
+	go srv.Loop()
+	defer srv.Close()
+	address := srv.(*server).listener.Addr().String()
+
+	// TLS is only for tcp
+	assert.Error(t, NewServer().ListenTLS("unix", filepath.Join(dir, "sock"),
+		&TLSConfig{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile}))
+
+	// client with certificate signed by CA
+	conn, err := DialTLS("tcp", address, &TLSConfig{CertFile: clientCert, KeyFile: clientKey, CAFile: caFile})
+	assert.NoError(t, err)
+	var reply string
+	assert.NoError(t, conn.SendAndReceive(1, 10, "hello", &reply))
+	assert.Equal(t, "hello", reply)
+	conn.Close()
+
+	// client with certificate of other CA
+	otherCA := newTestCert(t, "other", nil)
+	otherCAFile, _ := otherCA.write(t, dir, "other")
+	otherCert, otherKey := newTestCert(t, "client", otherCA).write(t, dir, "otherclient")
+	conn, err = DialTLS("tcp", address, &TLSConfig{CertFile: otherCert, KeyFile: otherKey, CAFile: caFile})
+	if err == nil {
+		// server rejects the certificate after the handshake of client
+		err = conn.SendAndReceive(1, 11, "hello", &reply)
+		conn.Close()
+	}
+	assert.Error(t, err)
+
+	// client which doesn't trust server
+	_, err = DialTLS("tcp", address, &TLSConfig{CertFile: otherCert, KeyFile: otherKey, CAFile: otherCAFile})
+	assert.Error(t, err)
+}
+
+func TestTLS_handshakeTimeout(t *testing.T) {
+	timeout := tlsHandshakeTimeout
+	tlsHandshakeTimeout = 100 * time.Millisecond
+	defer func() {
+		tlsHandshakeTimeout = timeout
+	}()
+
+	dir, err := ioutil.TempDir("", "tls")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil)
+	caFile, _ := ca.write(t, dir, "ca")
+	serverCert, serverKey := newTestCert(t, "server", ca).write(t, dir, "server")
+
+	srv := NewServer()
+	err = srv.ListenTLS("tcp", "127.0.0.1:0",
+		&TLSConfig{CertFile: serverCert, KeyFile: serverKey, CAFile: caFile})
+	assert.NoError(t, err)
+	srv.SetHandler(new(echoHandler))
+	go srv.Loop()
+	defer srv.Close()
+	address := srv.(*server).listener.Addr().String()
+
+	// server closes connection without handshake
+	conn, err := net.Dial("tcp", address)
+	assert.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+	if ne, ok := err.(net.Error); ok {
+		assert.False(t, ne.Timeout())
+	}
+
+	// client gives up server which doesn't handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		if c, err := listener.Accept(); err == nil {
+			time.Sleep(time.Second)
+			c.Close()
+		}
+	}()
+	start := time.Now()
+	_, err = DialTLS("tcp", listener.Addr().String(), &TLSConfig{CertFile: serverCert, KeyFile: serverKey,
+		CAFile: caFile})
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
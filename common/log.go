@@ -25,9 +25,13 @@ func (l *Log) Write(p []byte) (n int, err error) {
 	return l.logger.Write(buf)
 }
 
+var rcLog *Log
+
+// SetLog sets output of standard logger. It can be called again to change log settings.
 func SetLog(file string, maxSize int, maxBackups int, localtime bool) {
 	log.SetFlags(log.Lshortfile)
-	rcLog := Log{
+	oldLog := rcLog
+	rcLog = &Log{
 		lumberjack.Logger{
 			Filename:   file,
 			MaxSize:    maxSize,
@@ -35,6 +39,11 @@ func SetLog(file string, maxSize int, maxBackups int, localtime bool) {
 			LocalTime:  localtime,
 		},
 	}
-	log.SetOutput(&rcLog)
+	log.SetOutput(rcLog)
+
+	// close old log file
+	if oldLog != nil {
+		oldLog.logger.Close()
+	}
 }
 
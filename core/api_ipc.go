@@ -1,27 +1,50 @@
 package core
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/crypto"
 	"github.com/icon-project/rewardcalculator/common/ipc"
 )
 
+// RCIPC is a client of Reward Calculator. Requests can be sent from many goroutines concurrently
 type RCIPC struct {
-	conn ipc.Connection
-	id   uint32
+	client        *ipc.Client
+	calculateDone chan *ipc.Message
+
+	lock             sync.Mutex
+	nonce            []byte // nonce of signed messages from NegotiateVersion
+	calculateTimeout time.Duration
 }
 
+// DefaultCalculateTimeout is the timeout of RCIPC.SendCalculate
+const DefaultCalculateTimeout = time.Hour
+
 func InitRCIPC(net string, address string) (*RCIPC, error) {
+	return InitRCIPCWithTLS(net, address, nil)
+}
+
+// InitRCIPCWithTLS connects to Reward Calculator with TLS. It connects without TLS if tlsConfig is nil
+func InitRCIPCWithTLS(net string, address string, tlsConfig *ipc.TLSConfig) (*RCIPC, error) {
 	rc := new(RCIPC)
 
 	// Connect to server
 	retry := 0
 RETRY:
-	conn, err := ipc.Dial(net, address)
+	var conn ipc.Connection
+	var err error
+	if tlsConfig != nil {
+		conn, err = ipc.DialTLS(net, address, tlsConfig)
+	} else {
+		conn, err = ipc.Dial(net, address)
+	}
 	if err != nil {
 		if retry != 5 {
 			time.Sleep(200 * time.Millisecond)
@@ -31,7 +54,6 @@ RETRY:
 		fmt.Printf("Failed to dial %s:%s with %d tries. err=%+v\n", net, address, retry, err)
 		return nil, err
 	}
-	rc.conn = conn
 
 	// flush READY message
 	for true {
@@ -42,17 +64,73 @@ RETRY:
 		}
 	}
 
+	if rc.client, err = ipc.NewClient(conn, IsNotification); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	rc.calculateTimeout = DefaultCalculateTimeout
+	rc.calculateDone = make(chan *ipc.Message, 16)
+	rc.client.Subscribe(MsgCalculateDone, rc.calculateDone)
+
 	return rc, nil
 }
 
+// SetSigner signs CLAIM, COMMIT_CLAIM, COMMIT_BLOCK, CALCULATE, ROLLBACK and INIT with key.
+// Signed messages need the nonce of the connection, so negotiate SIGNED_MESSAGE with NegotiateVersion() first
+func (rc *RCIPC) SetSigner(key *crypto.PrivateKey) {
+	rc.client.SetEncoder(messageSigner(key, rc.getNonce))
+}
+
+func (rc *RCIPC) getNonce() []byte {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	return rc.nonce
+}
+
+// SetTimeout sets timeout of requests. SetCalculateTimeout sets that of SendCalculate. Set it before sending requests
+func (rc *RCIPC) SetTimeout(timeout time.Duration) {
+	rc.client.Timeout = timeout
+}
+
+// SetCalculateTimeout sets timeout of SendCalculate which waits for CALCULATE_DONE
+func (rc *RCIPC) SetCalculateTimeout(timeout time.Duration) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	rc.calculateTimeout = timeout
+}
+
+func (rc *RCIPC) getCalculateTimeout() time.Duration {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	return rc.calculateTimeout
+}
+
+// Subscribe delivers notifications of msg to ch. ex) CALCULATE_PROGRESS
+func (rc *RCIPC) Subscribe(msg uint, ch chan<- *ipc.Message) {
+	rc.client.Subscribe(msg, ch)
+}
+
 func FiniRCIPC(ipc *RCIPC) {
-	ipc.conn.Close()
+	ipc.client.Close()
 }
 
 func (rc *RCIPC) SendVersion() (*ResponseVersion, error) {
 	resp := new(ResponseVersion)
-	rc.id++
-	err := rc.conn.SendAndReceive(MsgVersion, rc.id, nil, resp)
+	err := rc.client.Call(MsgVersion, nil, resp)
+	return resp, err
+}
+
+// NegotiateVersion sends VERSION with capabilities of the client.
+// Response has Version 0 if Reward Calculator refused it.
+func (rc *RCIPC) NegotiateVersion(capabilities []string) (*ResponseVersionNegotiation, error) {
+	req := VersionRequest{Version: IPCVersion, MinVersion: IPCMinVersion, Capabilities: capabilities}
+	resp := new(ResponseVersionNegotiation)
+	err := rc.client.Call(MsgVersion, &req, resp)
+	if err == nil {
+		rc.lock.Lock()
+		rc.nonce = resp.Nonce
+		rc.lock.Unlock()
+	}
 	return resp, err
 }
 
@@ -88,8 +166,7 @@ func (rc *RCIPC) SendClaim(address string, blockHeight uint64, blockHash string,
 	}
 
 	log.Printf("Send CLAIM message: %s\n", req.String())
-	rc.id++
-	err := rc.conn.SendAndReceive(MsgClaim, rc.id, &req, resp)
+	err := rc.client.Call(MsgClaim, &req, resp)
 	if err != nil {
 		return resp, err
 	}
@@ -137,12 +214,78 @@ func (rc *RCIPC) SendQuery(address string, blockHeight uint64, blockHash string,
 	}
 	copy(req.TXHash, th)
 
-	err = rc.conn.SendAndReceive(MsgQuery, rc.id, &req, resp)
+	err = rc.client.Call(MsgQuery, &req, resp)
 
 	return resp, err
 }
 
+func (rc *RCIPC) SendQueryRewardLedger(address string, blockHeight uint64) (*ResponseQueryRewardLedger, error) {
+	var req QueryRewardLedger
+	resp := new(ResponseQueryRewardLedger)
+
+	req.Address.SetString(address)
+	req.BlockHeight = blockHeight
+
+	err := rc.client.Call(MsgQueryRewardLedger, &req, resp)
+	if err != nil {
+		log.Printf("Failed to get QUERY_REWARD_LEDGER response. %v", err)
+		return nil, err
+	}
+	log.Printf("Get QUERY_REWARD_LEDGER response: %s\n", resp.String())
+	return resp, nil
+}
+
+// SendQueryBatch sends QUERY_BATCH. Set cursor to Next of the previous response for next page
+func (rc *RCIPC) SendQueryBatch(addresses []string, cursor []byte, limit uint64) (*ResponseQueryBatch, error) {
+	var req QueryBatch
+	resp := new(ResponseQueryBatch)
+
+	req.Addresses = make([]common.Address, len(addresses))
+	for i, address := range addresses {
+		req.Addresses[i].SetString(address)
+	}
+	req.Cursor = cursor
+	req.Limit = limit
+
+	err := rc.client.Call(MsgQueryBatch, &req, resp)
+	if err != nil {
+		log.Printf("Failed to get QUERY_BATCH response. %v", err)
+		return nil, err
+	}
+	log.Printf("Get QUERY_BATCH response: %s\n", resp.String())
+	if resp.Error != "" {
+		return resp, fmt.Errorf("failed to query batch. %s", resp.Error)
+	}
+	return resp, nil
+}
+
+func (rc *RCIPC) SendQueryProof(address string) (*ResponseQueryProof, error) {
+	var req QueryProof
+	resp := new(ResponseQueryProof)
+
+	req.Address.SetString(address)
+
+	err := rc.client.Call(MsgQueryProof, &req, resp)
+	if err != nil {
+		log.Printf("Failed to get QUERY_PROOF response. %v", err)
+		return nil, err
+	}
+	log.Printf("Get QUERY_PROOF response: %s\n", resp.String())
+	return resp, nil
+}
+
+// SendCalculate sends CALCULATE and waits for CALCULATE_DONE of the block height in its response
+// for the timeout set by SetCalculateTimeout()
 func (rc *RCIPC) SendCalculate(iissData string, blockHeight uint64) (*CalculateResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rc.getCalculateTimeout())
+	defer cancel()
+	return rc.SendCalculateWithContext(ctx, iissData, blockHeight)
+}
+
+// SendCalculateWithContext sends CALCULATE and waits for CALCULATE_DONE of the block height in its response
+// until ctx is done
+func (rc *RCIPC) SendCalculateWithContext(ctx context.Context, iissData string,
+	blockHeight uint64) (*CalculateResponse, error) {
 	var req CalculateRequest
 	resp := new(CalculateResponse)
 
@@ -150,7 +293,7 @@ func (rc *RCIPC) SendCalculate(iissData string, blockHeight uint64) (*CalculateR
 	req.BlockHeight = blockHeight
 
 	// Send CALCULATE and get response
-	err := rc.conn.SendAndReceive(MsgCalculate, rc.id, &req, resp)
+	err := rc.client.Request(ctx, MsgCalculate, &req, resp)
 	if err != nil {
 		log.Printf("Failed to get CALCULATE response. %v", err)
 		return nil, err
@@ -160,20 +303,27 @@ func (rc *RCIPC) SendCalculate(iissData string, blockHeight uint64) (*CalculateR
 		return resp, nil
 	}
 
-	// Get CALCULATE_DONE
-	var respDone CalculateDone
-	msg, id, err := rc.conn.Receive(&respDone)
-	if err != nil {
-		log.Printf("Failed to get CALCULATE_DONE. %v", err)
-		return resp, err
-	}
-	if msg == MsgCalculateDone {
-		log.Printf("Get CALCULATE_DONE: %s\n", respDone.String())
-	} else {
-		log.Printf("Get invalid response : (msg:%d, id:%d)\n", msg, id)
+	// Get CALCULATE_DONE of the block height in the response. It is the block height of IISS data
+	for {
+		select {
+		case m := <-rc.calculateDone:
+			var respDone CalculateDone
+			if err = m.Decode(&respDone); err != nil {
+				log.Printf("Failed to decode CALCULATE_DONE. %v", err)
+				return resp, err
+			}
+			log.Printf("Get CALCULATE_DONE: %s\n", respDone.String())
+			if respDone.BlockHeight == resp.BlockHeight {
+				return resp, nil
+			}
+		case <-ctx.Done():
+			log.Printf("Failed to get CALCULATE_DONE of %d. %v", resp.BlockHeight, ctx.Err())
+			return resp, ctx.Err()
+		case <-rc.client.Done():
+			log.Printf("Failed to get CALCULATE_DONE. connection closed")
+			return resp, ipc.ErrClientClosed
+		}
 	}
-
-	return resp, nil
 }
 
 func (rc *RCIPC) SendStartBlock(success bool, blockHeight uint64, blockHash string) (*StartBlock, error) {
@@ -194,8 +344,7 @@ func (rc *RCIPC) SendStartBlock(success bool, blockHeight uint64, blockHash stri
 	req.BlockHeight = blockHeight
 
 	log.Printf("Send START_BLOCK message: %s\n", req.String())
-	rc.id++
-	err := rc.conn.SendAndReceive(MsgStartBlock, rc.id, &req, &resp)
+	err := rc.client.Call(MsgStartBlock, &req, &resp)
 	log.Printf("Get START_BLOCK response: %s\n", resp.String())
 
 	return resp, err
@@ -220,8 +369,7 @@ func (rc *RCIPC) SendCommitBlock(success bool, blockHeight uint64, blockHash str
 	req.BlockHeight = blockHeight
 
 	log.Printf("Send COMMIT_BLOCK message: %s\n", req.String())
-	rc.id++
-	err := rc.conn.SendAndReceive(MsgCommitBlock, rc.id, &req, &resp)
+	err := rc.client.Call(MsgCommitBlock, &req, &resp)
 	log.Printf("Get COMMIT_BLOCK response: %s\n", resp.String())
 
 	return resp, err
@@ -259,8 +407,7 @@ func (rc *RCIPC) SendCommitClaim(success bool, address string, blockHeight uint6
 	}
 
 	log.Printf("Send COMMIT_CLAIM message: %s\n", req.String())
-	rc.id++
-	err := rc.conn.SendAndReceive(MsgCommitClaim, rc.id, &req, nil)
+	err := rc.client.Call(MsgCommitClaim, &req, nil)
 	log.Printf("Get COMMIT_CLAIM ack. %v\n", err)
 
 	return err
@@ -270,7 +417,7 @@ func (rc *RCIPC) SendQueryCalculateStatus() (*QueryCalculateStatusResponse, erro
 	resp := new(QueryCalculateStatusResponse)
 
 	// Send QUERY_CALCULATE_STATUS and get response
-	err := rc.conn.SendAndReceive(MsgQueryCalculateStatus, rc.id, nil, resp)
+	err := rc.client.Call(MsgQueryCalculateStatus, nil, resp)
 	if err != nil {
 		log.Printf("Failed to get QUERY_CALCULATE_STATUS response. %v", err)
 		return nil, err
@@ -283,7 +430,7 @@ func (rc *RCIPC) SendQueryCalculateResult(blockHeight uint64) (*QueryCalculateRe
 	resp := new(QueryCalculateResultResponse)
 
 	// Send QUERY_CALCULATE_RESULT and get response
-	err := rc.conn.SendAndReceive(MsgQueryCalculateResult, rc.id, &blockHeight, resp)
+	err := rc.client.Call(MsgQueryCalculateResult, &blockHeight, resp)
 	if err != nil {
 		log.Printf("Failed to get QUERY_CALCULATE_RESULT response. %v", err)
 		return nil, err
@@ -307,7 +454,7 @@ func (rc *RCIPC) SendRollback(blockHeight uint64, blockHash string) (*RollBackRe
 	req.BlockHash = make([]byte, BlockHashSize)
 	copy(req.BlockHash, hash)
 
-	err = rc.conn.SendAndReceive(MsgRollBack, rc.id, &req, &resp)
+	err = rc.client.Call(MsgRollBack, &req, &resp)
 	if err != nil {
 		log.Printf("Failed to ROLLBACK response. %v\n", err)
 		return nil, err
@@ -319,7 +466,7 @@ func (rc *RCIPC) SendRollback(blockHeight uint64, blockHash string) (*RollBackRe
 func (rc *RCIPC) SendInit(blockHeight uint64) (*ResponseInit, error) {
 	resp := new(ResponseInit)
 
-	err := rc.conn.SendAndReceive(MsgINIT, rc.id, &blockHeight, &resp)
+	err := rc.client.Call(MsgINIT, &blockHeight, &resp)
 	if err != nil {
 		log.Printf("Failed to INIT response. %v\n", err)
 		return nil, err
@@ -327,3 +474,19 @@ func (rc *RCIPC) SendInit(blockHeight uint64) (*ResponseInit, error) {
 	fmt.Printf("Get INIT response: %s\n", resp.String())
 	return resp, nil
 }
+
+func (rc *RCIPC) SendQueryEstimate(address string, blockHeight uint64) (*ResponseQueryEstimate, error) {
+	var req QueryEstimate
+	resp := new(ResponseQueryEstimate)
+
+	req.Address.SetString(address)
+	req.BlockHeight = blockHeight
+
+	err := rc.client.Call(MsgQueryEstimate, &req, resp)
+	if err != nil {
+		log.Printf("Failed to get QUERY_ESTIMATE response. %v", err)
+		return nil, err
+	}
+	log.Printf("Get QUERY_ESTIMATE response: %s\n", resp.String())
+	return resp, nil
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/crypto"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRCIPC_concurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rcipc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	address := filepath.Join(dir, "rc.sock")
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	key, pub := crypto.GenerateKeyPair()
+	m := newTestManager(ctx)
+	m.auth = &msgAuth{keys: []*crypto.PublicKey{pub}}
+
+	srv := ipc.NewServer()
+	assert.NoError(t, srv.Listen("unix", address))
+	srv.SetHandler(m)
+	go srv.Loop()
+	defer srv.Close()
+
+	rc, err := InitRCIPC("unix", address)
+	assert.NoError(t, err)
+	defer FiniRCIPC(rc)
+
+	// requests from goroutines on a connection
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			resp, err := rc.SendVersion()
+			assert.NoError(t, err)
+			assert.Equal(t, IPCMinVersion, resp.Version)
+		}()
+		go func() {
+			defer wg.Done()
+			resp, err := rc.SendQueryCalculateStatus()
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(CalculationDone), resp.Status)
+		}()
+	}
+	wg.Wait()
+
+	// signed message with the nonce from VERSION
+	vResp, err := rc.NegotiateVersion(SupportedCapabilities())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(IPCVersion), vResp.Version)
+	rc.SetSigner(key)
+	resp, err := rc.SendInit(1)
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+}
+
+func TestRCIPC_SendQueryBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rcipc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	address := filepath.Join(dir, "rc.sock")
+
+	ctx := initTest(3)
+	defer finalizeTest(ctx)
+	writeTestAccounts(makeTestAccounts(10), ctx.DB.getQueryDB)
+	m := newTestManager(ctx)
+
+	srv := ipc.NewServer()
+	assert.NoError(t, srv.Listen("unix", address))
+	srv.SetHandler(m)
+	go srv.Loop()
+	defer srv.Close()
+
+	rc, err := InitRCIPC("unix", address)
+	assert.NoError(t, err)
+	defer FiniRCIPC(rc)
+	_, err = rc.NegotiateVersion(SupportedCapabilities())
+	assert.NoError(t, err)
+
+	// pages with Next of the previous response
+	var cursor []byte
+	count := 0
+	for {
+		resp, err := rc.SendQueryBatch(nil, cursor, 3)
+		assert.NoError(t, err)
+		count += len(resp.Results)
+		if len(resp.Next) == 0 {
+			break
+		}
+		cursor = resp.Next
+	}
+	assert.Equal(t, 10, count)
+
+	// too many addresses
+	addresses := make([]string, MaxQueryBatchCount+1)
+	for i := range addresses {
+		addresses[i] = "hx1234"
+	}
+	resp, err := rc.SendQueryBatch(addresses, nil, 0)
+	assert.Error(t, err)
+	assert.NotEqual(t, "", resp.Error)
+	assert.Equal(t, 0, len(resp.Results))
+}
+
+// calculateServer responds to CALCULATE and sends CALCULATE_DONE of doneBHs.
+// The response has lastBH for CALCULATE of block height 0 like IISS data of the next term
+type calculateServer struct {
+	lastBH  uint64
+	doneBHs []uint64
+}
+
+func (s *calculateServer) OnConnect(c ipc.Connection) error {
+	c.SetHandler(MsgCalculate, s)
+	return c.Send(MsgReady, 0, ResponseVersion{Version: IPCMinVersion})
+}
+
+func (s *calculateServer) OnClose(c ipc.Connection) error {
+	return nil
+}
+
+func (s *calculateServer) HandleMessage(c ipc.Connection, msg uint, id uint32, data []byte) error {
+	var req CalculateRequest
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		return err
+	}
+	if req.BlockHeight == 0 {
+		req.BlockHeight = s.lastBH
+	}
+	resp := CalculateResponse{Status: CalcRespStatusOK, BlockHeight: req.BlockHeight}
+	if err := c.Send(MsgCalculate, id, resp); err != nil {
+		return err
+	}
+	for _, bh := range s.doneBHs {
+		if err := c.Send(MsgCalculateDone, 0, CalculateDone{Success: true, BlockHeight: bh}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRCIPC_SendCalculate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rcipc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	address := filepath.Join(dir, "rc.sock")
+
+	handler := &calculateServer{lastBH: 10, doneBHs: []uint64{9, 10}}
+	srv := ipc.NewServer()
+	assert.NoError(t, srv.Listen("unix", address))
+	srv.SetHandler(handler)
+	go srv.Loop()
+	defer srv.Close()
+
+	rc, err := InitRCIPC("unix", address)
+	assert.NoError(t, err)
+	defer FiniRCIPC(rc)
+
+	// block height 0 waits for CALCULATE_DONE of the block height in the response
+	resp, err := rc.SendCalculate("iiss", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), resp.BlockHeight)
+
+	// no CALCULATE_DONE of the block height
+	rc.SetCalculateTimeout(100 * time.Millisecond)
+	_, err = rc.SendCalculate("iiss", 11)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
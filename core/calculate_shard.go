@@ -0,0 +1,77 @@
+package core
+
+import (
+	"log"
+	"sync"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+// shardWriter reads and writes accounts of a calculate DB with batch.
+// Accounts in the batch are cached, so they can be read before the batch is written.
+type shardWriter struct {
+	bucket     db.Bucket
+	batch      db.Batch
+	batchCount uint64
+	cache      map[common.Address][]byte
+}
+
+// newShardWriter returns writer of calculate DB. It writes without batch if batchCount is 0
+func newShardWriter(cDB db.Database, batchCount uint64) *shardWriter {
+	w := &shardWriter{batchCount: batchCount}
+	w.bucket, _ = cDB.GetBucket(db.PrefixIScore)
+	if batchCount > 0 {
+		w.batch, _ = cDB.GetBatch()
+		w.batch.New()
+		w.cache = make(map[common.Address][]byte)
+	}
+	return w
+}
+
+func (w *shardWriter) get(address common.Address) []byte {
+	if data, ok := w.cache[address]; ok {
+		return data
+	}
+	data, _ := w.bucket.Get(address.Bytes())
+	return data
+}
+
+func (w *shardWriter) set(ia *IScoreAccount) {
+	if w.batchCount == 0 {
+		w.bucket.Set(ia.ID(), ia.Bytes())
+		return
+	}
+
+	data := ia.Bytes()
+	w.batch.Set(append([]byte(db.PrefixIScore), ia.ID()...), data)
+	w.cache[ia.Address] = data
+	if uint64(w.batch.Len()) >= w.batchCount {
+		w.flush()
+	}
+}
+
+// flush writes batch to calculate DB
+func (w *shardWriter) flush() {
+	if w.batchCount == 0 || w.batch.Len() == 0 {
+		return
+	}
+	if err := w.batch.Write(); err != nil {
+		log.Printf("Failed to write batch. %v", err)
+	}
+	w.batch.Reset()
+	w.cache = make(map[common.Address][]byte)
+}
+
+// runShards calls f with each account DB index concurrently and waits for all
+func runShards(dbCount int, f func(index int)) {
+	var wait sync.WaitGroup
+	wait.Add(dbCount)
+	for i := 0; i < dbCount; i++ {
+		go func(index int) {
+			defer wait.Done()
+			f(index)
+		}(i)
+	}
+	wait.Wait()
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type shardResult struct {
+	newAccount []uint64
+	reward     []string
+	hash       [][]byte
+	accounts   map[string]string
+}
+
+// calculateShards calculates IISS TX, block produce and P-Rep reward with dbCount account DBs
+func calculateShards(t *testing.T, dbCount int, batchCount uint64) *shardResult {
+	const (
+		accountCount        = 20
+		blockHeight  uint64 = 100
+	)
+
+	ctx := initTest(dbCount)
+	defer finalizeTest(ctx)
+
+	gv := new(GovernanceVariable)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
+	gv.CalculatedIncentiveRep.SetUint64(10)
+	gv.RewardRep.SetUint64(testNetwork.MinRewardRep)
+	gv.setReward(testNetwork)
+	ctx.GV = append(ctx.GV, gv)
+
+	preps := make([]common.Address, 0)
+	for i := 0; i < 3; i++ {
+		prep := new(PRepCandidate)
+		prep.Address = *common.NewAddressFromString(fmt.Sprintf("hxa%d", i))
+		ctx.PRepCandidates[prep.Address] = prep
+		preps = append(preps, prep.Address)
+	}
+
+	iissDBDir := testDBDir + "/iiss"
+	iissDB := db.Open(iissDBDir, string(db.GoLevelDBBackend), testDB)
+	defer iissDB.Close()
+	defer os.RemoveAll(iissDBDir)
+
+	// each account delegates twice, so the second TX reads the account of the first one
+	txList := make([]*IISSTX, 0)
+	for i := 0; i < 2*accountCount; i++ {
+		dgDataSlice := []DelegateData{
+			{preps[i%len(preps)], *common.NewHexIntFromUint64(testNetwork.MinDelegation() * uint64(i+1))},
+		}
+		tx := makeIISSTX(TXDataTypeDelegate, fmt.Sprintf("hx%02x", i%accountCount), dgDataSlice)
+		tx.Index = uint64(i)
+		tx.BlockHeight = uint64(i + 1)
+		txList = append(txList, tx)
+	}
+	writeTX(iissDB, txList)
+
+	bucket, _ := iissDB.GetBucket(db.PrefixIISSBPInfo)
+	for i := 0; i < 10; i++ {
+		bp := new(IISSBlockProduceInfo)
+		bp.BlockHeight = uint64(i + 1)
+		bp.Generator = preps[i%len(preps)]
+		bp.Validator = []common.Address{preps[(i+1)%len(preps)], preps[(i+2)%len(preps)]}
+		bs, _ := bp.Bytes()
+		bucket.Set(bp.ID(), bs)
+	}
+
+	for i, bh := range []uint64{0, 50} {
+		prep := new(PRep)
+		prep.BlockHeight = bh
+		for j := 0; j < accountCount; j += i + 1 {
+			dInfo := PRepDelegationInfo{Address: *common.NewAddressFromString(fmt.Sprintf("hx%02x", j))}
+			dInfo.DelegatedAmount.SetUint64(uint64(j + 1))
+			prep.List = append(prep.List, dInfo)
+			prep.TotalDelegation.Add(&prep.TotalDelegation.Int, &dInfo.DelegatedAmount.Int)
+		}
+		ctx.PRep = append(ctx.PRep, prep)
+	}
+
+	result := &shardResult{accounts: make(map[string]string)}
+	add := func(account uint64, reward *common.HexInt, hash []byte) {
+		result.newAccount = append(result.newAccount, account)
+		result.reward = append(result.reward, reward.String())
+		result.hash = append(result.hash, hash)
+	}
+	add(calculateIISSTX(ctx, iissDB, blockHeight, batchCount, false))
+	add(calculateIISSBlockProduce(ctx, iissDB, blockHeight, batchCount, false))
+	add(calculatePRepReward(ctx, blockHeight, batchCount))
+
+	err := mergeAccountDBs(ctx.DB.GetCalcDBList(), func(key []byte, value []byte) error {
+		result.accounts[string(key)] = string(value)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	return result
+}
+
+func TestCalculateShard_DBCount(t *testing.T) {
+	expected := calculateShards(t, 1, 0)
+	assert.Equal(t, []uint64{20, 3, 0}, expected.newAccount)
+	assert.Equal(t, 23, len(expected.accounts))
+
+	assert.Equal(t, expected, calculateShards(t, 1, 3))
+	assert.Equal(t, expected, calculateShards(t, 4, 0))
+	assert.Equal(t, expected, calculateShards(t, 4, 3))
+}
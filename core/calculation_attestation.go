@@ -0,0 +1,90 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/crypto"
+)
+
+// ResultAttestation is the calculation result which Reward Calculator signs with its node key.
+// Signature is [R|S|V] of SHA3-256 of msgpack [BlockHeight, StateHash, IScore, Beta1, Beta2, Beta3, StateRoot]
+type ResultAttestation struct {
+	BlockHeight uint64
+	StateHash   []byte
+	IScore      common.HexInt
+	Beta1       common.HexInt
+	Beta2       common.HexInt
+	Beta3       common.HexInt
+	StateRoot   []byte
+}
+
+// newResultAttestation makes the attestation of CalculationResult, CALCULATE_DONE and QUERY_CALCULATE_RESULT
+func newResultAttestation(blockHeight uint64, stateHash []byte, stateRoot []byte, iScore *common.HexInt,
+	beta1 *common.HexInt, beta2 *common.HexInt, beta3 *common.HexInt) *ResultAttestation {
+	ra := &ResultAttestation{
+		BlockHeight: blockHeight,
+		StateHash:   stateHash,
+		StateRoot:   stateRoot,
+	}
+	ra.IScore.Set(&iScore.Int)
+	ra.Beta1.Set(&beta1.Int)
+	ra.Beta2.Set(&beta2.Int)
+	ra.Beta3.Set(&beta3.Int)
+	return ra
+}
+
+func (ra *ResultAttestation) hash() ([]byte, error) {
+	bs, err := codec.MP.MarshalToBytes(ra)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.SHA3Sum256(bs), nil
+}
+
+// Sign returns signature of the result with key
+func (ra *ResultAttestation) Sign(key *crypto.PrivateKey) ([]byte, error) {
+	h, err := ra.hash()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.NewSignature(h, key)
+	if err != nil {
+		return nil, err
+	}
+	return sig.SerializeRSV()
+}
+
+// Signer recovers public key of Reward Calculator which signed the result
+func (ra *ResultAttestation) Signer(signature []byte) (*crypto.PublicKey, error) {
+	if len(signature) == 0 {
+		return nil, fmt.Errorf("no signature")
+	}
+	sig, err := crypto.ParseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	h, err := ra.hash()
+	if err != nil {
+		return nil, err
+	}
+	return sig.RecoverPublicKey(h)
+}
+
+// LoadNodeKey reads private key in hex from file
+func LoadNodeKey(fileName string) (*crypto.PrivateKey, error) {
+	bs, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(string(bs)), "0x")
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node key file %s. %v", fileName, err)
+	}
+	return crypto.ParsePrivateKey(key)
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultAttestation_Sign(t *testing.T) {
+	key, pub := crypto.GenerateKeyPair()
+
+	ra := &ResultAttestation{BlockHeight: 100, StateHash: []byte{1, 2, 3}}
+	ra.IScore.SetUint64(1000)
+	ra.Beta1.SetUint64(10)
+	ra.Beta2.SetUint64(20)
+	ra.Beta3.SetUint64(970)
+
+	sig, err := ra.Sign(key)
+	assert.NoError(t, err)
+	signer, err := ra.Signer(sig)
+	assert.NoError(t, err)
+	assert.True(t, signer.Equal(pub))
+
+	// other result
+	ra.Beta3.SetUint64(971)
+	signer, err = ra.Signer(sig)
+	if err == nil {
+		assert.False(t, signer.Equal(pub))
+	}
+
+	// other state root
+	ra.Beta3.SetUint64(970)
+	ra.StateRoot = []byte{4, 5, 6}
+	signer, err = ra.Signer(sig)
+	if err == nil {
+		assert.False(t, signer.Equal(pub))
+	}
+
+	_, err = ra.Signer(nil)
+	assert.Error(t, err)
+}
+
+func TestLoadNodeKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nodekey")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	key, _ := crypto.GenerateKeyPair()
+	fileName := filepath.Join(dir, "key")
+	assert.NoError(t, ioutil.WriteFile(fileName, []byte("0x"+hex.EncodeToString(key.Bytes())+"\n"), 0600))
+	loaded, err := LoadNodeKey(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Bytes(), loaded.Bytes())
+
+	assert.NoError(t, ioutil.WriteFile(fileName, []byte("xyz"), 0600))
+	_, err = LoadNodeKey(fileName)
+	assert.Error(t, err)
+
+	_, err = LoadNodeKey(filepath.Join(dir, "none"))
+	assert.Error(t, err)
+}
+
+func TestDoQueryCalculateResult_signature(t *testing.T) {
+	const blockHeight uint64 = 100
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	key, pub := crypto.GenerateKeyPair()
+
+	stats := new(Statistics)
+	stats.TotalReward.SetUint64(600)
+	stats.Beta1.SetUint64(100)
+	stats.Beta2.SetUint64(200)
+	stats.Beta3.SetUint64(300)
+	cr := WriteCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight, stats, []byte{1, 2}, nil, key)
+	assert.NotEmpty(t, cr.Signature)
+
+	var resp QueryCalculateResultResponse
+	DoQueryCalculateResult(ctx, blockHeight, &resp)
+	assert.Equal(t, calcSucceeded, resp.Status)
+	assert.Equal(t, cr.Signature, resp.Signature)
+	assert.Equal(t, 0, resp.Beta2.Cmp(&stats.Beta2.Int))
+	signer, err := resp.Attestation().Signer(resp.Signature)
+	assert.NoError(t, err)
+	assert.True(t, signer.Equal(pub))
+
+	// CALCULATE_DONE has the same attestation
+	done := CalculateDone{Success: true, BlockHeight: blockHeight, StateHash: cr.StateHash, StateRoot: cr.StateRoot,
+		Signature: cr.Signature}
+	done.IScore.Set(&cr.IScore.Int)
+	done.Beta1.Set(&cr.Beta1.Int)
+	done.Beta2.Set(&cr.Beta2.Int)
+	done.Beta3.Set(&cr.Beta3.Int)
+	assert.Equal(t, cr.Attestation(), done.Attestation())
+	assert.Equal(t, cr.Attestation(), resp.Attestation())
+
+	// state root is signed
+	cr = WriteCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight, stats, []byte{1, 2}, []byte{3, 4}, key)
+	ra := cr.Attestation()
+	ra.StateRoot = nil
+	signer, err = ra.Signer(cr.Signature)
+	if err == nil {
+		assert.False(t, signer.Equal(pub))
+	}
+
+	// not signed without node key
+	cr = WriteCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight+1, stats, []byte{1, 2}, nil, nil)
+	assert.Empty(t, cr.Signature)
+}
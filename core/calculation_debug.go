@@ -10,9 +10,12 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"sync"
 )
 
 type CalcDebug struct {
+	// calculation starts with lock, so configuration is not replaced while calculating
+	lock   sync.Mutex
 	conf   *CalcDebugConfig
 	result *CalcDebugResult
 }
@@ -176,6 +179,43 @@ func InitCalcDebugConfig(ctx *Context, debugConfigPath string) {
 	}
 }
 
+// ReloadCalcDebugConfig replaces calculation debug configuration with the file.
+// Can't replace while calculating because debug result of calculation depends on it.
+func ReloadCalcDebugConfig(ctx *Context, debugConfigPath string) error {
+	// no debug config file means that debugging is disabled
+	conf := NewCalcDebugConfig()
+	debugConfig, err := os.Open(debugConfigPath)
+	if err == nil {
+		defer debugConfig.Close()
+
+		cfgByte, err := ioutil.ReadAll(debugConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(cfgByte, conf); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	ctx.calcDebug.lock.Lock()
+	defer ctx.calcDebug.lock.Unlock()
+	if ctx.DB.isCalculating() {
+		return fmt.Errorf("calculating now. try again after calculation")
+	}
+	ctx.calcDebug.conf = conf
+
+	return nil
+}
+
+// startCalculation sets calculating block height. Calculation debug configuration can't be reloaded after it
+func startCalculation(ctx *Context, blockHeight uint64, blockHash []byte) {
+	ctx.calcDebug.lock.Lock()
+	defer ctx.calcDebug.lock.Unlock()
+	ctx.DB.setCalculatingBH(blockHeight, blockHash)
+}
+
 func NeedToUpdateCalcDebugResult(ctx *Context) bool {
 	return ctx.calcDebug.conf.Flag && len(ctx.calcDebug.conf.Addresses) > 0
 }
@@ -303,6 +343,7 @@ func writeBeta3Info(ctx *Context, debugAddress *common.Address, rewardAddress co
 	if rewardAddress.Equal(debugAddress) {
 		calcResult := GetCalcResult(ctx, *debugAddress)
 		reward := getReward(calcResult, endBlock)
+		rewardDivider := ctx.network.RewardDivider()
 		iScore := rewardRep * period * delegationInfo.Delegate.Uint64() / rewardDivider
 		dgInfo := &DelegationInfo{BlockHeight: endBlock, Address: delegationInfo.Address,
 			Amount: delegationInfo.Delegate.Uint64(), IScore: iScore}
@@ -369,7 +410,7 @@ func InitCalcResult(ctx *Context, address common.Address) {
 }
 
 func WriteCalcDebugResult(ctx *Context) {
-	calcDebugDB := db.Open(ctx.DB.info.DBRoot, string(db.GoLevelDBBackend), "calculation_debug")
+	calcDebugDB := db.Open(ctx.DB.info.DBRoot, ctx.DB.info.DBType, "calculation_debug")
 	defer calcDebugDB.Close()
 	bucket, _ := calcDebugDB.GetBucket("")
 	b, err := ctx.calcDebug.result.Bytes()
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+// DryRunResult is the result of a calculation which was not written to DB
+type DryRunResult struct {
+	BlockHeight uint64
+	Stats       Statistics
+	StateHash   []byte
+	StateRoot   []byte
+	Deltas      []*AccountDelta // accounts whose I-Score was changed, in address order
+}
+
+func (r *DryRunResult) String() string {
+	return fmt.Sprintf("BlockHeight: %d, Stats: %s, StateHash: %s, StateRoot: %s, Deltas: %d",
+		r.BlockHeight,
+		r.Stats.String(),
+		hex.EncodeToString(r.StateHash),
+		hex.EncodeToString(r.StateRoot),
+		len(r.Deltas))
+}
+
+// AccountDelta is I-Score of an account before and after the calculation.
+// I-Score is not subtracted by claimed I-Score.
+type AccountDelta struct {
+	Address   common.Address
+	OldIScore common.HexInt
+	NewIScore common.HexInt
+	Delta     common.HexInt
+}
+
+// newDryRunContext returns a copy of ctx for the calculation of blockHeight.
+// Account DBs which the calculation reads are query DBs of the copy. Calculate DBs and management DB of the copy
+// are layers over DBs of ctx, so the calculation does not change DB of ctx. Reward ledger is disabled.
+func newDryRunContext(ctx *Context, blockHeight uint64) *Context {
+	info := *ctx.DB.info
+	info.QueryDBIsZero = true
+
+	isDB := &IScoreDB{
+		info:        &info,
+		management:  db.NewLayerDB(ctx.DB.management),
+		backupCount: ctx.DB.backupCount,
+	}
+
+	// CALCULATE toggles account DB unless it was toggled for blockHeight by ROLLBACK
+	readDBs := ctx.DB.GetCalcDBList()
+	if ctx.DB.info.ToggleBH == blockHeight+1 {
+		readDBs = ctx.DB.getQueryDBList()
+	}
+	for _, rDB := range readDBs {
+		isDB.Account0 = append(isDB.Account0, rDB)
+		isDB.Account1 = append(isDB.Account1, db.NewLayerDB(rDB))
+	}
+
+	dryCtx := ctx.snapshotState()
+	dryCtx.DB = isDB
+	dryCtx.CancelCalculation = ctx.CancelCalculation
+	dryCtx.calcDebug = &CalcDebug{conf: NewCalcDebugConfig()}
+	return dryCtx
+}
+
+// discard drops all changes of dry-run context
+func (idb *IScoreDB) discard() {
+	idb.management.(db.LayerDB).Flush(false)
+	for _, cDB := range idb.Account1 {
+		cDB.(db.LayerDB).Flush(false)
+	}
+}
+
+// DryRunCalculate calculates I-Score of all accounts with IISS data from the latest calculation
+// and returns the result without writing to DB. Changes are kept in memory and dropped after the calculation.
+func DryRunCalculate(ctx *Context, iissDataPath string) (*DryRunResult, error) {
+	startTime := time.Now()
+
+	iissDB := OpenIISSData(iissDataPath)
+	defer iissDB.Close()
+	header, gvList, prepList := LoadIISSData(iissDB)
+	if header == nil {
+		return nil, fmt.Errorf("failed to load IISS data (path: %s)", iissDataPath)
+	}
+
+	// copy context while no calculation changes account DBs
+	ctx.DB.viewLock.RLock()
+	if ctx.DB.isCalculating() {
+		ctx.DB.viewLock.RUnlock()
+		return nil, fmt.Errorf("calculating now. blockHeight: %d", ctx.DB.getCalculatingBH())
+	}
+	calcDoneBH := ctx.DB.getCalcDoneBH()
+	blockHeight := header.BlockHeight
+	if blockHeight == 0 {
+		blockHeight = calcDoneBH + 1
+	}
+	if blockHeight <= calcDoneBH {
+		ctx.DB.viewLock.RUnlock()
+		return nil, fmt.Errorf("invalid blockHeight(request: %d, RC blockHeight: %d)", blockHeight, calcDoneBH)
+	}
+	dryCtx := newDryRunContext(ctx, blockHeight)
+	quit := ctx.CancelCalculation.GetChannel()
+	ctx.DB.viewLock.RUnlock()
+	defer dryCtx.DB.discard()
+
+	log.Printf("Start dry-run calculation: block height: %d -> %d, IISS data path: %s",
+		calcDoneBH, blockHeight, iissDataPath)
+
+	// batch is not supported by layer DB
+	stats, stateHash, _ := calculateAccounts(quit, dryCtx, iissDB, header, gvList, prepList, blockHeight,
+		nil, 0, false)
+	if stats == nil {
+		return nil, fmt.Errorf("dry-run calculation of %d was canceled", blockHeight)
+	}
+
+	result := &DryRunResult{BlockHeight: blockHeight, Stats: *stats, StateHash: stateHash}
+	stateTree, err := buildStateTree(quit, blockHeight, dryCtx.DB.GetCalcDBList())
+	if err != nil {
+		return nil, err
+	}
+	result.StateRoot = stateTree.root()
+
+	if result.Deltas, err = dryCtx.DB.accountDeltas(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Finish dry-run calculation: Duration: %s, %s", time.Since(startTime), result.String())
+	return result, nil
+}
+
+// accountDeltas compares accounts in calculate DBs with accounts in query DBs
+func (idb *IScoreDB) accountDeltas() ([]*AccountDelta, error) {
+	deltas := make([]*AccountDelta, 0)
+	err := mergeAccountDBs(idb.GetCalcDBList(), func(key []byte, value []byte) error {
+		ia, err := NewIScoreAccountFromBytes(value)
+		if err != nil {
+			return err
+		}
+		delta := &AccountDelta{Address: *common.NewAddress(key[len(db.PrefixIScore):])}
+		delta.NewIScore.Set(&ia.IScore.Int)
+
+		bucket, _ := idb.getQueryDB(delta.Address).GetBucket(db.PrefixIScore)
+		if bs, _ := bucket.Get(delta.Address.Bytes()); bs != nil {
+			old, err := NewIScoreAccountFromBytes(bs)
+			if err != nil {
+				return err
+			}
+			delta.OldIScore.Set(&old.IScore.Int)
+		}
+
+		delta.Delta.Sub(&delta.NewIScore.Int, &delta.OldIScore.Int)
+		if delta.Delta.Sign() != 0 {
+			deltas = append(deltas, delta)
+		}
+		return nil
+	})
+	return deltas, err
+}
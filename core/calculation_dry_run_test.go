@@ -0,0 +1,110 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculationDryRun_DryRunCalculate(t *testing.T) {
+	const (
+		count                = 10
+		calcDoneBH    uint64 = 50
+		dryRunBH      uint64 = 100
+		accountIScore        = 100
+	)
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	gv := new(GovernanceVariable)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
+	gv.CalculatedIncentiveRep.SetUint64(1)
+	gv.RewardRep.SetUint64(testNetwork.MinRewardRep)
+	gv.setReward(testNetwork)
+	ctx.GV = append(ctx.GV, gv)
+
+	prep := new(PRepCandidate)
+	prep.Address = *common.NewAddressFromString("hxaa")
+	ctx.PRepCandidates[prep.Address] = prep
+
+	// write accounts of the latest calculation
+	ctx.DB.setCalcDoneBH(calcDoneBH)
+	accounts := makeTestAccounts(count)
+	for i, ia := range accounts {
+		ia.BlockHeight = calcDoneBH
+		ia.IScore.SetUint64(accountIScore)
+		ia.Delegations[0].Address = prep.Address
+		ia.Delegations[0].Delegate.SetUint64(testNetwork.MinDelegation() + uint64(i))
+	}
+	addresses := writeTestAccounts(accounts, ctx.DB.getCalculateDB)
+
+	iissDBDir := testDBDir + "/iiss"
+	header, iissDB := writeHeader(testDBDir, "iiss", dryRunBH)
+	iissDB.Close()
+	defer os.RemoveAll(iissDBDir)
+
+	result, err := DryRunCalculate(ctx, iissDBDir)
+	assert.NoError(t, err)
+	assert.Equal(t, dryRunBH, result.BlockHeight)
+	assert.Equal(t, count, len(result.Deltas))
+	for i, delta := range result.Deltas {
+		assert.Equal(t, addresses[i], delta.Address)
+		assert.Equal(t, uint64(accountIScore), delta.OldIScore.Uint64())
+		assert.True(t, delta.Delta.Sign() > 0)
+		assert.Equal(t, delta.NewIScore.Uint64()-delta.OldIScore.Uint64(), delta.Delta.Uint64())
+	}
+
+	// DB and context were not changed
+	assert.Equal(t, calcDoneBH, ctx.DB.getCalcDoneBH())
+	assert.False(t, ctx.DB.isCalculating())
+	assert.False(t, ctx.DB.hasCalcCheckpoint(dryRunBH, nil, header))
+	assert.Equal(t, 1, len(ctx.GV))
+	for _, address := range addresses {
+		bucket, _ := ctx.DB.getCalculateDB(address).GetBucket(db.PrefixIScore)
+		bs, _ := bucket.Get(address.Bytes())
+		ia, _ := NewIScoreAccountFromBytes(bs)
+		assert.Equal(t, calcDoneBH, ia.BlockHeight)
+		assert.Equal(t, uint64(accountIScore), ia.IScore.Uint64())
+	}
+
+	// calculation on DB has the same result
+	ctx.DB.toggleAccountDB(dryRunBH + 1)
+	assert.NoError(t, ctx.DB.resetAccountDB(dryRunBH))
+	iissDB = OpenIISSData(iissDBDir)
+	_, gvList, prepList := LoadIISSData(iissDB)
+	stats, stateHash, _ := calculateAccounts(ctx.CancelCalculation.GetChannel(), ctx, iissDB, header, gvList,
+		prepList, dryRunBH, testHash, writeBatchCount, false)
+	iissDB.Close()
+	assert.Equal(t, stats.String(), result.Stats.String())
+	assert.Equal(t, stateHash, result.StateHash)
+	tree, _ := buildStateTree(nil, dryRunBH, ctx.DB.GetCalcDBList())
+	assert.Equal(t, tree.root(), result.StateRoot)
+	for _, delta := range result.Deltas {
+		bucket, _ := ctx.DB.getCalculateDB(delta.Address).GetBucket(db.PrefixIScore)
+		bs, _ := bucket.Get(delta.Address.Bytes())
+		ia, _ := NewIScoreAccountFromBytes(bs)
+		assert.Equal(t, delta.NewIScore.Uint64(), ia.IScore.Uint64())
+	}
+	ctx.DB.setCalcDoneBH(dryRunBH)
+
+	// IISS data of calculated block height
+	_, err = DryRunCalculate(ctx, iissDBDir)
+	assert.Error(t, err)
+
+	// calculating
+	_, iissDB = writeHeader(testDBDir, "iiss", dryRunBH+50)
+	iissDB.Close()
+	ctx.DB.setCalculatingBH(dryRunBH+50, nil)
+	_, err = DryRunCalculate(ctx, iissDBDir)
+	assert.Error(t, err)
+	ctx.DB.resetCalculatingBH()
+
+	// no IISS data
+	_, err = DryRunCalculate(ctx, testDBDir+"/none")
+	assert.Error(t, err)
+	os.RemoveAll(testDBDir + "/none")
+}
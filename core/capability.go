@@ -0,0 +1,148 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+)
+
+// capabilities of IPC protocol which are negotiated with VERSION message.
+// Optional messages are capabilities with their names. ex) START_BLOCK
+const (
+	// StateRoot field of CALCULATE_DONE and QUERY_CALCULATE_RESULT
+	CapStateRoot = "STATE_ROOT"
+	// Beta1, Beta2, Beta3 and Signature fields of CALCULATE_DONE and QUERY_CALCULATE_RESULT
+	CapSignedResult = "SIGNED_RESULT"
+	// signed data of mutating messages
+	CapSignedMessage = "SIGNED_MESSAGE"
+)
+
+// optionalMessages are messages added after IPC version 1. They are handled if they are negotiated
+var optionalMessages = []uint{
+	MsgStartBlock,
+	MsgQueryRewardLedger,
+	MsgQueryBatch,
+	MsgQueryProof,
+	MsgQueryEstimate,
+	MsgQueryCalculateProgress,
+}
+
+// SupportedCapabilities returns capabilities of Reward Calculator in name order
+func SupportedCapabilities() []string {
+	caps := append([]string{CapSignedMessage}, fieldCapabilities...)
+	for _, msg := range optionalMessages {
+		caps = append(caps, MsgToString(msg))
+	}
+	sort.Strings(caps)
+	return caps
+}
+
+// messageCapability returns capability name of an optional message. empty string for others
+func messageCapability(msg uint) string {
+	for _, m := range optionalMessages {
+		if m == msg {
+			return MsgToString(msg)
+		}
+	}
+	return ""
+}
+
+// fieldCapabilities add fields to messages of IPC version 2
+var fieldCapabilities = []string{CapStateRoot, CapSignedResult}
+
+func isFieldCapability(name string) bool {
+	for _, c := range fieldCapabilities {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// capabilities negotiated with a peer. nil means a peer which did not negotiate.
+// It gets all messages with fields of IPC version 2
+type capabilities map[string]bool
+
+func (c capabilities) has(name string) bool {
+	if c == nil {
+		return !isFieldCapability(name)
+	}
+	return c[name]
+}
+
+func (c capabilities) names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// capabilityEncoder is a message whose fields depend on negotiated capabilities.
+// Fields are encoded in order, so a capability includes fields of capabilities before it.
+type capabilityEncoder interface {
+	encodeFor(caps capabilities) interface{}
+}
+
+// VersionRequest is data of VERSION message of a peer which negotiates version and capabilities.
+// VERSION without data is a request of a peer with IPCMinVersion, all messages and fields of IPC version 2.
+type VersionRequest struct {
+	Version      uint64 // the latest version of the peer
+	MinVersion   uint64 // the oldest version of the peer. same as Version if it is 0
+	Capabilities []string
+}
+
+// ResponseVersionNegotiation is the response of VERSION with VersionRequest.
+// Version is 0 if there is no common version or a required capability is missing, and
+// Reward Calculator closes the connection.
+type ResponseVersionNegotiation struct {
+	Version      uint64
+	BlockHeight  uint64
+	BlockHash    [BlockHashSize]byte
+	MinVersion   uint64
+	Capabilities []string
+	Nonce        []byte // nonce of signed messages of the connection with SIGNED_MESSAGE
+}
+
+func (rv *ResponseVersionNegotiation) String() string {
+	return fmt.Sprintf("Version: %d, BlockHeight: %d, MinVersion: %d, Capabilities: %v",
+		rv.Version, rv.BlockHeight, rv.MinVersion, rv.Capabilities)
+}
+
+// negotiate returns the highest common version and common capabilities with the peer.
+// required capabilities must be supported by the peer.
+func negotiate(req *VersionRequest, required []string) (uint64, capabilities, error) {
+	minVersion := req.MinVersion
+	if minVersion == 0 {
+		minVersion = req.Version
+	}
+	if minVersion < IPCMinVersion {
+		minVersion = IPCMinVersion
+	}
+	version := req.Version
+	if version > IPCVersion {
+		version = IPCVersion
+	}
+	if version < minVersion {
+		return 0, nil, fmt.Errorf("no common IPC version. peer: %d-%d, RC: %d-%d",
+			req.MinVersion, req.Version, IPCMinVersion, IPCVersion)
+	}
+
+	peer := make(map[string]bool, len(req.Capabilities))
+	for _, name := range req.Capabilities {
+		peer[name] = true
+	}
+	for _, name := range required {
+		if !peer[name] {
+			return 0, nil, fmt.Errorf("peer does not support required capability %s", name)
+		}
+	}
+
+	caps := make(capabilities)
+	for _, name := range SupportedCapabilities() {
+		if peer[name] {
+			caps[name] = true
+		}
+	}
+	return version, caps, nil
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	// highest common version and common capabilities
+	version, caps, err := negotiate(&VersionRequest{Version: IPCVersion + 1, MinVersion: IPCMinVersion,
+		Capabilities: []string{CapStateRoot, "QUERY_PROOF", "UNKNOWN"}}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, IPCVersion, version)
+	assert.Equal(t, []string{"QUERY_PROOF", CapStateRoot}, caps.names())
+	assert.True(t, caps.has(CapStateRoot))
+	assert.False(t, caps.has(CapSignedResult))
+
+	version, _, err = negotiate(&VersionRequest{Version: IPCMinVersion}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, IPCMinVersion, version)
+
+	// no common version
+	_, _, err = negotiate(&VersionRequest{Version: IPCVersion + 2, MinVersion: IPCVersion + 1}, nil)
+	assert.Error(t, err)
+	_, _, err = negotiate(&VersionRequest{Version: IPCMinVersion - 1}, nil)
+	assert.Error(t, err)
+
+	// required capability
+	_, _, err = negotiate(&VersionRequest{Version: IPCVersion}, []string{CapSignedMessage})
+	assert.Error(t, err)
+	_, caps, err = negotiate(&VersionRequest{Version: IPCVersion, Capabilities: []string{CapSignedMessage}},
+		[]string{CapSignedMessage})
+	assert.NoError(t, err)
+	assert.True(t, caps.has(CapSignedMessage))
+
+	// peer which did not negotiate has messages with fields of IPC version 2
+	assert.True(t, capabilities(nil).has("QUERY_PROOF"))
+	assert.False(t, capabilities(nil).has(CapStateRoot))
+	assert.False(t, capabilities(nil).has(CapSignedResult))
+}
+
+func TestCalculateDone_encodeFor(t *testing.T) {
+	type legacyCalculateDone struct {
+		Success     bool
+		BlockHeight uint64
+		IScore      common.HexInt
+		StateHash   []byte
+	}
+
+	cd := &CalculateDone{Success: true, BlockHeight: 100, StateHash: []byte{1}, StateRoot: []byte{2},
+		Signature: []byte{3}}
+	cd.IScore.SetUint64(1000)
+	cd.Beta1.SetUint64(10)
+
+	// all fields
+	all := capabilities{CapStateRoot: true, CapSignedResult: true}
+	assert.Equal(t, codec.MP.MustMarshalToBytes(cd), codec.MP.MustMarshalToBytes(cd.encodeFor(all)))
+
+	// fields of IPC version 2 for a peer which did not negotiate
+	assert.Equal(t, codec.MP.MustMarshalToBytes(cd.encodeFor(capabilities{})),
+		codec.MP.MustMarshalToBytes(cd.encodeFor(nil)))
+
+	// without StateRoot and signed result
+	var legacy legacyCalculateDone
+	bs := codec.MP.MustMarshalToBytes(cd.encodeFor(capabilities{}))
+	_, err := codec.MP.UnmarshalFromBytes(bs, &legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, cd.BlockHeight, legacy.BlockHeight)
+	assert.Equal(t, 0, cd.IScore.Cmp(&legacy.IScore.Int))
+	assert.Equal(t, cd.StateHash, legacy.StateHash)
+
+	// without signed result
+	var decoded CalculateDone
+	bs = codec.MP.MustMarshalToBytes(cd.encodeFor(capabilities{CapStateRoot: true}))
+	_, err = codec.MP.UnmarshalFromBytes(bs, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, cd.StateRoot, decoded.StateRoot)
+	assert.Nil(t, decoded.Signature)
+	assert.Equal(t, uint64(0), decoded.Beta1.Uint64())
+}
+
+func TestMsgHandler_version(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	m := newTestManager(ctx)
+
+	conn := new(testConn)
+	assert.NoError(t, m.OnConnect(conn))
+	mh := &msgHandler{mgr: m, conn: m.conns.conns[conn]}
+
+	// VERSION without negotiation
+	assert.NoError(t, mh.HandleMessage(conn, MsgVersion, 1, codec.MP.MustMarshalToBytes(nil)))
+	assert.Equal(t, IPCMinVersion, conn.data[1].(ResponseVersion).Version)
+	assert.Nil(t, mh.conn.capabilities())
+
+	// negotiate
+	req := VersionRequest{Version: IPCVersion, MinVersion: IPCMinVersion,
+		Capabilities: []string{MsgToString(MsgQueryCalculateProgress), CapStateRoot}}
+	assert.NoError(t, mh.HandleMessage(conn, MsgVersion, 2, codec.MP.MustMarshalToBytes(&req)))
+	resp := conn.data[2].(*ResponseVersionNegotiation)
+	assert.Equal(t, IPCVersion, resp.Version)
+	assert.Equal(t, []string{"QUERY_CALCULATE_PROGRESS", CapStateRoot}, resp.Capabilities)
+
+	// message which is not negotiated
+	assert.Error(t, mh.HandleMessage(conn, MsgQueryBatch, 3, nil))
+	assert.NoError(t, mh.HandleMessage(conn, MsgQueryCalculateProgress, 4,
+		codec.MP.MustMarshalToBytes(&QueryCalculateProgress{})))
+	assert.True(t, waitMsgTasks(m))
+	assert.Equal(t, 4, conn.count())
+
+	// fields which are not negotiated are omitted
+	assert.NoError(t, mh.conn.Send(MsgCalculateDone, 0, &CalculateDone{}))
+	assert.Equal(t, 5, len(conn.data[4].([]interface{})))
+
+	// refuse peer which doesn't sign messages
+	_, pub := crypto.GenerateKeyPair()
+	m.auth = &msgAuth{keys: []*crypto.PublicKey{pub}}
+	conn2 := new(testConn)
+	assert.NoError(t, m.OnConnect(conn2))
+	mh2 := &msgHandler{mgr: m, conn: m.conns.conns[conn2]}
+	assert.Error(t, mh2.HandleMessage(conn2, MsgVersion, 1, codec.MP.MustMarshalToBytes(&req)))
+	resp = conn2.data[1].(*ResponseVersionNegotiation)
+	assert.Equal(t, uint64(0), resp.Version)
+	assert.Equal(t, SupportedCapabilities(), resp.Capabilities)
+}
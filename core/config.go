@@ -0,0 +1,256 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+const (
+	ConfigEnvPrefix = "ICON_RC_"
+)
+
+type RcConfig struct {
+	IISSDataDir   string `json:"IISSData"`
+	DBDir         string `json:"IScoreDB"`
+	IpcNet        string `json:"IPCNet"`
+	IpcAddr       string `json:"IPCAddress"`
+	ClientMode    bool   `json:"ClientMode"`
+	DBCount       int    `json:"DBCount"`
+	DBType        string `json:"DBType"`
+	Monitor       bool   `json:"Monitor"`
+	LogFile       string `json:"LogFile"`
+	LogMaxSize    int    `json:"LogMaxSize"`
+	LogMaxBackups int    `json:"LogMaxBackups"`
+	CalcDebugConf string `json:"CalcDebugConf"`
+	MetricsAddr   string `json:"MetricsAddress"`
+	RewardLedger  bool   `json:"RewardLedger"`
+	BackupCount   int    `json:"BackupCount"`
+	Network       string `json:"Network"`
+	FileName      string `json:"-"`
+
+	// TLS of tcp IPC channel. Enabled with certificate
+	IpcTLSCert       string `json:"IPCTLSCert"`
+	IpcTLSKey        string `json:"IPCTLSKey"`
+	IpcTLSCA         string `json:"IPCTLSCA"`
+	IpcTLSServerName string `json:"IPCTLSServerName"`
+
+	// comma separated public keys of ICON Service in hex. Mutating messages must be signed if it is set
+	IpcAuthKeys string `json:"IPCAuthKeys"`
+
+	// file of private key in hex to sign calculation results. Results are not signed if it is empty
+	NodeKey string `json:"NodeKey"`
+
+	// profile of network which is not built-in
+	NetworkProfile *NetworkProfile `json:"NetworkProfile,omitempty"`
+}
+
+func (cfg *RcConfig) Print() {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		log.Printf("Can't covert configuration to json")
+		return
+	}
+
+	log.Printf("Running config %s\n", string(b))
+}
+
+// Load overwrites the configuration with the values in JSON file.
+// Fields which are not in the file keep their values.
+func (cfg *RcConfig) Load(fileName string) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bs, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(bs, cfg); err != nil {
+		return fmt.Errorf("invalid configuration file %s. %v", fileName, err)
+	}
+	return nil
+}
+
+// LoadEnv overwrites the configuration with environment variables.
+// The name of variable is ConfigEnvPrefix + upper case of JSON field name. ex) ICON_RC_DBCOUNT
+func (cfg *RcConfig) LoadEnv() error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("json")
+		if len(tag) == 0 || tag == "-" {
+			continue
+		}
+		name := ConfigEnvPrefix + strings.ToUpper(tag)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid environment variable %s=%s. %v", name, value, err)
+			}
+			field.SetBool(b)
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid environment variable %s=%s. %v", name, value, err)
+			}
+			field.SetInt(int64(n))
+		}
+	}
+	return nil
+}
+
+func (cfg *RcConfig) Validate() error {
+	if len(cfg.IISSDataDir) == 0 {
+		return fmt.Errorf("IISSData is empty")
+	}
+	if len(cfg.DBDir) == 0 {
+		return fmt.Errorf("IScoreDB is empty")
+	}
+	switch cfg.IpcNet {
+	case "unix", "tcp", "tcp4", "tcp6":
+	default:
+		return fmt.Errorf("invalid IPCNet %s", cfg.IpcNet)
+	}
+	if len(cfg.IpcAddr) == 0 {
+		return fmt.Errorf("IPCAddress is empty")
+	}
+	if len(cfg.IpcTLSCert) > 0 || len(cfg.IpcTLSKey) > 0 || len(cfg.IpcTLSCA) > 0 {
+		if !ipc.IsTCP(cfg.IpcNet) {
+			return fmt.Errorf("TLS is not supported for IPCNet %s", cfg.IpcNet)
+		}
+		if len(cfg.IpcTLSCert) == 0 || len(cfg.IpcTLSKey) == 0 || len(cfg.IpcTLSCA) == 0 {
+			return fmt.Errorf("IPCTLSCert, IPCTLSKey and IPCTLSCA are needed for TLS")
+		}
+	}
+	if _, err := ParseAuthKeys(cfg.IpcAuthKeys); err != nil {
+		return fmt.Errorf("invalid IPCAuthKeys. %v", err)
+	}
+	if cfg.DBCount <= 0 || cfg.DBCount > MaxDBCount {
+		return fmt.Errorf("invalid DBCount %d. MIN: 1, MAX: %d", cfg.DBCount, MaxDBCount)
+	}
+	if !db.HasBackend(cfg.DBType) {
+		return fmt.Errorf("invalid DBType %s. %v", cfg.DBType, db.GetBackendList())
+	}
+	if db.IsTestBackend(cfg.DBType) {
+		return fmt.Errorf("DBType %s is for test only", cfg.DBType)
+	}
+	if len(cfg.LogFile) == 0 {
+		return fmt.Errorf("LogFile is empty")
+	}
+	if cfg.BackupCount < 1 {
+		return fmt.Errorf("invalid BackupCount %d. MIN: 1", cfg.BackupCount)
+	}
+	if cfg.LogMaxSize < 0 {
+		return fmt.Errorf("invalid LogMaxSize %d", cfg.LogMaxSize)
+	}
+	if cfg.LogMaxBackups < 0 {
+		return fmt.Errorf("invalid LogMaxBackups %d", cfg.LogMaxBackups)
+	}
+	if _, err := cfg.GetNetworkProfile(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetNetworkProfile returns the built-in profile of Network or NetworkProfile for other network
+func (cfg *RcConfig) GetNetworkProfile() (*NetworkProfile, error) {
+	if np, err := GetNetworkProfile(cfg.Network); err == nil {
+		if cfg.NetworkProfile != nil {
+			return nil, fmt.Errorf("NetworkProfile can't be set for built-in network %s", cfg.Network)
+		}
+		return np, nil
+	}
+	if cfg.NetworkProfile == nil {
+		return nil, fmt.Errorf("invalid Network %s. Set NetworkProfile for network which is not in %v",
+			cfg.Network, GetNetworkList())
+	}
+
+	np := *cfg.NetworkProfile
+	np.Name = cfg.Network
+	if err := np.Validate(); err != nil {
+		return nil, err
+	}
+	return &np, nil
+}
+
+// IPCTLSConfig returns TLS configuration of IPC channel. nil if TLS is disabled
+func (cfg *RcConfig) IPCTLSConfig() *ipc.TLSConfig {
+	if len(cfg.IpcTLSCert) == 0 {
+		return nil
+	}
+	return &ipc.TLSConfig{
+		CertFile:   cfg.IpcTLSCert,
+		KeyFile:    cfg.IpcTLSKey,
+		CAFile:     cfg.IpcTLSCA,
+		ServerName: cfg.IpcTLSServerName,
+	}
+}
+
+// staticChanged returns names of changed fields which need restart to apply
+func (cfg *RcConfig) staticChanged(newCfg *RcConfig) []string {
+	changed := make([]string, 0)
+	if cfg.IISSDataDir != newCfg.IISSDataDir {
+		changed = append(changed, "IISSData")
+	}
+	if cfg.DBDir != newCfg.DBDir {
+		changed = append(changed, "IScoreDB")
+	}
+	if cfg.IpcNet != newCfg.IpcNet {
+		changed = append(changed, "IPCNet")
+	}
+	if cfg.IpcAddr != newCfg.IpcAddr {
+		changed = append(changed, "IPCAddress")
+	}
+	if cfg.ClientMode != newCfg.ClientMode {
+		changed = append(changed, "ClientMode")
+	}
+	if !reflect.DeepEqual(cfg.IPCTLSConfig(), newCfg.IPCTLSConfig()) {
+		changed = append(changed, "IPCTLS")
+	}
+	if cfg.IpcAuthKeys != newCfg.IpcAuthKeys {
+		changed = append(changed, "IPCAuthKeys")
+	}
+	if cfg.NodeKey != newCfg.NodeKey {
+		changed = append(changed, "NodeKey")
+	}
+	if cfg.DBCount != newCfg.DBCount {
+		changed = append(changed, "DBCount")
+	}
+	if cfg.DBType != newCfg.DBType {
+		changed = append(changed, "DBType")
+	}
+	if cfg.MetricsAddr != newCfg.MetricsAddr {
+		changed = append(changed, "MetricsAddress")
+	}
+	if cfg.RewardLedger != newCfg.RewardLedger {
+		changed = append(changed, "RewardLedger")
+	}
+	if cfg.BackupCount != newCfg.BackupCount {
+		changed = append(changed, "BackupCount")
+	}
+	if cfg.Network != newCfg.Network || !reflect.DeepEqual(cfg.NetworkProfile, newCfg.NetworkProfile) {
+		changed = append(changed, "Network")
+	}
+	return changed
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConfig() *RcConfig {
+	return &RcConfig{
+		IISSDataDir:   "./iissdata",
+		DBDir:         ".iscoredb",
+		IpcNet:        "unix",
+		IpcAddr:       "/tmp/icon-rc.sock",
+		DBCount:       2,
+		DBType:        "goleveldb",
+		BackupCount:   1,
+		Network:       NetworkMainnet,
+		LogFile:       "icon_rc.log",
+		LogMaxSize:    10,
+		LogMaxBackups: 10,
+		CalcDebugConf: "./calculation_debug.json",
+	}
+}
+
+func TestRcConfig_Load(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rc_config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, "rc_config.json")
+	err = ioutil.WriteFile(fileName, []byte(`{"IScoreDB": "/data/iscore", "DBCount": 16, "Monitor": true}`), 0644)
+	assert.NoError(t, err)
+
+	cfg := newTestConfig()
+	assert.NoError(t, cfg.Load(fileName))
+	assert.Equal(t, "/data/iscore", cfg.DBDir)
+	assert.Equal(t, 16, cfg.DBCount)
+	assert.True(t, cfg.Monitor)
+	// keep values not in file
+	assert.Equal(t, "./iissdata", cfg.IISSDataDir)
+	assert.Equal(t, "unix", cfg.IpcNet)
+
+	// no file
+	assert.True(t, os.IsNotExist(cfg.Load(filepath.Join(dir, "none.json"))))
+
+	// invalid file
+	err = ioutil.WriteFile(fileName, []byte(`{"DBCount": "many"}`), 0644)
+	assert.NoError(t, err)
+	assert.Error(t, cfg.Load(fileName))
+}
+
+func TestRcConfig_LoadEnv(t *testing.T) {
+	cfg := newTestConfig()
+
+	os.Setenv(ConfigEnvPrefix+"ISCOREDB", "/data/iscore")
+	os.Setenv(ConfigEnvPrefix+"DBCOUNT", "16")
+	os.Setenv(ConfigEnvPrefix+"CLIENTMODE", "true")
+	defer os.Unsetenv(ConfigEnvPrefix + "ISCOREDB")
+	defer os.Unsetenv(ConfigEnvPrefix + "DBCOUNT")
+	defer os.Unsetenv(ConfigEnvPrefix + "CLIENTMODE")
+
+	assert.NoError(t, cfg.LoadEnv())
+	assert.Equal(t, "/data/iscore", cfg.DBDir)
+	assert.Equal(t, 16, cfg.DBCount)
+	assert.True(t, cfg.ClientMode)
+	assert.Equal(t, "./iissdata", cfg.IISSDataDir)
+
+	// invalid value
+	os.Setenv(ConfigEnvPrefix+"DBCOUNT", "many")
+	assert.Error(t, cfg.LoadEnv())
+}
+
+func TestRcConfig_Validate(t *testing.T) {
+	cfg := newTestConfig()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.DBCount = MaxDBCount + 1
+	assert.Error(t, cfg.Validate())
+	cfg.DBCount = 0
+	assert.Error(t, cfg.Validate())
+	cfg.DBCount = MaxDBCount
+	assert.NoError(t, cfg.Validate())
+
+	cfg.DBType = "unknown"
+	assert.Error(t, cfg.Validate())
+	cfg.DBType = "mapdb"
+	assert.Error(t, cfg.Validate())
+	cfg.DBType = "badgerdb"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.BackupCount = 0
+	assert.Error(t, cfg.Validate())
+	cfg.BackupCount = 3
+	assert.NoError(t, cfg.Validate())
+
+	cfg.IpcNet = "udp"
+	assert.Error(t, cfg.Validate())
+	cfg.IpcNet = "tcp"
+	assert.NoError(t, cfg.Validate())
+
+	// TLS needs certificate, key and CA of tcp
+	cfg.IpcTLSCert = "rc.crt"
+	assert.Error(t, cfg.Validate())
+	cfg.IpcTLSKey = "rc.key"
+	cfg.IpcTLSCA = "ca.crt"
+	assert.NoError(t, cfg.Validate())
+	cfg.IpcNet = "unix"
+	assert.Error(t, cfg.Validate())
+	cfg.IpcNet = "tcp"
+
+	cfg.IpcAuthKeys = "0x1234"
+	assert.Error(t, cfg.Validate())
+	cfg.IpcAuthKeys = ""
+
+	cfg.LogMaxSize = -1
+	assert.Error(t, cfg.Validate())
+	cfg.LogMaxSize = 10
+
+	cfg.IISSDataDir = ""
+	assert.Error(t, cfg.Validate())
+}
+
+func TestRcConfig_GetNetworkProfile(t *testing.T) {
+	cfg := newTestConfig()
+	np, err := cfg.GetNetworkProfile()
+	assert.NoError(t, err)
+	assert.Equal(t, mainnetProfile, *np)
+
+	// built-in network with profile
+	private := mainnetProfile
+	cfg.NetworkProfile = &private
+	_, err = cfg.GetNetworkProfile()
+	assert.Error(t, err)
+
+	// network which is not built-in
+	cfg.Network = "private"
+	np, err = cfg.GetNetworkProfile()
+	assert.NoError(t, err)
+	assert.Equal(t, "private", np.Name)
+	assert.Equal(t, mainnetProfile.BlocksPerYear, np.BlocksPerYear)
+
+	private.IScoreMultiplier = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg.NetworkProfile = nil
+	assert.Error(t, cfg.Validate())
+}
+
+func TestRcConfig_staticChanged(t *testing.T) {
+	cfg := newTestConfig()
+	newCfg := newTestConfig()
+	assert.Equal(t, 0, len(cfg.staticChanged(newCfg)))
+
+	// reloadable values
+	newCfg.LogFile = "new.log"
+	newCfg.Monitor = true
+	newCfg.CalcDebugConf = "new.json"
+	assert.Equal(t, 0, len(cfg.staticChanged(newCfg)))
+
+	newCfg.DBCount = 4
+	newCfg.IpcAddr = "/tmp/new.sock"
+	assert.Equal(t, []string{"IPCAddress", "DBCount"}, cfg.staticChanged(newCfg))
+
+	newCfg.Network = "private"
+	assert.Equal(t, []string{"IPCAddress", "DBCount", "Network"}, cfg.staticChanged(newCfg))
+
+	newCfg.IpcTLSCert = "rc.crt"
+	assert.Equal(t, []string{"IPCAddress", "IPCTLS", "DBCount", "Network"}, cfg.staticChanged(newCfg))
+}
+
+func TestReloadCalcDebugConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "calc_debug")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "calculation_debug.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"enable": true, "addresses": ["hx11"]}`), 0644))
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	assert.NoError(t, ReloadCalcDebugConfig(ctx, path))
+	assert.True(t, ctx.calcDebug.conf.Flag)
+
+	// can't reload after calculation started
+	startCalculation(ctx, 100, nil)
+	assert.Error(t, ReloadCalcDebugConfig(ctx, filepath.Join(dir, "none.json")))
+	assert.True(t, ctx.calcDebug.conf.Flag)
+
+	ctx.DB.resetCalculatingBH()
+	assert.NoError(t, ReloadCalcDebugConfig(ctx, filepath.Join(dir, "none.json")))
+	assert.False(t, ctx.calcDebug.conf.Flag)
+}
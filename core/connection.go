@@ -0,0 +1,208 @@
+package core
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+var errConnectionClosed = errors.New("connection closed")
+
+const connNonceSize = 16
+
+// trackedConn is an IPC connection registered to manager.
+// It counts requests in flight and drops their replies after the connection is closed.
+type trackedConn struct {
+	ipc.Connection
+	id        uint64
+	monitor   bool
+	connected time.Time
+
+	lock     sync.Mutex
+	closed   bool
+	handled  uint64
+	inFlight map[uint]int // the number of requests in flight of each message
+
+	nonce        []byte       // random bytes signed messages of the connection must have
+	lastSignedID uint32       // id of the last signed message
+	caps         capabilities // negotiated capabilities. nil if the peer did not negotiate
+}
+
+func (tc *trackedConn) Send(msg uint, id uint32, data interface{}) error {
+	if tc.isClosed() {
+		log.Printf("Drop message to closed connection %d. (msg:%s, id:%d)", tc.id, MsgToString(msg), id)
+		return errConnectionClosed
+	}
+	if ce, ok := data.(capabilityEncoder); ok {
+		data = ce.encodeFor(tc.capabilities())
+	}
+	return tc.Connection.Send(msg, id, data)
+}
+
+func (tc *trackedConn) SendAndReceive(msg uint, id uint32, data interface{}, buf interface{}) error {
+	if tc.isClosed() {
+		return errConnectionClosed
+	}
+	return tc.Connection.SendAndReceive(msg, id, data, buf)
+}
+
+func (tc *trackedConn) isClosed() bool {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	return tc.closed
+}
+
+// checkSignedID rejects a signed message whose id is not greater than the last one to prevent replay
+func (tc *trackedConn) checkSignedID(id uint32) error {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	if id <= tc.lastSignedID {
+		return fmt.Errorf("replayed message id %d <= %d", id, tc.lastSignedID)
+	}
+	tc.lastSignedID = id
+	return nil
+}
+
+func (tc *trackedConn) setCapabilities(caps capabilities) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	tc.caps = caps
+}
+
+func (tc *trackedConn) capabilities() capabilities {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	return tc.caps
+}
+
+func (tc *trackedConn) hasCapability(name string) bool {
+	return tc.capabilities().has(name)
+}
+
+func (tc *trackedConn) begin(msg uint) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	tc.inFlight[msg]++
+}
+
+func (tc *trackedConn) end(msg uint) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	tc.handled++
+	if tc.inFlight[msg]--; tc.inFlight[msg] <= 0 {
+		delete(tc.inFlight, msg)
+	}
+}
+
+// ConnectionInfo is the status of a live IPC connection
+type ConnectionInfo struct {
+	ID          uint64
+	Monitor     bool   // connection of monitoring channel
+	ConnectedAt uint64 // unix time in seconds
+	Handled     uint64 // the number of handled requests
+	InFlight    []string
+}
+
+func (ci *ConnectionInfo) String() string {
+	return fmt.Sprintf("ID: %d, Monitor: %t, ConnectedAt: %s, Handled: %d, InFlight: %v",
+		ci.ID, ci.Monitor, time.Unix(int64(ci.ConnectedAt), 0).String(), ci.Handled, ci.InFlight)
+}
+
+func (tc *trackedConn) info() ConnectionInfo {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+
+	info := ConnectionInfo{
+		ID:          tc.id,
+		Monitor:     tc.monitor,
+		ConnectedAt: uint64(tc.connected.Unix()),
+		Handled:     tc.handled,
+		InFlight:    make([]string, 0),
+	}
+	for msg, count := range tc.inFlight {
+		for i := 0; i < count; i++ {
+			info.InFlight = append(info.InFlight, MsgToString(msg))
+		}
+	}
+	sort.Strings(info.InFlight)
+	return info
+}
+
+// connRegistry has live IPC connections of IPC server and monitoring channel
+type connRegistry struct {
+	lock   sync.Mutex
+	lastID uint64
+	conns  map[ipc.Connection]*trackedConn
+}
+
+func newConnRegistry() *connRegistry {
+	return &connRegistry{conns: make(map[ipc.Connection]*trackedConn)}
+}
+
+func (r *connRegistry) add(c ipc.Connection, monitor bool) *trackedConn {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.lastID++
+	nonce := make([]byte, connNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		log.Printf("Failed to make nonce of connection %d. %v", r.lastID, err)
+	}
+	tc := &trackedConn{
+		Connection: c,
+		id:         r.lastID,
+		monitor:    monitor,
+		connected:  time.Now(),
+		inFlight:   make(map[uint]int),
+		nonce:      nonce,
+	}
+	r.conns[c] = tc
+	return tc
+}
+
+func (r *connRegistry) get(c ipc.Connection) *trackedConn {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.conns[c]
+}
+
+// remove closes the connection and returns it. Replies of requests in flight are dropped
+func (r *connRegistry) remove(c ipc.Connection) *trackedConn {
+	r.lock.Lock()
+	tc, ok := r.conns[c]
+	delete(r.conns, c)
+	r.lock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	tc.lock.Lock()
+	tc.closed = true
+	tc.lock.Unlock()
+	return tc
+}
+
+// list returns live connections in ID order
+func (r *connRegistry) list() []ConnectionInfo {
+	r.lock.Lock()
+	conns := make([]*trackedConn, 0, len(r.conns))
+	for _, tc := range r.conns {
+		conns = append(conns, tc)
+	}
+	r.lock.Unlock()
+
+	infos := make([]ConnectionInfo, len(conns))
+	for i, tc := range conns {
+		infos[i] = tc.info()
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/ipc"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestManager(ctx *Context) *manager {
+	return &manager{ctx: ctx, waitGroup: new(sync.WaitGroup), conns: newConnRegistry()}
+}
+
+// waitMsgTasks returns false if message tasks are not done in a second
+func waitMsgTasks(m *manager) bool {
+	done := make(chan struct{})
+	go func() {
+		m.WaitMsgTasksDone()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestConnection_lifecycle(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	m := newTestManager(ctx)
+
+	conn0 := new(testConn)
+	conn1 := new(testConn)
+	assert.NoError(t, m.OnConnect(conn0))
+	assert.NoError(t, m.OnConnect(conn1))
+	assert.Equal(t, uint(MsgReady), conn0.msgs[0])
+
+	list := m.conns.list()
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, uint64(1), list[0].ID)
+	assert.Equal(t, uint64(2), list[1].ID)
+
+	// request in flight
+	tc := m.conns.conns[conn0]
+	mh := &msgHandler{mgr: m, conn: tc}
+	ctx.progress.subscribe(tc, time.Second)
+	release := make(chan struct{})
+	mh.beginTask(MsgQueryProof)
+	go mh.instrument(MsgQueryProof, func(c ipc.Connection, id uint32, data []byte) error {
+		<-release
+		return c.Send(MsgQueryProof, id, nil)
+	}, 1, nil)
+	assert.Equal(t, []string{"QUERY_PROOF"}, m.conns.list()[0].InFlight)
+
+	// reply is dropped after the connection is closed
+	assert.NoError(t, m.OnClose(conn0))
+	close(release)
+	assert.True(t, waitMsgTasks(m))
+	assert.Equal(t, 1, conn0.count())
+	assert.Equal(t, 0, len(ctx.progress.subscribers))
+
+	list = m.conns.list()
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, uint64(2), list[0].ID)
+	assert.NoError(t, m.OnClose(conn0))
+}
+
+func TestConnection_decodeError(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	m := newTestManager(ctx)
+
+	conn := new(testConn)
+	assert.NoError(t, m.OnConnect(conn))
+	mh := &msgHandler{mgr: m, conn: m.conns.conns[conn]}
+
+	// message tasks are done with invalid data. do not use QUERY which is checked by metrics test
+	invalid := []byte{0xc1}
+	for _, msg := range []uint{MsgClaim, MsgQueryEstimate, MsgCalculate, MsgCommitClaim, MsgQueryCalculateResult} {
+		assert.NoError(t, mh.HandleMessage(conn, msg, 1, invalid))
+	}
+	assert.Error(t, mh.HandleMessage(conn, MsgRollBack, 1, invalid))
+	assert.True(t, waitMsgTasks(m))
+
+	info := m.conns.list()[0]
+	assert.Equal(t, uint64(6), info.Handled)
+	assert.Equal(t, 0, len(info.InFlight))
+}
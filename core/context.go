@@ -5,10 +5,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"sync"
 
 	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/crypto"
 	"github.com/icon-project/rewardcalculator/common/db"
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
@@ -35,9 +37,23 @@ type IScoreDB struct {
 	claim       db.Database
 	claimBackup db.Database
 
+	// optional. nil if reward ledger is disabled
+	rewardLedger db.Database
+
+	// QUERY and CLAIM hold read lock to read claim DB, preCommit DB and query account DB at the same point.
+	// Operations which change claim DB or switch query account DB hold write lock.
+	viewLock sync.RWMutex
+
+	// Readers of account DB snapshots hold read lock. Operations which close account DBs hold write lock.
+	// Lock order is viewLock, closeLock and accountLock
+	closeLock sync.RWMutex
+
 	accountLock sync.RWMutex
 	Account0    []db.Database
 	Account1    []db.Database
+
+	// the number of calculations to keep backup account DB
+	backupCount int
 }
 
 func (idb *IScoreDB) getQueryDBList() []db.Database {
@@ -64,18 +80,50 @@ func (idb *IScoreDB) _getCalcDBList() []db.Database {
 	}
 }
 
-func (idb *IScoreDB) toggleAccountDB(blockHeight uint64) {
+// getCalcDBSet returns the set of calculate DB. Set is the postfix of account DB name
+func (idb *IScoreDB) getCalcDBSet() int {
+	idb.accountLock.RLock()
+	defer idb.accountLock.RUnlock()
+	if idb.info.QueryDBIsZero {
+		return 1
+	} else {
+		return 0
+	}
+}
+
+func (idb *IScoreDB) getAccountDBSet(set int) []db.Database {
+	idb.accountLock.RLock()
+	defer idb.accountLock.RUnlock()
+	if set == 0 {
+		return idb.Account0
+	} else {
+		return idb.Account1
+	}
+}
+
+// toggleAccountDB switches query DB and calculate DB for the calculation of blockHeight - 1.
+// Toggle is written with journal of backup, so old query DB is backed up after crash.
+func (idb *IScoreDB) toggleAccountDB(blockHeight uint64) error {
 	if idb.info.ToggleBH == blockHeight {
-		return
+		return nil
 	}
 
+	idb.viewLock.Lock()
+	defer idb.viewLock.Unlock()
 	idb.accountLock.Lock()
+	defer idb.accountLock.Unlock()
+
+	toggleBH := idb.info.ToggleBH
 	idb.info.QueryDBIsZero = !idb.info.QueryDBIsZero
 	idb.info.ToggleBH = blockHeight
-	idb.accountLock.Unlock()
 
 	// write to DB
-	idb.writeToDB()
+	if err := idb.writeToDBWithJournal(newJournal(JournalBackup, blockHeight-1)); err != nil {
+		idb.info.QueryDBIsZero = !idb.info.QueryDBIsZero
+		idb.info.ToggleBH = toggleBH
+		return err
+	}
+	return nil
 }
 
 func (idb *IScoreDB) getAccountDBIndex(address common.Address) int {
@@ -97,6 +145,8 @@ func (idb *IScoreDB) OpenAccountDB() {
 }
 
 func (idb *IScoreDB) CloseAccountDB() {
+	idb.closeLock.Lock()
+	defer idb.closeLock.Unlock()
 	for _, aDB := range idb.Account0 {
 		aDB.Close()
 	}
@@ -133,10 +183,31 @@ func (idb *IScoreDB) getCalculateResultDB() db.Database {
 	return idb.calcResult
 }
 
-func (idb *IScoreDB) resetAccountDB(blockHeight uint64, oldCalcBH uint64) error {
+func (idb *IScoreDB) getRewardLedgerDB() db.Database {
+	return idb.rewardLedger
+}
+
+// OpenRewardLedgerDB opens reward ledger DB which keeps Beta1, Beta2 and Beta3 of each account for each term
+func (idb *IScoreDB) OpenRewardLedgerDB() {
+	if idb.rewardLedger == nil {
+		idb.rewardLedger = db.Open(idb.info.DBRoot, idb.info.DBType, RewardLedgerDBName)
+	}
+}
+
+func (idb *IScoreDB) SetBackupCount(count int) {
+	idb.backupCount = count
+}
+
+func (idb *IScoreDB) resetAccountDB(blockHeight uint64) error {
+	idb.closeLock.Lock()
+	defer idb.closeLock.Unlock()
 	idb.accountLock.Lock()
 	defer idb.accountLock.Unlock()
 
+	if err := idb.writeJournal(newJournal(JournalBackup, blockHeight)); err != nil {
+		return err
+	}
+
 	// account DB was toggled, so calculate DB points old query DB
 	oldQueryDBs := idb._getCalcDBList()
 	var oldQueryDBPostFix = 0
@@ -144,22 +215,6 @@ func (idb *IScoreDB) resetAccountDB(blockHeight uint64, oldCalcBH uint64) error
 		oldQueryDBPostFix = 1
 	}
 
-	// delete old backup account DB
-	oldBackup := filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+strconv.FormatUint(oldCalcBH, 10)+"_*")
-	oldBackups, err := filepath.Glob(oldBackup)
-	if err != nil {
-		log.Printf("Failed to get old backup account DB %s. %v", oldBackup, err)
-		return err
-	}
-	log.Printf("delete old backup %d account DBs. %s", len(oldBackups), oldBackup)
-	for _, f := range oldBackups {
-		err = os.RemoveAll(f)
-		if err != nil {
-			log.Printf("Failed to delete old backup account DB %s. %v", f, err)
-			return err
-		}
-	}
-
 	newCalcDBs := make([]db.Database, len(oldQueryDBs))
 	backupCount := 0
 	for i, oldQueryDB := range oldQueryDBs {
@@ -182,8 +237,7 @@ func (idb *IScoreDB) resetAccountDB(blockHeight uint64, oldCalcBH uint64) error
 		// open new calculate DB
 		newCalcDBs[i] = db.Open(idb.info.DBRoot, idb.info.DBType, dbName)
 	}
-	backup := filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+strconv.FormatUint(blockHeight, 10)+"_*")
-	log.Printf("backup %d account DBs. %s", backupCount, backup)
+	log.Printf("backup %d account DBs. %s", backupCount, idb.backupAccountDBPattern(blockHeight))
 
 	// set new calculate DB
 	if idb.info.QueryDBIsZero {
@@ -192,17 +246,126 @@ func (idb *IScoreDB) resetAccountDB(blockHeight uint64, oldCalcBH uint64) error
 		idb.Account0 = newCalcDBs
 	}
 
+	return idb.deleteJournal()
+}
+
+func (idb *IScoreDB) backupAccountDBPattern(blockHeight uint64) string {
+	return filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+strconv.FormatUint(blockHeight, 10)+"_*")
+}
+
+func (idb *IScoreDB) hasBackupAccountDB(blockHeight uint64) bool {
+	backups, err := filepath.Glob(idb.backupAccountDBPattern(blockHeight))
+	return err == nil && len(backups) > 0
+}
+
+// getBackupAccountDBList returns calculation block heights of backup account DBs in descending order
+func (idb *IScoreDB) getBackupAccountDBList() ([]uint64, error) {
+	backups, err := filepath.Glob(filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	bhMap := make(map[uint64]bool)
+	for _, f := range backups {
+		var backupBH uint64
+		var index int
+		_, backupName := filepath.Split(f)
+		if n, _ := fmt.Sscanf(backupName, BackupDBNameFormat, &backupBH, &index); n == 2 {
+			bhMap[backupBH] = true
+		}
+	}
+
+	bhList := make([]uint64, 0, len(bhMap))
+	for bh := range bhMap {
+		bhList = append(bhList, bh)
+	}
+	sort.Slice(bhList, func(i, j int) bool { return bhList[i] > bhList[j] })
+
+	return bhList, nil
+}
+
+// deleteOldBackupAccountDB keeps backup account DB of the latest backupCount calculations and deletes others.
+// Calculation history which is not needed for rollback is deleted too.
+func (idb *IScoreDB) deleteOldBackupAccountDB() error {
+	bhList, err := idb.getBackupAccountDBList()
+	if err != nil {
+		log.Printf("Failed to get backup account DB list. %v", err)
+		return err
+	}
+
+	oldest := idb.getCalcDoneBH()
+	for i, bh := range bhList {
+		if i < idb.backupCount {
+			oldest = bh
+			continue
+		}
+
+		if err = idb.deleteBackupAccountDB(bh); err != nil {
+			return err
+		}
+	}
+
+	idb.deleteOldCalcHistory(oldest)
+
 	return nil
 }
 
-func (idb *IScoreDB) setCalculatingBH(blockHeight uint64) {
+// restoreBackupAccountDB replaces calculate DB with backup account DB of the calculation.
+// Account DB must be closed. It can be called again if it was stopped.
+func (idb *IScoreDB) restoreBackupAccountDB(blockHeight uint64) error {
+	var calcDBPostFix = 0
+	if idb.info.QueryDBIsZero {
+		calcDBPostFix = 1
+	}
+
+	backups, err := filepath.Glob(idb.backupAccountDBPattern(blockHeight))
+	if err != nil {
+		log.Printf("Failed to get backup account DB")
+		return err
+	}
+
+	rollbackCount := 0
+	for _, f := range backups {
+		var backupBH uint64
+		var index int
+		_, backupName := filepath.Split(f)
+		fmt.Sscanf(backupName, BackupDBNameFormat, &backupBH, &index)
+		calcDBName := fmt.Sprintf(AccountDBNameFormat, index, idb.info.DBCount, calcDBPostFix)
+
+		// remove calculate DB
+		err = os.RemoveAll(filepath.Join(idb.info.DBRoot, calcDBName))
+		if err != nil {
+			log.Printf("Failed to remove old calculate DB")
+			return err
+		} else {
+			log.Printf("remove old calculate DB. %s", calcDBName)
+		}
+
+		// rename backup DB to calculate DB
+		err = os.Rename(f, filepath.Join(idb.info.DBRoot, calcDBName))
+		if err != nil {
+			log.Printf("Failed to rename backup DB to query DB. %s -> %s", f, calcDBName)
+			return err
+		} else {
+			log.Printf("rename backup DB to query DB. %s -> %s", f, calcDBName)
+			rollbackCount++
+		}
+	}
+	log.Printf("Rollback %d account DB of %d", rollbackCount, blockHeight)
+
+	return nil
+}
+
+func (idb *IScoreDB) setCalculatingBH(blockHeight uint64, blockHash []byte) {
 	idb.info.Calculating = blockHeight
+	idb.info.CalculatingHash = append([]byte(nil), blockHash...)
 
 	idb.writeToDB()
 }
 
 func (idb *IScoreDB) resetCalculatingBH() {
 	idb.info.Calculating = idb.info.CalcDone
+	idb.info.CalculatingHash = nil
 
 	idb.writeToDB()
 }
@@ -217,11 +380,25 @@ func (idb *IScoreDB) setCurrentBlockInfo(blockHeight uint64, blockHash []byte) {
 	idb.writeToDB()
 }
 
-func (idb *IScoreDB) setCalcDoneBH(blockHeight uint64) {
+// setCalcDoneBH writes block height of the calculation and calculation history at once
+func (idb *IScoreDB) setCalcDoneBH(blockHeight uint64) error {
+	prevCalcDone := idb.info.PrevCalcDone
 	idb.info.PrevCalcDone = idb.info.CalcDone
 	idb.info.CalcDone = blockHeight
 
-	idb.writeToDB()
+	batch, _ := idb.management.GetBatch()
+	batch.New()
+	value, _ := idb.info.Bytes()
+	batch.Set(dbInfoKey(idb.info), value)
+	batch.Set(calcHistoryKey(idb.info.CalcDone), blockHeightKey(idb.info.PrevCalcDone))
+	if err := batch.Write(); err != nil {
+		log.Printf("Failed to write calculation block height %d. %v", blockHeight, err)
+		idb.info.CalcDone = idb.info.PrevCalcDone
+		idb.info.PrevCalcDone = prevCalcDone
+		return err
+	}
+	batch.Reset()
+	return nil
 }
 
 func (idb *IScoreDB) getCurrentBlockInfo() *BlockInfo {
@@ -240,94 +417,250 @@ func (idb *IScoreDB) getCalculatingBH() uint64 {
 	return idb.info.Calculating
 }
 
+func (idb *IScoreDB) getCalculatingHash() []byte {
+	return idb.info.CalculatingHash
+}
+
 func (idb *IScoreDB) rollbackCurrentBlockInfo(blockHeight uint64, blockHash []byte) {
 	idb.setCurrentBlockInfo(blockHeight, blockHash)
 
 	idb.writeToDB()
 }
 
+// getRollbackLimitBH returns the highest block height which can't be rolled back to.
+// Rollback to PrevCalcDone is always possible and can go further while backup account DBs remain.
+func (idb *IScoreDB) getRollbackLimitBH() uint64 {
+	limit := idb.getPrevCalcDoneBH()
+	for limit != 0 && idb.hasBackupAccountDB(limit) {
+		prev, ok := idb.getCalcHistory(limit)
+		if !ok || prev >= limit {
+			break
+		}
+		limit = prev
+	}
+	return limit
+}
+
 func (idb *IScoreDB) rollbackAccountDBBlockInfo() {
+	idb.setRollbackAccountDBBlockInfo()
+
+	idb.writeToDB()
+}
+
+func (idb *IScoreDB) setRollbackAccountDBBlockInfo() {
 	idb.info.CalcDone = idb.info.PrevCalcDone
 	idb.info.Calculating = idb.info.PrevCalcDone
 
-	idb.writeToDB()
+	// set previous calculation if backup account DB remains for further rollback
+	prev, ok := idb.getCalcHistory(idb.info.CalcDone)
+	if ok && prev < idb.info.CalcDone && idb.hasBackupAccountDB(idb.info.CalcDone) {
+		idb.info.PrevCalcDone = prev
+	}
+}
+
+func blockHeightKey(blockHeight uint64) []byte {
+	bs := make([]byte, BlockHeightSize)
+	bh := common.Uint64ToBytes(blockHeight)
+	copy(bs[BlockHeightSize-len(bh):], bh)
+	return bs
 }
 
-func (idb *IScoreDB) writeToDB() {
+func calcHistoryKey(blockHeight uint64) []byte {
+	return append([]byte(db.PrefixCalcHistory), blockHeightKey(blockHeight)...)
+}
+
+// getCalcHistory returns block height of the previous calculation
+func (idb *IScoreDB) getCalcHistory(blockHeight uint64) (uint64, bool) {
+	bucket, _ := idb.management.GetBucket(db.PrefixCalcHistory)
+	bs, err := bucket.Get(blockHeightKey(blockHeight))
+	if err != nil || len(bs) == 0 {
+		return 0, false
+	}
+	return common.BytesToUint64(bs), true
+}
+
+func (idb *IScoreDB) deleteCalcHistory(blockHeight uint64) {
+	bucket, _ := idb.management.GetBucket(db.PrefixCalcHistory)
+	bucket.Delete(blockHeightKey(blockHeight))
+}
+
+// deleteOldCalcHistory deletes calculation history lower than blockHeight
+func (idb *IScoreDB) deleteOldCalcHistory(blockHeight uint64) {
+	bhList := make([]uint64, 0)
+	iter, _ := idb.management.GetIterator()
+	prefix := util.BytesPrefix([]byte(db.PrefixCalcHistory))
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		bh := common.BytesToUint64(iter.Key()[len(db.PrefixCalcHistory):])
+		if bh >= blockHeight {
+			break
+		}
+		bhList = append(bhList, bh)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		log.Printf("There is error while calculation history iteration. %+v", err)
+	}
+
+	for _, bh := range bhList {
+		idb.deleteCalcHistory(bh)
+	}
+}
+
+func (idb *IScoreDB) writeToDB() error {
 	bucket, _ := idb.management.GetBucket(db.PrefixManagement)
 	value, _ := idb.info.Bytes()
-	bucket.Set(idb.info.ID(), value)
+	if err := bucket.Set(idb.info.ID(), value); err != nil {
+		log.Printf("Failed to write DB information %s. %v", idb.info.String(), err)
+		return err
+	}
+	return nil
 }
 
 func (idb *IScoreDB) rollbackAccountDB(blockHeight uint64) error {
 	log.Printf("Start Rollback account DB to %d", blockHeight)
-	var calcDBPostFix = 0
-	if idb.info.QueryDBIsZero {
-		calcDBPostFix = 1
+
+	if limit := idb.getRollbackLimitBH(); limit >= blockHeight {
+		return &RollbackLowBlockHeightError{limit, blockHeight}
 	}
 
-	backups, err := filepath.Glob(filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+"*"))
-	if err != nil {
-		log.Printf("Failed to get backup account DB")
+	journal := newJournal(JournalRollback, blockHeight)
+	if err := idb.writeJournal(journal); err != nil {
 		return err
 	}
 
+	return idb.doRollbackAccountDB(journal)
+}
+
+// doRollbackAccountDB restores backup account DBs with journal. It resumes the step which was stopped.
+func (idb *IScoreDB) doRollbackAccountDB(journal *Journal) error {
+	blockHeight := journal.BlockHeight
+
 	// rollback account DB
 	idb.CloseAccountDB()
-	rollbackCount := 0
-	for _, f := range backups {
-		var backupBH, index int
-		_, backupName := filepath.Split(f)
-		fmt.Sscanf(backupName, BackupDBNameFormat, &backupBH, &index)
-		calcDBName := fmt.Sprintf(AccountDBNameFormat, index, idb.info.DBCount, calcDBPostFix)
+	defer idb.OpenAccountDB()
 
-		// remove calculate DB
-		err = os.RemoveAll(filepath.Join(idb.info.DBRoot, calcDBName))
-		if err != nil && os.IsNotExist(err) {
-			log.Printf("Failed to remove old calculate DB")
+	// resume the step of journal
+	if journal.Step != 0 {
+		if err := idb.rollbackAccountDBStep(journal); err != nil {
 			return err
-		} else {
-			log.Printf("remove old calculate DB. %s", calcDBName)
 		}
+	}
 
-		// rename backup DB to calculate DB
-		err = os.Rename(f, filepath.Join(idb.info.DBRoot, calcDBName))
-		if err != nil {
-			log.Printf("Failed to rename backup DB to query DB. %s -> %s", f, calcDBName)
+	// rollback canceled calculation
+	if idb.isCalculating() && idb.hasBackupAccountDB(idb.getCalculatingBH()) {
+		journal.Step = idb.getCalculatingBH()
+		if err := idb.rollbackAccountDBStep(journal); err != nil {
 			return err
-		} else {
-			log.Printf("rename backup DB to query DB. %s -> %s", f, calcDBName)
-			rollbackCount++
 		}
 	}
-	log.Printf("Rollback %d account DB", rollbackCount)
-	idb.OpenAccountDB()
-
-	// set toggle block height with rollback block height
-	idb.toggleAccountDB(blockHeight)
 
-	// delete calculation result
-	DeleteCalculationResult(idb.getCalculateResultDB(), idb.getCalcDoneBH())
+	// rollback calculations term by term
+	for blockHeight <= idb.getCalcDoneBH() && idb.getCalcDoneBH() != idb.getPrevCalcDoneBH() {
+		journal.Step = idb.getCalcDoneBH()
+		if err := idb.rollbackAccountDBStep(journal); err != nil {
+			return err
+		}
+	}
 
-	// Rollback block height and block hash
-	idb.rollbackAccountDBBlockInfo()
+	// set toggle block height with rollback block height
+	idb.info.ToggleBH = blockHeight
+	if err := idb.writeToDBWithJournal(nil); err != nil {
+		return err
+	}
 
 	log.Printf("End rollblack account DB to %d", blockHeight)
 	return nil
 }
 
+// rollbackAccountDBStep restores backup account DB of journal.Step.
+// Toggled account DB and block heights are written to DB with journal at once.
+func (idb *IScoreDB) rollbackAccountDBStep(journal *Journal) error {
+	stepBH := journal.Step
+	if err := idb.writeJournal(journal); err != nil {
+		return err
+	}
+
+	if err := idb.restoreBackupAccountDB(stepBH); err != nil {
+		return err
+	}
+
+	// delete reward ledger
+	deleteRewardLedger(idb.getRewardLedgerDB(), stepBH)
+
+	if idb.isCalculating() && stepBH == idb.getCalculatingBH() {
+		// canceled calculation
+		idb.info.Calculating = idb.info.CalcDone
+	} else {
+		// delete calculation result
+		DeleteCalculationResult(idb.getCalculateResultDB(), stepBH)
+
+		idb.deleteCalcHistory(stepBH)
+
+		// Rollback block height
+		idb.setRollbackAccountDBBlockInfo()
+	}
+
+	// backup DB has values before the calculation, so it is query DB now
+	idb.accountLock.Lock()
+	idb.info.QueryDBIsZero = !idb.info.QueryDBIsZero
+	idb.accountLock.Unlock()
+
+	journal.Step = 0
+	return idb.writeToDBWithJournal(journal)
+}
+
 type Context struct {
 	DB *IScoreDB
 
+	// network profile of I-Score DB. It is not changed after NewContext
+	network *NetworkProfile
+
+	// calculation and rollback change Revision, PRep, PRepCandidates, GV and rewardPolicySwitches with stateLock.
+	// Others read them with snapshotState
+	stateLock      sync.RWMutex
 	Revision       uint64
 	PRep           []*PRep
 	PRepCandidates map[common.Address]*PRepCandidate
 	GV             []*GovernanceVariable
 
+	// reward policy switches in block height order
+	rewardPolicySwitches []*rewardPolicySwitch
+
 	stats             *Statistics
 	CancelCalculation *CancelCalculation
+	progress          *calcProgress
 
 	calcDebug *CalcDebug
+
+	// key to sign calculation results. nil if it is not set
+	nodeKey *crypto.PrivateKey
+
+	// Merkle tree of the latest calculation for account proof. Query holds stateTreeBuildLock to make the tree
+	stateTreeLock      sync.Mutex
+	stateTreeBuildLock sync.Mutex
+	stateTree          *merkleTree
+}
+
+// snapshotState returns a context with a copy of Revision, PRep, PRepCandidates, GV and rewardPolicySwitches
+func (ctx *Context) snapshotState() *Context {
+	ctx.stateLock.RLock()
+	defer ctx.stateLock.RUnlock()
+
+	state := &Context{
+		network:              ctx.network,
+		Revision:             ctx.Revision,
+		PRep:                 append([]*PRep(nil), ctx.PRep...),
+		PRepCandidates:       make(map[common.Address]*PRepCandidate, len(ctx.PRepCandidates)),
+		GV:                   append([]*GovernanceVariable(nil), ctx.GV...),
+		rewardPolicySwitches: append([]*rewardPolicySwitch(nil), ctx.rewardPolicySwitches...),
+	}
+	// End of P-Rep candidate is changed in place
+	for address, candidate := range ctx.PRepCandidates {
+		p := *candidate
+		state.PRepCandidates[address] = &p
+	}
+	return state
 }
 
 func (ctx *Context) getGVByBlockHeight(blockHeight uint64) *GovernanceVariable {
@@ -348,7 +681,7 @@ func (ctx *Context) UpdateGovernanceVariable(gvList []*IISSGovernanceVariable) {
 	for _, gvIISS := range gvList {
 		// there is new GV
 		if len(ctx.GV) == 0 || ctx.GV[len(ctx.GV)-1].BlockHeight < gvIISS.BlockHeight {
-			gv := NewGVFromIISS(gvIISS)
+			gv := NewGVFromIISS(gvIISS, ctx.network)
 
 			// write to memory
 			ctx.GV = append(ctx.GV, gv)
@@ -363,8 +696,9 @@ func (ctx *Context) UpdateGovernanceVariable(gvList []*IISSGovernanceVariable) {
 	gvLen := len(ctx.GV)
 	deleteOld := false
 	deleteIndex := -1
+	rollbackLimit := ctx.DB.getRollbackLimitBH()
 	for i := gvLen - 1; i >= 0; i-- {
-		if ctx.GV[i].BlockHeight <= ctx.DB.getPrevCalcDoneBH() {
+		if ctx.GV[i].BlockHeight <= rollbackLimit {
 			if deleteOld {
 				// delete from management DB
 				bucket.Delete(ctx.GV[i].ID())
@@ -398,8 +732,9 @@ func (ctx *Context) UpdatePRep(prepList []*PRep) {
 	prepLen := len(ctx.PRep)
 	deleteOld := false
 	deleteIndex := -1
+	rollbackLimit := ctx.DB.getRollbackLimitBH()
 	for i := prepLen - 1; i >= 0; i-- {
-		if ctx.PRep[i].BlockHeight <= ctx.DB.getPrevCalcDoneBH() {
+		if ctx.PRep[i].BlockHeight <= rollbackLimit {
 			if deleteOld {
 				// delete from management DB
 				bucket.Delete(ctx.PRep[i].ID())
@@ -482,6 +817,9 @@ func (ctx *Context) UpdatePRepCandidate(iissDB db.Database) {
 }
 
 func (ctx *Context) RollbackManagementDB(blockHeight uint64) {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+
 	// Rollback Governance Variable
 	bucket, _ := ctx.DB.management.GetBucket(db.PrefixGovernanceVariable)
 	gvLen := len(ctx.GV)
@@ -505,12 +843,19 @@ func (ctx *Context) RollbackManagementDB(blockHeight uint64) {
 			ctx.PRep = ctx.PRep[:i]
 		}
 	}
+
+	// Rollback reward policy switches of rolled back terms
+	ctx.rollbackRewardPolicySwitches(ctx.DB.getCalcDoneBH())
 }
 
 func (ctx *Context) Print() {
 	log.Printf("============================================================================")
 	log.Printf("Print context values\n")
+	log.Printf("Network profile : %s\n", ctx.network.String())
 	log.Printf("Revision : %d\n", ctx.Revision)
+	for i, v := range ctx.rewardPolicySwitches {
+		log.Printf("\tReward policy switch %d: %s\n", i, v.String())
+	}
 	log.Printf("Database Info.: %s\n", ctx.DB.info.String())
 	log.Printf("Governance Variable: %d\n", len(ctx.GV))
 	for i, v := range ctx.GV {
@@ -526,25 +871,41 @@ func (ctx *Context) Print() {
 	log.Printf("============================================================================")
 }
 
-func NewContext(dbPath string, dbType string, dbName string, dbCount int, debugConfigPath string) (*Context, error) {
+// NewContext opens I-Score DB. profile is written to new I-Score DB and must be the same with the network profile
+// of existing I-Score DB. With nil profile, it uses the network profile of I-Score DB.
+func NewContext(dbPath string, dbType string, dbName string, dbCount int, debugConfigPath string,
+	profile *NetworkProfile) (*Context, error) {
 	ctx := new(Context)
 	isDB := new(IScoreDB)
 	ctx.DB = isDB
-	var err error
 
 	// Open management DB
-	mngDB := db.Open(dbPath, dbType, dbName)
+	mngDB, err := openManagementDB(dbPath, dbType, dbName)
+	if err != nil {
+		log.Printf("Failed to open management DB. %v", err)
+		return nil, err
+	}
 	isDB.management = mngDB
 
 	// read DB Info.
 	isDB.info, err = NewDBInfo(mngDB, dbPath, dbType, dbName, dbCount)
 	if err != nil {
-		log.Panicf("Failed to load DB Information\n")
+		log.Printf("Failed to load DB Information. %v", err)
+		mngDB.Close()
 		return nil, err
 	}
 
+	// read network profile
+	np, err := loadNetworkProfile(mngDB, profile, isDB.info.CalcDone != 0)
+	if err != nil {
+		log.Printf("Failed to load network profile. %v", err)
+		mngDB.Close()
+		return nil, err
+	}
+	ctx.network = np
+
 	// read Governance variable
-	ctx.GV, err = LoadGovernanceVariable(mngDB)
+	ctx.GV, err = LoadGovernanceVariable(mngDB, np)
 	if err != nil {
 		log.Printf("Failed to load GV structure\n")
 		return nil, err
@@ -564,6 +925,16 @@ func NewContext(dbPath string, dbType string, dbName string, dbCount int, debugC
 		return nil, err
 	}
 
+	// read reward policy switches. revision is of the latest switch
+	ctx.rewardPolicySwitches, err = loadRewardPolicySwitches(mngDB)
+	if err != nil {
+		log.Printf("Failed to load reward policy switches\n")
+		return nil, err
+	}
+	if n := len(ctx.rewardPolicySwitches); n > 0 {
+		ctx.Revision = ctx.rewardPolicySwitches[n-1].Revision
+	}
+
 	InitCalcDebugConfig(ctx, debugConfigPath)
 
 	// Open calculation result DB
@@ -580,9 +951,16 @@ func NewContext(dbPath string, dbType string, dbName string, dbCount int, debugC
 
 	// Open account DB
 	isDB.OpenAccountDB()
+	isDB.backupCount = 1
+
+	// complete the operation of account DB which was stopped by crash
+	if err = isDB.recoverJournal(); err != nil {
+		return nil, err
+	}
 
 	// make new CancelCalculation stuff
 	ctx.CancelCalculation = NewCancel()
+	ctx.progress = newCalcProgress()
 
 	return ctx, nil
 }
@@ -607,4 +985,9 @@ func CloseIScoreDB(isDB *IScoreDB) {
 
 	// close claim backup DB
 	isDB.claimBackup.Close()
+
+	// close reward ledger DB
+	if isDB.rewardLedger != nil {
+		isDB.rewardLedger.Close()
+	}
 }
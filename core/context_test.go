@@ -15,15 +15,25 @@ import (
 
 var testDir string
 
+// testDBType is the backend of I-Score DB for tests. Set ICON_RC_TEST_DB_TYPE to test other backends
+var testDBType = getTestDBType()
+
+func getTestDBType() string {
+	if dbType, ok := os.LookupEnv("ICON_RC_TEST_DB_TYPE"); ok {
+		return dbType
+	}
+	return string(db.GoLevelDBBackend)
+}
+
 func initTest(dbCount int) *Context{
 	var err error
-	testDir, err = ioutil.TempDir("", "goleveldb")
+	testDir, err = ioutil.TempDir("", testDBType)
 	if err != nil {
 		panic(err)
 	}
 
-	ctx, _ := NewContext(testDir, string(db.GoLevelDBBackend), "test", dbCount,
-		"debugConfigPath")
+	ctx, _ := NewContext(testDir, testDBType, "test", dbCount,
+		"debugConfigPath", nil)
 
 	return ctx
 }
@@ -33,6 +43,30 @@ func finalizeTest(ctx *Context) {
 	os.RemoveAll(testDir)
 }
 
+// makeTestAccounts returns accounts whose addresses are spread over account DBs.
+// I-Score of accounts[i] is testNetwork.ClaimMinIScore * (i+1)
+func makeTestAccounts(count int) []*IScoreAccount {
+	accounts := make([]*IScoreAccount, count)
+	for i := 0; i < count; i++ {
+		ia := makeIA()
+		ia.Address = *common.NewAddressFromString(fmt.Sprintf("hx%02x%038x", i*7, i))
+		ia.IScore.SetUint64(testNetwork.ClaimMinIScore * uint64(i+1))
+		accounts[i] = ia
+	}
+	return accounts
+}
+
+// writeTestAccounts writes accounts to account DB of getDB and returns their addresses
+func writeTestAccounts(accounts []*IScoreAccount, getDB func(address common.Address) db.Database) []common.Address {
+	addresses := make([]common.Address, len(accounts))
+	for i, ia := range accounts {
+		bucket, _ := getDB(ia.Address).GetBucket(db.PrefixIScore)
+		bucket.Set(ia.ID(), ia.Bytes())
+		addresses[i] = ia.Address
+	}
+	return addresses
+}
+
 func TestContext_NewContext(t *testing.T) {
 	const dbCount int = 16
 	ctx := initTest(dbCount)
@@ -454,8 +488,7 @@ func TestContext_ResetAccountDB(t *testing.T) {
 	assert.NoError(t, err)
 
 	blockHeight := uint64(1000)
-	oldBlockHeight := ctx.DB.getCalcDoneBH()
-	err = ctx.DB.resetAccountDB(blockHeight, oldBlockHeight)
+	err = ctx.DB.resetAccountDB(blockHeight)
 	assert.NoError(t, err)
 
 	// same query DB
@@ -465,17 +498,11 @@ func TestContext_ResetAccountDB(t *testing.T) {
 	assert.NotEqual(t, cDBList, ctx.DB.GetCalcDBList())
 	assert.Equal(t, dbCount, len(ctx.DB.GetCalcDBList()))
 
-	// old and new backup DB
+	// new backup DB
 	for i := 0; i < ctx.DB.info.DBCount; i++ {
-		// old backup DB were deleted
-		backupName := fmt.Sprintf(BackupDBNameFormat, oldBlockHeight, i+1)
-		stat, err := os.Stat(filepath.Join(ctx.DB.info.DBRoot, backupName))
-		assert.Error(t, err)
-		assert.True(t, os.IsNotExist(err))
-
 		// new backup DB wew created
-		backupName = fmt.Sprintf(BackupDBNameFormat, blockHeight, i+1)
-		stat, err = os.Stat(filepath.Join(ctx.DB.info.DBRoot, backupName))
+		backupName := fmt.Sprintf(BackupDBNameFormat, blockHeight, i+1)
+		stat, err := os.Stat(filepath.Join(ctx.DB.info.DBRoot, backupName))
 		assert.NoError(t, err)
 		assert.True(t, stat.IsDir())
 	}
@@ -553,7 +580,7 @@ func TestContext_RollbackAccountDB(t *testing.T) {
 	prevBlockHeight := uint64(5)
 	ctx.DB.setCalcDoneBH(prevBlockHeight)
 	blockHeight := uint64(10)
-	ctx.DB.setCalculatingBH(blockHeight)
+	ctx.DB.setCalculatingBH(blockHeight, nil)
 	ctx.DB.writeToDB()
 	ctx.DB.toggleAccountDB(blockHeight + 1)
 
@@ -562,9 +589,9 @@ func TestContext_RollbackAccountDB(t *testing.T) {
 	//assert.Error(t, err)
 
 	// reset account DB to make backup account DB
-	err = ctx.DB.resetAccountDB(blockHeight, ctx.DB.getCalcDoneBH())
+	err = ctx.DB.resetAccountDB(blockHeight)
 	assert.NoError(t, err)
-	WriteCalculationResult(crDB, blockHeight, nil, nil)
+	WriteCalculationResult(crDB, blockHeight, nil, nil, nil, nil)
 	ctx.DB.setCalcDoneBH(blockHeight)
 	ctx.DB.writeToDB()
 	assert.Equal(t, prevBlockHeight, ctx.DB.getPrevCalcDoneBH())
@@ -611,6 +638,142 @@ func TestContext_RollbackAccountDB(t *testing.T) {
 	assert.Nil(t, bs)
 }
 
+// emulateCalculation writes I-Score of ia with block height to calculate DB
+func emulateCalculation(ctx *Context, ia *IScoreAccount, blockHeight uint64, done bool) {
+	ctx.DB.setCalculatingBH(blockHeight, nil)
+	ctx.DB.toggleAccountDB(blockHeight + 1)
+	ctx.DB.resetAccountDB(blockHeight)
+
+	ia.IScore.SetUint64(blockHeight)
+	bucket, _ := ctx.DB.getCalculateDB(ia.Address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+
+	if done {
+		ctx.DB.setCalcDoneBH(blockHeight)
+		WriteCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight, nil, nil, nil, nil)
+		ctx.DB.deleteOldBackupAccountDB()
+	}
+}
+
+func readIScore(t *testing.T, aDB db.Database, ia *IScoreAccount) uint64 {
+	bucket, _ := aDB.GetBucket(db.PrefixIScore)
+	bs, _ := bucket.Get(ia.ID())
+	if bs == nil {
+		return 0
+	}
+	account, err := NewIScoreAccountFromBytes(bs)
+	assert.NoError(t, err)
+	return account.IScore.Uint64()
+}
+
+func TestContext_DeleteOldBackupAccountDB(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetBackupCount(2)
+
+	ia := makeIA()
+	emulateCalculation(ctx, ia, 10, true)
+	emulateCalculation(ctx, ia, 20, true)
+	emulateCalculation(ctx, ia, 30, true)
+
+	bhList, err := ctx.DB.getBackupAccountDBList()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{30, 20}, bhList)
+
+	// calculation history for rollback
+	_, ok := ctx.DB.getCalcHistory(10)
+	assert.False(t, ok)
+	prev, ok := ctx.DB.getCalcHistory(20)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), prev)
+	prev, ok = ctx.DB.getCalcHistory(30)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(20), prev)
+
+	assert.Equal(t, uint64(10), ctx.DB.getRollbackLimitBH())
+
+	// reduce backup count
+	ctx.DB.SetBackupCount(1)
+	ctx.DB.deleteOldBackupAccountDB()
+	bhList, err = ctx.DB.getBackupAccountDBList()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{30}, bhList)
+	assert.Equal(t, uint64(20), ctx.DB.getRollbackLimitBH())
+}
+
+func TestContext_RollbackAccountDB_MultiTerm(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetBackupCount(2)
+
+	ia := makeIA()
+	emulateCalculation(ctx, ia, 10, true)
+	emulateCalculation(ctx, ia, 20, true)
+	emulateCalculation(ctx, ia, 30, true)
+	assert.Equal(t, uint64(20), readIScore(t, ctx.DB.getQueryDB(ia.Address), ia))
+	assert.Equal(t, uint64(30), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+
+	// too low block height
+	assert.Error(t, ctx.DB.rollbackAccountDB(10))
+
+	// rollback 2 terms
+	err := ctx.DB.rollbackAccountDB(11)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), ctx.DB.getCalcDoneBH())
+	assert.Equal(t, uint64(10), ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, uint64(10), ctx.DB.getCalculatingBH())
+	assert.Equal(t, uint64(11), ctx.DB.info.ToggleBH)
+	assert.Equal(t, uint64(0), readIScore(t, ctx.DB.getQueryDB(ia.Address), ia))
+	assert.Equal(t, uint64(10), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+
+	// calculation results and history were deleted
+	crBucket, _ := ctx.DB.getCalculateResultDB().GetBucket(db.PrefixCalcResult)
+	for _, bh := range []uint64{20, 30} {
+		bs, _ := crBucket.Get(common.Uint64ToBytes(bh))
+		assert.Nil(t, bs)
+		_, ok := ctx.DB.getCalcHistory(bh)
+		assert.False(t, ok)
+	}
+	bs, _ := crBucket.Get(common.Uint64ToBytes(10))
+	assert.NotNil(t, bs)
+
+	bhList, err := ctx.DB.getBackupAccountDBList()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(bhList))
+}
+
+func TestContext_RollbackAccountDB_Calculating(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetBackupCount(2)
+
+	ia := makeIA()
+	emulateCalculation(ctx, ia, 10, true)
+	emulateCalculation(ctx, ia, 20, true)
+	emulateCalculation(ctx, ia, 30, true)
+
+	// calculation was canceled
+	emulateCalculation(ctx, ia, 40, false)
+	assert.True(t, ctx.DB.isCalculating())
+	assert.Equal(t, uint64(10), ctx.DB.getRollbackLimitBH())
+
+	err := ctx.DB.rollbackAccountDB(21)
+	assert.NoError(t, err)
+	assert.False(t, ctx.DB.isCalculating())
+	assert.Equal(t, uint64(20), ctx.DB.getCalcDoneBH())
+	assert.Equal(t, uint64(10), ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, uint64(10), readIScore(t, ctx.DB.getQueryDB(ia.Address), ia))
+	assert.Equal(t, uint64(20), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+
+	// rollback one more term
+	err = ctx.DB.rollbackAccountDB(15)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), ctx.DB.getCalcDoneBH())
+	assert.Equal(t, uint64(10), ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, uint64(0), readIScore(t, ctx.DB.getQueryDB(ia.Address), ia))
+	assert.Equal(t, uint64(10), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+}
+
 func TestContext_WriteToDB(t *testing.T) {
 	ctx := initTest(1)
 	defer finalizeTest(ctx)
@@ -658,3 +821,32 @@ func TestContext_GetGVByBlockHeight(t *testing.T) {
 	gv = ctx.getGVByBlockHeight(gvBH1 + 100)
 	assert.Equal(t, gvBH1, gv.BlockHeight)
 }
+
+func TestContext_snapshotState(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	ctx.Revision = Revision8
+	ctx.GV = append(ctx.GV, &GovernanceVariable{BlockHeight: 10})
+	ctx.PRep = append(ctx.PRep, &PRep{BlockHeight: 10})
+	prep := &PRepCandidate{Address: *common.NewAddressFromString("hxaa")}
+	prep.Start = 10
+	ctx.PRepCandidates[prep.Address] = prep
+	ctx.SetRewardPolicySwitch(15, Revision8)
+
+	state := ctx.snapshotState()
+
+	// calculation changes context
+	ctx.Revision = Revision8 + 1
+	ctx.GV = append(ctx.GV, &GovernanceVariable{BlockHeight: 20})
+	ctx.PRep[0] = &PRep{BlockHeight: 20}
+	prep.End = 20
+	ctx.SetRewardPolicySwitch(10, Revision8)
+
+	assert.Equal(t, uint64(Revision8), state.Revision)
+	assert.Equal(t, 1, len(state.GV))
+	assert.Equal(t, uint64(10), state.PRep[0].BlockHeight)
+	assert.Equal(t, uint64(0), state.PRepCandidates[prep.Address].End)
+	assert.Equal(t, 1, len(state.rewardPolicySwitches))
+	assert.Equal(t, uint64(15), state.rewardPolicySwitches[0].BlockHeight)
+}
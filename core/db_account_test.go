@@ -92,7 +92,7 @@ func TestDBAccount_NewIScoreAccountFromIISS(t *testing.T) {
 
 	dgData := make([]interface{}, 0)
 	dgData = append(dgData, common.NewAddressFromString(delegationAddress))
-	dgData = append(dgData, MinDelegation)
+	dgData = append(dgData, testNetwork.MinDelegation())
 	delegation = append(delegation, dgData)
 	var err error
 	tx.Data, err = common.EncodeAny(delegation)
@@ -108,5 +108,5 @@ func TestDBAccount_NewIScoreAccountFromIISS(t *testing.T) {
 	assert.Equal(t, iaBlockHeight, ia.BlockHeight)
 	assert.Equal(t, 1, len(ia.Delegations))
 	assert.True(t, ia.Delegations[0].Address.Equal(common.NewAddressFromString(delegationAddress)))
-	assert.Equal(t, uint64(MinDelegation), ia.Delegations[0].Delegate.Uint64())
+	assert.Equal(t, uint64(testNetwork.MinDelegation()), ia.Delegations[0].Delegate.Uint64())
 }
@@ -2,10 +2,13 @@ package core
 
 import (
 	"encoding/json"
+	"log"
+
 	"github.com/icon-project/rewardcalculator/common/db"
 
 	"github.com/icon-project/rewardcalculator/common"
 	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/crypto"
 )
 type CRData struct {
 	Success bool
@@ -14,6 +17,8 @@ type CRData struct {
 	Beta1 common.HexInt
 	Beta2 common.HexInt
 	Beta3 common.HexInt
+	StateRoot []byte
+	Signature []byte // signature of ResultAttestation with node key
 }
 
 type CalculationResult struct {
@@ -51,6 +56,11 @@ func (cr *CalculationResult) SetBytes(bs []byte) error {
 	return nil
 }
 
+func (cr *CalculationResult) Attestation() *ResultAttestation {
+	return newResultAttestation(cr.BlockHeight, cr.StateHash, cr.StateRoot, &cr.IScore,
+		&cr.Beta1, &cr.Beta2, &cr.Beta3)
+}
+
 func NewCalculationResultFromBytes(bs []byte) (*CalculationResult, error) {
 	cr := new(CalculationResult)
 	if err:= cr.SetBytes(bs); err != nil {
@@ -60,22 +70,47 @@ func NewCalculationResultFromBytes(bs []byte) (*CalculationResult, error) {
 	}
 }
 
-func WriteCalculationResult(crDB db.Database, blockHeight uint64, stats *Statistics, stateHash []byte) {
+// WriteCalculationResult writes the result of calculation. It is signed if key is not nil
+func WriteCalculationResult(crDB db.Database, blockHeight uint64, stats *Statistics, stateHash []byte,
+	stateRoot []byte, key *crypto.PrivateKey) *CalculationResult {
 	cr := new(CalculationResult)
 
 	cr.Success = true
 	cr.BlockHeight = blockHeight
 	cr.StateHash = stateHash
+	cr.StateRoot = stateRoot
 	if stats != nil {
 		cr.IScore.Set(&stats.TotalReward.Int)
 		cr.Beta1.Set(&stats.Beta1.Int)
 		cr.Beta2.Set(&stats.Beta2.Int)
 		cr.Beta3.Set(&stats.Beta3.Int)
 	}
+	if key != nil {
+		var err error
+		if cr.Signature, err = cr.Attestation().Sign(key); err != nil {
+			log.Printf("Failed to sign calculation result of %d. %v", blockHeight, err)
+		}
+	}
 
 	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
 	bs, _ := cr.Bytes()
 	bucket.Set(cr.ID(), bs)
+	return cr
+}
+
+// ReadCalculationResult returns nil if there is no result of the block height
+func ReadCalculationResult(crDB db.Database, blockHeight uint64) (*CalculationResult, error) {
+	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
+	bs, err := bucket.Get(common.Uint64ToBytes(blockHeight))
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	cr, err := NewCalculationResultFromBytes(bs)
+	if err != nil {
+		return nil, err
+	}
+	cr.BlockHeight = blockHeight
+	return cr, nil
 }
 
 func DeleteCalculationResult(crDB db.Database, blockHeight uint64) {
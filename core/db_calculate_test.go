@@ -78,7 +78,7 @@ func TestDBCalculate_CalculationResult(t *testing.T) {
 	stateHash := make([]byte, 64)
 	binary.BigEndian.PutUint64(stateHash, calcBlockHeight)
 
-	WriteCalculationResult(crDB, calcBlockHeight, stats, stateHash)
+	WriteCalculationResult(crDB, calcBlockHeight, stats, stateHash, nil, nil)
 
 	bucket, err := crDB.GetBucket(db.PrefixCalcResult)
 	assert.NoError(t, err)
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+// calcCheckpointCount is the number of accounts calculated by calculateDB between checkpoints
+var calcCheckpointCount uint64 = 10000
+
+type CalcCheckpointData struct {
+	BlockHeight uint64 // block height of calculation
+	DBCount     int
+	LastKey     []byte // the last key of query DB which was calculated
+	Count       uint64 // the number of accounts written to calculate DB
+	Accounts    uint64
+	Beta3       common.HexInt
+	BlockHash   []byte // block hash of calculation
+	Header      []byte // IISS data header of calculation
+}
+
+// CalcCheckpoint is progress of calculateDB for an account DB.
+// Reward Calculator continues the calculation from checkpoint when it restarts while calculating.
+type CalcCheckpoint struct {
+	Index int
+	CalcCheckpointData
+}
+
+func (cp *CalcCheckpoint) ID() []byte {
+	return common.Uint64ToBytes(uint64(cp.Index))
+}
+
+func (cp *CalcCheckpoint) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&cp.CalcCheckpointData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (cp *CalcCheckpoint) String() string {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (cp *CalcCheckpoint) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &cp.CalcCheckpointData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkpointHeader returns IISS data header in checkpoint. IISS data path is not used because it changes
+// when IISS data is reloaded
+func checkpointHeader(header *IISSHeader) []byte {
+	if header == nil {
+		return nil
+	}
+	bs, _ := header.Bytes()
+	return bs
+}
+
+func newCalcCheckpoint(index int, blockHeight uint64, blockHash []byte, header *IISSHeader, dbCount int,
+	lastKey []byte, count uint64, stats *Statistics) *CalcCheckpoint {
+	cp := new(CalcCheckpoint)
+	cp.Index = index
+	cp.BlockHeight = blockHeight
+	cp.BlockHash = append([]byte(nil), blockHash...)
+	cp.Header = checkpointHeader(header)
+	cp.DBCount = dbCount
+	cp.LastKey = make([]byte, len(lastKey))
+	copy(cp.LastKey, lastKey)
+	cp.Count = count
+	cp.Accounts = stats.Accounts
+	cp.Beta3.Set(&stats.Beta3.Int)
+	return cp
+}
+
+func (idb *IScoreDB) writeCalcCheckpoint(cp *CalcCheckpoint) error {
+	bucket, _ := idb.management.GetBucket(db.PrefixCalcCheckpoint)
+	value, _ := cp.Bytes()
+	if err := bucket.Set(cp.ID(), value); err != nil {
+		log.Printf("Failed to write checkpoint. %s. %v", cp.String(), err)
+		return err
+	}
+	return nil
+}
+
+// getCalcCheckpoint returns checkpoint of account DB for the calculation. It returns nil if there is no checkpoint
+// made with same block and IISS data
+func (idb *IScoreDB) getCalcCheckpoint(index int, blockHeight uint64, blockHash []byte,
+	header *IISSHeader) (*CalcCheckpoint, error) {
+	bucket, _ := idb.management.GetBucket(db.PrefixCalcCheckpoint)
+	cp := &CalcCheckpoint{Index: index}
+	bs, err := bucket.Get(cp.ID())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	if err = cp.SetBytes(bs); err != nil {
+		return nil, err
+	}
+	if cp.BlockHeight != blockHeight || cp.DBCount != idb.info.DBCount ||
+		!bytes.Equal(cp.BlockHash, blockHash) || !bytes.Equal(cp.Header, checkpointHeader(header)) {
+		return nil, nil
+	}
+	return cp, nil
+}
+
+func (idb *IScoreDB) hasCalcCheckpoint(blockHeight uint64, blockHash []byte, header *IISSHeader) bool {
+	for i := 0; i < idb.info.DBCount; i++ {
+		if cp, _ := idb.getCalcCheckpoint(i, blockHeight, blockHash, header); cp != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (idb *IScoreDB) deleteCalcCheckpoint() {
+	bucket, _ := idb.management.GetBucket(db.PrefixCalcCheckpoint)
+	for i := 0; i < MaxDBCount; i++ {
+		cp := &CalcCheckpoint{Index: i}
+		bucket.Delete(cp.ID())
+	}
+}
+
+// restoreCalcCheckpoint writes accounts in calculate DB up to checkpoint to h in key order.
+// SHAKE256 state can't be stored, so stateHash of calculateDB is restored with accounts written before checkpoint.
+// It returns the number of accounts.
+func restoreCalcCheckpoint(cp *CalcCheckpoint, calcDB db.Database, h io.Writer) (uint64, error) {
+	if len(cp.LastKey) == 0 {
+		return 0, nil
+	}
+
+	var count uint64
+	iter, _ := calcDB.GetIterator()
+	// limit is ignored with nil start
+	iter.New([]byte(db.PrefixIScore), nextKey(cp.LastKey))
+	for iter.Next() {
+		ia, err := NewIScoreAccountFromBytes(iter.Value())
+		if err != nil {
+			iter.Release()
+			return 0, err
+		}
+		ia.Address = *common.NewAddress(iter.Key()[len(db.PrefixIScore):])
+		h.Write(ia.BytesForHash())
+		count++
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
+
+	if count != cp.Count {
+		return 0, fmt.Errorf("calculate DB has %d accounts before checkpoint. %s", count, cp.String())
+	}
+	return count, nil
+}
+
+// nextKey returns the smallest key which is larger than key
+func nextKey(key []byte) []byte {
+	next := make([]byte, len(key)+1)
+	copy(next, key)
+	return next
+}
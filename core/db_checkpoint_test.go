@@ -0,0 +1,137 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBCheckpoint_CalculateDB(t *testing.T) {
+	const (
+		count                       = 10
+		calculateBlockHeight uint64 = 100
+	)
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	defaultCheckpointCount := calcCheckpointCount
+	calcCheckpointCount = 4
+	defer func() { calcCheckpointCount = defaultCheckpointCount }()
+
+	gv := new(GovernanceVariable)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
+	gv.CalculatedIncentiveRep.SetUint64(1)
+	gv.RewardRep.SetUint64(testNetwork.MinRewardRep)
+	gv.setReward(testNetwork)
+	ctx.GV = append(ctx.GV, gv)
+
+	ctx.DB.OpenRewardLedgerDB()
+
+	prep := new(PRepCandidate)
+	prep.Address = *common.NewAddressFromString("hxaa")
+	ctx.PRepCandidates[prep.Address] = prep
+
+	// write accounts to query DB
+	queryDB := ctx.DB.getQueryDBList()[0]
+	calcDB := ctx.DB.GetCalcDBList()[0]
+	qBucket, _ := queryDB.GetBucket(db.PrefixIScore)
+	cBucket, _ := calcDB.GetBucket(db.PrefixIScore)
+	accounts := make([]*IScoreAccount, count)
+	for i := 0; i < count; i++ {
+		ia := new(IScoreAccount)
+		ia.Address = *common.NewAddressFromString(fmt.Sprintf("hx%040x", i+1))
+		ia.BlockHeight = uint64(i + 1)
+		delegation := new(DelegateData)
+		delegation.Address = prep.Address
+		delegation.Delegate.SetUint64(testNetwork.MinDelegation() + uint64(i))
+		ia.Delegations = append(ia.Delegations, delegation)
+		qBucket.Set(ia.ID(), ia.Bytes())
+		accounts[i] = ia
+	}
+	quit := ctx.CancelCalculation.GetChannel()
+	header := &IISSHeader{Version: 2, BlockHeight: calculateBlockHeight, Revision: RevisionMin}
+
+	// calculate all accounts
+	expCount, expStats, expHash := calculateDB(quit, 0, queryDB, calcDB, ctx, calculateBlockHeight,
+		testHash, header, writeBatchCount, nil)
+	assert.Equal(t, uint64(count), expCount)
+	rl, _ := getRewardLedger(ctx.DB.getRewardLedgerDB(), calculateBlockHeight, accounts[0].Address)
+	expReward := rl.IScore().Uint64()
+
+	cp, _ := ctx.DB.getCalcCheckpoint(0, calculateBlockHeight, testHash, header)
+	assert.NotNil(t, cp)
+	assert.Equal(t, uint64(count), cp.Count)
+	assert.Equal(t, accounts[count-1].ID(), cp.LastKey)
+
+	// calculate first half of accounts to make checkpoint
+	for _, ia := range accounts {
+		cBucket.Delete(ia.ID())
+	}
+	for _, ia := range accounts[calcCheckpointCount:] {
+		qBucket.Delete(ia.ID())
+	}
+	calculateDB(quit, 0, queryDB, calcDB, ctx, calculateBlockHeight, testHash, header,
+		writeBatchCount, nil)
+	for _, ia := range accounts[calcCheckpointCount:] {
+		qBucket.Set(ia.ID(), ia.Bytes())
+	}
+	cp, _ = ctx.DB.getCalcCheckpoint(0, calculateBlockHeight, testHash, header)
+	assert.NotNil(t, cp)
+	assert.Equal(t, calcCheckpointCount, cp.Count)
+	assert.True(t, ctx.DB.hasCalcCheckpoint(calculateBlockHeight, testHash, header))
+
+	// checkpoint of other calculation
+	cp2, _ := ctx.DB.getCalcCheckpoint(0, calculateBlockHeight+1, testHash, header)
+	assert.Nil(t, cp2)
+	assert.False(t, ctx.DB.hasCalcCheckpoint(calculateBlockHeight+1, testHash, header))
+	assert.False(t, ctx.DB.hasCalcCheckpoint(calculateBlockHeight, zeroHash, header))
+	assert.False(t, ctx.DB.hasCalcCheckpoint(calculateBlockHeight, testHash, nil))
+	otherHeader := *header
+	otherHeader.Revision++
+	assert.False(t, ctx.DB.hasCalcCheckpoint(calculateBlockHeight, testHash, &otherHeader))
+
+	// IISS data reloaded from other path has the same header
+	reloadedHeader := *header
+	assert.True(t, ctx.DB.hasCalcCheckpoint(calculateBlockHeight, testHash, &reloadedHeader))
+
+	// continue from checkpoint
+	n, stats, hash := calculateDB(quit, 0, queryDB, calcDB, ctx, calculateBlockHeight, testHash, header,
+		writeBatchCount, cp)
+	assert.Equal(t, expCount, n)
+	assert.Equal(t, expStats.Accounts, stats.Accounts)
+	assert.Equal(t, 0, expStats.Beta3.Cmp(&stats.Beta3.Int))
+	assert.Equal(t, expHash, hash)
+
+	// reward ledger is not added twice
+	rl, _ = getRewardLedger(ctx.DB.getRewardLedgerDB(), calculateBlockHeight, accounts[0].Address)
+	assert.Equal(t, expReward, rl.IScore().Uint64())
+
+	// invalid checkpoint. calculate from the first account
+	cp.Count = 1
+	n, stats, hash = calculateDB(quit, 0, queryDB, calcDB, ctx, calculateBlockHeight, testHash, header,
+		writeBatchCount, cp)
+	assert.Equal(t, expCount, n)
+	assert.Equal(t, expStats.Accounts, stats.Accounts)
+	assert.Equal(t, expHash, hash)
+
+	ctx.DB.deleteCalcCheckpoint()
+	assert.False(t, ctx.DB.hasCalcCheckpoint(calculateBlockHeight, testHash, header))
+}
+
+func TestDBCheckpoint_CalculatingHash(t *testing.T) {
+	ctx := initTest(1)
+	ctx.DB.setCalculatingBH(100, testHash)
+
+	// block hash of calculation is kept for reload of IISS data
+	ctx = reopenContext(ctx, 1)
+	defer finalizeTest(ctx)
+	assert.Equal(t, uint64(100), ctx.DB.getCalculatingBH())
+	assert.Equal(t, testHash, ctx.DB.getCalculatingHash())
+
+	ctx.DB.resetCalculatingBH()
+	assert.Nil(t, ctx.DB.getCalculatingHash())
+}
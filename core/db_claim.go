@@ -20,7 +20,6 @@ const (
 	PreCommitIDSize = BlockHeightSize + BlockHashSize + common.AddressBytes
 
 	ClaimBackupIDSize = BlockHeightSize + common.AddressBytes
-	ClaimBackupPeriod = 43120*2 - 1
 )
 
 type ClaimData struct {
@@ -371,8 +370,10 @@ func findPreCommit(pcDB db.Database, address common.Address, blockHeight uint64,
 	return pc, nil
 }
 
+// writePreCommitToClaimDB writes claims of the block to claim DB and their old values to claim backup DB.
+// Claim backup DB keeps old values after rollbackLimit for rollback of account DB and for backupPeriod blocks.
 func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claimBackupDB db.Database,
-	blockHeight uint64, blockHash []byte) error {
+	blockHeight uint64, blockHash []byte, rollbackLimit uint64, backupPeriod uint64) error {
 	iter, err := preCommitDB.GetIterator()
 	if err != nil {
 		return err
@@ -433,7 +434,7 @@ func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claim
 		return err
 	}
 
-	err = writeClaimBackupInfo(claimBackupDB, blockHeight)
+	err = writeClaimBackupInfo(claimBackupDB, blockHeight, rollbackLimit, backupPeriod)
 	if err != nil {
 		return err
 	}
@@ -442,7 +443,8 @@ func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claim
 	return flushPreCommit(preCommitDB, blockHeight, nil)
 }
 
-func writeClaimBackupInfo(claimBackupDB db.Database, blockHeight uint64) error {
+func writeClaimBackupInfo(claimBackupDB db.Database, blockHeight uint64, rollbackLimit uint64,
+	backupPeriod uint64) error {
 	var cbInfo ClaimBackupInfo
 	cbBucket, _ := claimBackupDB.GetBucket(db.PrefixManagement)
 	bs, err := cbBucket.Get(cbInfo.ID())
@@ -462,16 +464,19 @@ func writeClaimBackupInfo(claimBackupDB db.Database, blockHeight uint64) error {
 		cbInfo.LastBlockHeight = blockHeight
 	}
 
-	// do garbage collection of claim backup DB
-	if blockHeight > ClaimBackupPeriod+cbInfo.FirstBlockHeight {
-		garbageBlock := blockHeight - ClaimBackupPeriod - 1
-
-		err = garbageCollectClaimBackupDB(claimBackupDB, cbInfo.FirstBlockHeight, garbageBlock)
-		if err != nil {
-			return err
+	// do garbage collection of claim backup DB. keep backup while account DB can be rolled back
+	if blockHeight > backupPeriod+cbInfo.FirstBlockHeight {
+		garbageBlock := blockHeight - backupPeriod - 1
+		if garbageBlock > rollbackLimit {
+			garbageBlock = rollbackLimit
 		}
-		// set first block height
-		if cbInfo.FirstBlockHeight < garbageBlock+1 {
+
+		if cbInfo.FirstBlockHeight <= garbageBlock {
+			err = garbageCollectClaimBackupDB(claimBackupDB, cbInfo.FirstBlockHeight, garbageBlock)
+			if err != nil {
+				return err
+			}
+			// set first block height
 			cbInfo.FirstBlockHeight = garbageBlock + 1
 		}
 	}
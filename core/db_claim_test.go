@@ -304,7 +304,7 @@ func TestDBPreCommit_manage(t *testing.T) {
 	// write to claim DB with commit
 	cDB := ctx.DB.getClaimDB()
 	assert.NoError(t, writePreCommitToClaimDB(pcDB, cDB, ctx.DB.getClaimBackupDB(),
-		tests[0].blockHeight, tests[0].hash))
+		tests[0].blockHeight, tests[0].hash, ctx.DB.getRollbackLimitBH(), ctx.network.ClaimBackupPeriod))
 
 	// can't query commited preCommit data
 	pc := newPreCommit(tests[0].blockHeight, tests[0].hash, tests[0].txIndex, tests[0].hash, *tests[0].address)
@@ -474,10 +474,11 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	const (
 		blockHeight uint64 = 100
 	)
+	rollbackLimit := blockHeight + testNetwork.ClaimBackupPeriod
 
 	cbDB := ctx.DB.getClaimBackupDB()
 
-	err := writeClaimBackupInfo(cbDB, blockHeight)
+	err := writeClaimBackupInfo(cbDB, blockHeight, rollbackLimit, testNetwork.ClaimBackupPeriod)
 	assert.NoError(t, err)
 
 	var cbInfo ClaimBackupInfo
@@ -491,7 +492,7 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	assert.Equal(t, blockHeight, cbInfo.LastBlockHeight)
 
 	// write invalid blockHeight
-	err = writeClaimBackupInfo(cbDB, blockHeight - 10)
+	err = writeClaimBackupInfo(cbDB, blockHeight - 10, rollbackLimit, testNetwork.ClaimBackupPeriod)
 	assert.NoError(t, err)
 	bs, err = cbBucket.Get(cbInfo.ID())
 	assert.NotNil(t, bs)
@@ -502,7 +503,7 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	assert.Equal(t, blockHeight, cbInfo.LastBlockHeight)
 
 	// write valid blockHeight
-	err = writeClaimBackupInfo(cbDB, blockHeight + 1)
+	err = writeClaimBackupInfo(cbDB, blockHeight + 1, rollbackLimit, testNetwork.ClaimBackupPeriod)
 	assert.NoError(t, err)
 	bs, err = cbBucket.Get(cbInfo.ID())
 	assert.NotNil(t, bs)
@@ -513,7 +514,7 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	assert.Equal(t, blockHeight + 1, cbInfo.LastBlockHeight)
 
 	// write valid blockHeight
-	err = writeClaimBackupInfo(cbDB, blockHeight + ClaimBackupPeriod + 1)
+	err = writeClaimBackupInfo(cbDB, blockHeight + testNetwork.ClaimBackupPeriod + 1, rollbackLimit, testNetwork.ClaimBackupPeriod)
 	assert.NoError(t, err)
 	bs, err = cbBucket.Get(cbInfo.ID())
 	assert.NotNil(t, bs)
@@ -521,7 +522,17 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	err = cbInfo.SetBytes(bs)
 	assert.NoError(t, err)
 	assert.Equal(t, blockHeight + 1, cbInfo.FirstBlockHeight)
-	assert.Equal(t, blockHeight + ClaimBackupPeriod + 1, cbInfo.LastBlockHeight)
+	assert.Equal(t, blockHeight + testNetwork.ClaimBackupPeriod + 1, cbInfo.LastBlockHeight)
+
+	// keep backup after rollback limit of account DB
+	err = writeClaimBackupInfo(cbDB, blockHeight + testNetwork.ClaimBackupPeriod + 10, blockHeight + 5, testNetwork.ClaimBackupPeriod)
+	assert.NoError(t, err)
+	bs, err = cbBucket.Get(cbInfo.ID())
+	assert.NoError(t, err)
+	err = cbInfo.SetBytes(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, blockHeight + 6, cbInfo.FirstBlockHeight)
+	assert.Equal(t, blockHeight + testNetwork.ClaimBackupPeriod + 10, cbInfo.LastBlockHeight)
 }
 
 func Test_garbageCollectClaimBackupDB(t *testing.T) {
@@ -0,0 +1,424 @@
+package core
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+const (
+	ExportFormatJSONL = "jsonl"
+	ExportFormatCSV   = "csv"
+
+	ExportAccountQuery     = "query"
+	ExportAccountCalculate = "calculate"
+)
+
+var exportCSVHeader = []string{"address", "iscore", "claimed_iscore", "claim_block_height", "block_height", "delegations"}
+
+// ExportAccount is an I-Score account in export file.
+// IScore is the claimable I-Score which is the I-Score of account minus ClaimedIScore like QUERY.
+type ExportAccount struct {
+	Address          common.Address
+	IScore           common.HexInt
+	ClaimedIScore    common.HexInt
+	ClaimBlockHeight uint64
+	BlockHeight      uint64
+	Delegations      []*DelegateData
+}
+
+func (ea *ExportAccount) String() string {
+	b, err := json.Marshal(ea)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func newExportAccount(address common.Address, accountBytes []byte, claimBytes []byte) (*ExportAccount, error) {
+	ia, err := NewIScoreAccountFromBytes(accountBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ea := new(ExportAccount)
+	ea.Address = address
+	ea.IScore.Set(&ia.IScore.Int)
+	ea.BlockHeight = ia.BlockHeight
+	ea.Delegations = ia.Delegations
+	if ea.Delegations == nil {
+		ea.Delegations = make([]*DelegateData, 0)
+	}
+
+	if claimBytes != nil {
+		// subtract claimed I-Score
+		claim, err := NewClaimFromBytes(claimBytes)
+		if err != nil {
+			return nil, err
+		}
+		ea.IScore.Sub(&ea.IScore.Int, &claim.Data.IScore.Int)
+		ea.ClaimedIScore.Set(&claim.Data.IScore.Int)
+		ea.ClaimBlockHeight = claim.Data.BlockHeight
+	}
+	return ea, nil
+}
+
+// AccountWriter writes ExportAccount to export file
+type AccountWriter interface {
+	Write(account *ExportAccount) error
+	Flush() error
+}
+
+// AccountReader reads ExportAccount from export file. It returns io.EOF after the last account.
+type AccountReader interface {
+	Read() (*ExportAccount, error)
+}
+
+func NewAccountWriter(format string, w io.Writer) (AccountWriter, error) {
+	switch format {
+	case ExportFormatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlAccountWriter{w: bw, encoder: json.NewEncoder(bw)}, nil
+	case ExportFormatCSV:
+		return &csvAccountWriter{w: csv.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("invalid export format %s. %s or %s", format, ExportFormatJSONL, ExportFormatCSV)
+	}
+}
+
+func NewAccountReader(format string, r io.Reader) (AccountReader, error) {
+	switch format {
+	case ExportFormatJSONL:
+		return &jsonlAccountReader{decoder: json.NewDecoder(bufio.NewReader(r))}, nil
+	case ExportFormatCSV:
+		return &csvAccountReader{r: csv.NewReader(bufio.NewReader(r))}, nil
+	default:
+		return nil, fmt.Errorf("invalid export format %s. %s or %s", format, ExportFormatJSONL, ExportFormatCSV)
+	}
+}
+
+// jsonlAccountWriter writes an account as a JSON object per line
+type jsonlAccountWriter struct {
+	w       *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (jw *jsonlAccountWriter) Write(account *ExportAccount) error {
+	return jw.encoder.Encode(account)
+}
+
+func (jw *jsonlAccountWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+type jsonlAccountReader struct {
+	decoder *json.Decoder
+}
+
+func (jr *jsonlAccountReader) Read() (*ExportAccount, error) {
+	account := new(ExportAccount)
+	if err := jr.decoder.Decode(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// csvAccountWriter writes an account as a CSV record with exportCSVHeader.
+// Numbers are written in decimal and delegations are written as "address:amount" separated by ';'.
+type csvAccountWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (cw *csvAccountWriter) writeHeader() error {
+	if cw.wroteHeader {
+		return nil
+	}
+	cw.wroteHeader = true
+	return cw.w.Write(exportCSVHeader)
+}
+
+func (cw *csvAccountWriter) Write(account *ExportAccount) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	delegations := make([]string, len(account.Delegations))
+	for i, dg := range account.Delegations {
+		delegations[i] = dg.Address.String() + ":" + dg.Delegate.Int.String()
+	}
+	return cw.w.Write([]string{
+		account.Address.String(),
+		account.IScore.Int.String(),
+		account.ClaimedIScore.Int.String(),
+		strconv.FormatUint(account.ClaimBlockHeight, 10),
+		strconv.FormatUint(account.BlockHeight, 10),
+		strings.Join(delegations, ";"),
+	})
+}
+
+func (cw *csvAccountWriter) Flush() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type csvAccountReader struct {
+	r          *csv.Reader
+	readHeader bool
+}
+
+func (cr *csvAccountReader) Read() (*ExportAccount, error) {
+	if !cr.readHeader {
+		header, err := cr.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		if strings.Join(header, ",") != strings.Join(exportCSVHeader, ",") {
+			return nil, fmt.Errorf("invalid CSV header %v", header)
+		}
+		cr.readHeader = true
+	}
+
+	record, err := cr.r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	account := new(ExportAccount)
+	if err = account.Address.SetString(record[0]); err != nil {
+		return nil, err
+	}
+	if err = setDecimal(&account.IScore, record[1]); err != nil {
+		return nil, err
+	}
+	if err = setDecimal(&account.ClaimedIScore, record[2]); err != nil {
+		return nil, err
+	}
+	if account.ClaimBlockHeight, err = strconv.ParseUint(record[3], 10, 64); err != nil {
+		return nil, err
+	}
+	if account.BlockHeight, err = strconv.ParseUint(record[4], 10, 64); err != nil {
+		return nil, err
+	}
+	account.Delegations = make([]*DelegateData, 0)
+	if len(record[5]) != 0 {
+		for _, s := range strings.Split(record[5], ";") {
+			fields := strings.Split(s, ":")
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("invalid delegation %s of %s", s, record[0])
+			}
+			dg := new(DelegateData)
+			if err = dg.Address.SetString(fields[0]); err != nil {
+				return nil, err
+			}
+			if err = setDecimal(&dg.Delegate, fields[1]); err != nil {
+				return nil, err
+			}
+			account.Delegations = append(account.Delegations, dg)
+		}
+	}
+	return account, nil
+}
+
+func setDecimal(i *common.HexInt, s string) error {
+	if _, ok := i.Int.SetString(s, 10); !ok {
+		return fmt.Errorf("invalid number %s", s)
+	}
+	return nil
+}
+
+// ExportAccounts writes all accounts in query or calculate account DBs to w in address order.
+// Reward Calculator must not be running.
+func ExportAccounts(dbPath string, dbType string, dbName string, accountType string, w AccountWriter) (uint64, error) {
+	dbRoot := filepath.Join(dbPath, dbName)
+	if _, err := os.Stat(dbRoot); err != nil {
+		return 0, fmt.Errorf("can't find I-Score DB. %v", err)
+	}
+
+	idb := new(IScoreDB)
+	var err error
+	idb.management, err = openManagementDB(dbPath, dbType, dbName)
+	if err != nil {
+		return 0, err
+	}
+	defer idb.management.Close()
+
+	// read DB information without writing new one
+	bucket, _ := idb.management.GetBucket(db.PrefixManagement)
+	idb.info = new(DBInfo)
+	bs, err := bucket.Get(idb.info.ID())
+	if err != nil || bs == nil {
+		return 0, fmt.Errorf("can't read DB information of %s. %v", dbRoot, err)
+	}
+	if err = idb.info.SetBytes(bs); err != nil {
+		return 0, err
+	}
+	if len(idb.info.DBType) > 0 && idb.info.DBType != dbType {
+		return 0, fmt.Errorf("I-Score DB was made with %s. can't open it with %s", idb.info.DBType, dbType)
+	}
+
+	j, err := idb.getJournal()
+	if err != nil {
+		return 0, err
+	}
+	if j != nil {
+		return 0, fmt.Errorf("%s is not finished. Run icon_rc to recover it first", journalToString(j.Op))
+	}
+
+	var postFix int
+	switch accountType {
+	case ExportAccountQuery:
+		if !idb.info.QueryDBIsZero {
+			postFix = 1
+		}
+	case ExportAccountCalculate:
+		if idb.info.QueryDBIsZero {
+			postFix = 1
+		}
+	default:
+		return 0, fmt.Errorf("invalid account type %s. %s or %s",
+			accountType, ExportAccountQuery, ExportAccountCalculate)
+	}
+
+	names := make([]string, idb.info.DBCount)
+	for i := range names {
+		names[i] = fmt.Sprintf(AccountDBNameFormat, i+1, idb.info.DBCount, postFix)
+	}
+	accountDBs := openAccountDBList(dbRoot, dbType, names)
+	defer closeAccountDBList(accountDBs)
+
+	claimDB := db.Open(dbRoot, dbType, "claim")
+	defer claimDB.Close()
+	claimBucket, _ := claimDB.GetBucket(db.PrefixIScore)
+
+	var count uint64
+	err = mergeAccountDBs(accountDBs, func(key []byte, value []byte) error {
+		address := common.NewAddress(key[len(db.PrefixIScore):])
+		claimBytes, _ := claimBucket.Get(address.Bytes())
+		account, err := newExportAccount(*address, value, claimBytes)
+		if err != nil {
+			return err
+		}
+		count++
+		return w.Write(account)
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		log.Printf("Failed to export accounts. %v", err)
+		return count, err
+	}
+	return count, nil
+}
+
+// ImportAccounts writes accounts from r to new I-Score DB with dbCount account DBs.
+// I-Score of account is IScore + ClaimedIScore and claimed I-Score is written to claim DB,
+// so QUERY returns IScore of exported account. Other data like governance variables are not imported.
+func ImportAccounts(dbPath string, dbType string, dbName string, dbCount int, r AccountReader) (uint64, error) {
+	if dbCount <= 0 || dbCount > MaxDBCount {
+		return 0, fmt.Errorf("invalid DBCount %d. MIN: 1, MAX: %d", dbCount, MaxDBCount)
+	}
+	dbRoot := filepath.Join(dbPath, dbName)
+	if _, err := os.Stat(dbRoot); err == nil {
+		return 0, fmt.Errorf("%s already exists. Import to new DB root", dbRoot)
+	}
+
+	idb := new(IScoreDB)
+	idb.management = db.Open(dbPath, dbType, dbName)
+	defer idb.management.Close()
+
+	var err error
+	idb.info, err = NewDBInfo(idb.management, dbPath, dbType, dbName, dbCount)
+	if err != nil {
+		return 0, err
+	}
+
+	idb.OpenAccountDB()
+	defer idb.CloseAccountDB()
+	claimDB := db.Open(dbRoot, dbType, "claim")
+	defer claimDB.Close()
+
+	// write the same accounts to query and calculate DBs
+	dbs := append(append([]db.Database{}, idb.Account0...), idb.Account1...)
+	dbs = append(dbs, claimDB)
+	batches := make([]db.Batch, len(dbs))
+	for i, aDB := range dbs {
+		batches[i], _ = aDB.GetBatch()
+		batches[i].New()
+	}
+	claimBatch := batches[len(batches)-1]
+
+	write := func(batch db.Batch, key []byte, value []byte) error {
+		batch.Set(key, value)
+		if batch.Len() >= accountBatchCount {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	}
+
+	var count uint64
+	for {
+		account, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("Failed to read account. %v", err)
+			return count, err
+		}
+		if account.IScore.Sign() < 0 || account.ClaimedIScore.Sign() < 0 {
+			return count, fmt.Errorf("invalid I-Score of account. %s", account.String())
+		}
+
+		ia := new(IScoreAccount)
+		ia.Address = account.Address
+		ia.IScore.Add(&account.IScore.Int, &account.ClaimedIScore.Int)
+		ia.BlockHeight = account.BlockHeight
+		ia.Delegations = account.Delegations
+
+		key := append([]byte(db.PrefixIScore), ia.ID()...)
+		index := idb.getAccountDBIndex(ia.Address)
+		if err = write(batches[index], key, ia.Bytes()); err != nil {
+			return count, err
+		}
+		if err = write(batches[dbCount+index], key, ia.Bytes()); err != nil {
+			return count, err
+		}
+
+		if account.ClaimedIScore.Sign() > 0 {
+			claim := new(Claim)
+			claim.Address = account.Address
+			claim.Data.BlockHeight = account.ClaimBlockHeight
+			claim.Data.IScore.Set(&account.ClaimedIScore.Int)
+			if err = write(claimBatch, append([]byte(db.PrefixIScore), claim.ID()...), claim.Bytes()); err != nil {
+				return count, err
+			}
+		}
+		count++
+	}
+
+	for _, batch := range batches {
+		if err = batch.Write(); err != nil {
+			return count, err
+		}
+		batch.Reset()
+	}
+	return count, nil
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBExport_ExportImportAccounts(t *testing.T) {
+	const count = 10
+	ctx := initTest(3)
+	addresses := writeTestAccounts(makeTestAccounts(count), ctx.DB.getQueryDB)
+
+	// claimed accounts
+	bucket, _ := ctx.DB.getClaimDB().GetBucket(db.PrefixIScore)
+	for i := 0; i < count; i += 3 {
+		var claim Claim
+		claim.Address = addresses[i]
+		claim.Data.BlockHeight = 101
+		claim.Data.IScore.SetUint64(testNetwork.ClaimMinIScore)
+		bucket.Set(claim.ID(), claim.Bytes())
+	}
+	expected := make([]*ResponseQuery, count)
+	for i, address := range addresses {
+		expected[i] = DoQuery(ctx, &Query{Address: address})
+	}
+	CloseIScoreDB(ctx.DB)
+
+	for i, format := range []string{ExportFormatJSONL, ExportFormatCSV} {
+		var buf bytes.Buffer
+		w, err := NewAccountWriter(format, &buf)
+		assert.NoError(t, err)
+		n, err := ExportAccounts(testDir, testDBType, "test", ExportAccountQuery, w)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(count), n)
+
+		// import to new DB root with different DB count
+		dbName := fmt.Sprintf("import_%d", i)
+		r, err := NewAccountReader(format, strings.NewReader(buf.String()))
+		assert.NoError(t, err)
+		n, err = ImportAccounts(testDir, testDBType, dbName, 2, r)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(count), n)
+
+		// import to existing DB root
+		r, _ = NewAccountReader(format, strings.NewReader(buf.String()))
+		_, err = ImportAccounts(testDir, testDBType, dbName, 2, r)
+		assert.Error(t, err)
+
+		iCtx, err := NewContext(testDir, testDBType, dbName, 2, "debugConfigPath", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, iCtx.DB.info.DBCount)
+		for j, address := range addresses {
+			resp := DoQuery(iCtx, &Query{Address: address})
+			assert.Equal(t, expected[j].IScore.Int64(), resp.IScore.Int64())
+			assert.Equal(t, expected[j].BlockHeight, resp.BlockHeight)
+		}
+
+		// export of imported DB is the same
+		var buf2 bytes.Buffer
+		CloseIScoreDB(iCtx.DB)
+		w, _ = NewAccountWriter(format, &buf2)
+		_, err = ExportAccounts(testDir, testDBType, dbName, ExportAccountCalculate, w)
+		assert.NoError(t, err)
+		assert.Equal(t, buf.String(), buf2.String())
+	}
+
+	// empty calculate DB
+	var buf bytes.Buffer
+	w, _ := NewAccountWriter(ExportFormatCSV, &buf)
+	n, err := ExportAccounts(testDir, testDBType, "test", ExportAccountCalculate, w)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), n)
+	assert.Equal(t, strings.Join(exportCSVHeader, ",")+"\n", buf.String())
+
+	// invalid input
+	_, err = ExportAccounts(testDir, testDBType, "test", "unknown", w)
+	assert.Error(t, err)
+	_, err = ExportAccounts(testDir, testDBType, "unknown", ExportAccountQuery, w)
+	assert.Error(t, err)
+	_, err = NewAccountWriter("xml", &buf)
+	assert.Error(t, err)
+
+	ctx, _ = NewContext(testDir, testDBType, "test", 3, "debugConfigPath", nil)
+	finalizeTest(ctx)
+}
@@ -28,6 +28,9 @@ type IISSHeader struct {
 	Version     uint64		// version of RC data
 	BlockHeight uint64
 	Revision    uint64		// revision of ICON Service
+
+	// block height from which Revision applies in the term. Revision applies to the whole term with 0
+	RevisionBlockHeight uint64
 }
 
 func (ih *IISSHeader) ID() []byte {
@@ -115,8 +115,8 @@ func makeIISSGV() *IISSGovernanceVariable {
 	gv := new(IISSGovernanceVariable)
 
 	gv.BlockHeight = iaBlockHeight
-	gv.MainPRepCount = NumMainPRep
-	gv.SubPRepCount = NumSubPRep
+	gv.MainPRepCount = testNetwork.NumMainPRep
+	gv.SubPRepCount = testNetwork.NumSubPRep
 	gv.IncentiveRep = gvIncentiveRep
 	gv.RewardRep = gvRewardRep
 
@@ -324,7 +324,7 @@ func addDelegationData(address common.Address, amount uint64) []interface{} {
 
 func TestDBIISSTX_ID(t *testing.T) {
 	dgDataSlice := []DelegateData {
-		{*common.NewAddressFromString(delegationAddress), *common.NewHexIntFromUint64(MinDelegation)},
+		{*common.NewAddressFromString(delegationAddress), *common.NewHexIntFromUint64(testNetwork.MinDelegation())},
 	}
 	tx := makeIISSTX(TXDataTypeDelegate, genAddress, dgDataSlice)
 
@@ -336,7 +336,7 @@ func TestDBIISSTX_ID(t *testing.T) {
 
 func TestDBIISSTX_BytesAndSetBytes(t *testing.T) {
 	dgDataSlice := []DelegateData {
-		{*common.NewAddressFromString(delegationAddress), *common.NewHexIntFromUint64(MinDelegation)},
+		{*common.NewAddressFromString(delegationAddress), *common.NewHexIntFromUint64(testNetwork.MinDelegation())},
 	}
 	tx := makeIISSTX(TXDataTypeDelegate, genAddress, dgDataSlice)
 
@@ -365,7 +365,7 @@ func writeIISSTX(dbDir string, dbName string) ([]*IISSTX, db.Database) {
 	txList := make([]*IISSTX, 0)
 
 	dgDataSlice := []DelegateData {
-		{*common.NewAddressFromString(delegationAddress), *common.NewHexIntFromUint64(MinDelegation)},
+		{*common.NewAddressFromString(delegationAddress), *common.NewHexIntFromUint64(testNetwork.MinDelegation())},
 	}
 	tx := makeIISSTX(TXDataTypeDelegate, genAddress, dgDataSlice)
 	txList = append(txList, tx)
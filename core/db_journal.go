@@ -0,0 +1,206 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+// Multi-step operations on account DB files
+const (
+	JournalNone         = iota
+	JournalBackup       // backup old query DB and open new calculate DB. resetAccountDB()
+	JournalDeleteBackup // delete old backup account DB. deleteOldBackupAccountDB()
+	JournalRollback     // restore backup account DB. rollbackAccountDB()
+	JournalReshard      // replace account DBs with new DB count. ReshardAccountDB()
+)
+
+type JournalData struct {
+	Op          int
+	BlockHeight uint64 // block height of calculation. block height of rollback for JournalRollback
+	Step        uint64 // block height of calculation whose backup account DB is being restored. new DB count for JournalReshard
+}
+
+// Journal is an intent of multi-step operation on account DB files.
+// It is written to management DB before the operation and deleted after the operation.
+type Journal struct {
+	JournalData
+}
+
+func (j *Journal) ID() []byte {
+	return []byte("")
+}
+
+func (j *Journal) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&j.JournalData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (j *Journal) String() string {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (j *Journal) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &j.JournalData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func journalToString(op int) string {
+	switch op {
+	case JournalBackup:
+		return "BACKUP"
+	case JournalDeleteBackup:
+		return "DELETE_BACKUP"
+	case JournalRollback:
+		return "ROLLBACK"
+	case JournalReshard:
+		return "RESHARD"
+	default:
+		return "NONE"
+	}
+}
+
+func newJournal(op int, blockHeight uint64) *Journal {
+	return &Journal{JournalData{Op: op, BlockHeight: blockHeight}}
+}
+
+func journalKey(j *Journal) []byte {
+	return append([]byte(db.PrefixJournal), j.ID()...)
+}
+
+func dbInfoKey(dbi *DBInfo) []byte {
+	return append([]byte(db.PrefixManagement), dbi.ID()...)
+}
+
+func (idb *IScoreDB) getJournal() (*Journal, error) {
+	bucket, _ := idb.management.GetBucket(db.PrefixJournal)
+	j := new(Journal)
+	bs, err := bucket.Get(j.ID())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	if err = j.SetBytes(bs); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (idb *IScoreDB) writeJournal(j *Journal) error {
+	bucket, _ := idb.management.GetBucket(db.PrefixJournal)
+	value, _ := j.Bytes()
+	if err := bucket.Set(j.ID(), value); err != nil {
+		log.Printf("Failed to write journal %s. %v", j.String(), err)
+		return err
+	}
+	return nil
+}
+
+func (idb *IScoreDB) deleteJournal() error {
+	bucket, _ := idb.management.GetBucket(db.PrefixJournal)
+	if err := bucket.Delete(new(Journal).ID()); err != nil {
+		log.Printf("Failed to delete journal. %v", err)
+		return err
+	}
+	return nil
+}
+
+// writeToDBWithJournal writes DB information and journal at once. Delete journal if j is nil
+func (idb *IScoreDB) writeToDBWithJournal(j *Journal) error {
+	batch, _ := idb.management.GetBatch()
+	batch.New()
+
+	value, _ := idb.info.Bytes()
+	batch.Set(dbInfoKey(idb.info), value)
+	if j != nil {
+		value, _ = j.Bytes()
+		batch.Set(journalKey(j), value)
+	} else {
+		batch.Delete(journalKey(new(Journal)))
+	}
+
+	if err := batch.Write(); err != nil {
+		log.Printf("Failed to write DB information with journal. %v", err)
+		return err
+	}
+	batch.Reset()
+	return nil
+}
+
+// recoverJournal completes the operation which was stopped by crash
+func (idb *IScoreDB) recoverJournal() error {
+	j, err := idb.getJournal()
+	if err != nil {
+		log.Printf("Failed to read journal. %v", err)
+		return err
+	}
+	if j == nil {
+		return nil
+	}
+
+	log.Printf("Recover %s. %s", journalToString(j.Op), j.String())
+	switch j.Op {
+	case JournalBackup:
+		// toggle may not be written to DB
+		if err = idb.toggleAccountDB(j.BlockHeight + 1); err == nil {
+			err = idb.resetAccountDB(j.BlockHeight)
+		}
+	case JournalDeleteBackup:
+		err = idb.deleteBackupAccountDB(j.BlockHeight)
+	case JournalRollback:
+		err = idb.doRollbackAccountDB(j)
+	case JournalReshard:
+		idb.CloseAccountDB()
+		err = idb.finishReshardAccountDB(j)
+		idb.OpenAccountDB()
+	default:
+		err = fmt.Errorf("invalid journal %s", j.String())
+	}
+	if err != nil {
+		log.Printf("Failed to recover %s. %v", journalToString(j.Op), err)
+		return err
+	}
+
+	log.Printf("Recovered %s. %d", journalToString(j.Op), j.BlockHeight)
+	return nil
+}
+
+// deleteBackupAccountDB deletes backup account DB of the calculation
+func (idb *IScoreDB) deleteBackupAccountDB(blockHeight uint64) error {
+	if err := idb.writeJournal(newJournal(JournalDeleteBackup, blockHeight)); err != nil {
+		return err
+	}
+
+	oldBackup := idb.backupAccountDBPattern(blockHeight)
+	oldBackups, err := filepath.Glob(oldBackup)
+	if err != nil {
+		log.Printf("Failed to get old backup account DB %s. %v", oldBackup, err)
+		return err
+	}
+	log.Printf("delete old backup %d account DBs. %s", len(oldBackups), oldBackup)
+	for _, f := range oldBackups {
+		err = os.RemoveAll(f)
+		if err != nil {
+			log.Printf("Failed to delete old backup account DB %s. %v", f, err)
+			return err
+		}
+	}
+
+	return idb.deleteJournal()
+}
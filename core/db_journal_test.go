@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reopenContext closes DB and opens again like restarting Reward Calculator
+func reopenContext(ctx *Context, dbCount int) *Context {
+	CloseIScoreDB(ctx.DB)
+	newCtx, err := NewContext(testDir, testDBType, "test", dbCount, "debugConfigPath", nil)
+	if err != nil {
+		panic(err)
+	}
+	return newCtx
+}
+
+func TestDBJournal_Bytes(t *testing.T) {
+	j := newJournal(JournalRollback, 100)
+	j.Step = 200
+
+	bs, err := j.Bytes()
+	assert.NoError(t, err)
+
+	j2 := new(Journal)
+	err = j2.SetBytes(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, j.JournalData, j2.JournalData)
+}
+
+func TestDBJournal_writeToDBWithJournal(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	j := newJournal(JournalRollback, 100)
+	ctx.DB.info.CalcDone = 50
+	assert.NoError(t, ctx.DB.writeToDBWithJournal(j))
+
+	j2, err := ctx.DB.getJournal()
+	assert.NoError(t, err)
+	assert.Equal(t, j.JournalData, j2.JournalData)
+
+	dbInfo, err := NewDBInfo(ctx.DB.management, testDir, testDBType, "test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50), dbInfo.CalcDone)
+
+	// delete journal
+	assert.NoError(t, ctx.DB.writeToDBWithJournal(nil))
+	j2, err = ctx.DB.getJournal()
+	assert.NoError(t, err)
+	assert.Nil(t, j2)
+}
+
+func TestDBJournal_recoverBackup(t *testing.T) {
+	const dbCount = 2
+	ctx := initTest(dbCount)
+	ia := makeIA()
+	emulateCalculation(ctx, ia, 10, true)
+
+	// stopped after toggle. toggle is written with journal of backup
+	const blockHeight uint64 = 20
+	ctx.DB.setCalculatingBH(blockHeight, nil)
+	assert.NoError(t, ctx.DB.toggleAccountDB(blockHeight+1))
+
+	ctx = reopenContext(ctx, dbCount)
+	defer finalizeTest(ctx)
+
+	j, err := ctx.DB.getJournal()
+	assert.NoError(t, err)
+	assert.Nil(t, j)
+
+	for i := 0; i < dbCount; i++ {
+		backupName := fmt.Sprintf(BackupDBNameFormat, blockHeight, i+1)
+		stat, err := os.Stat(filepath.Join(ctx.DB.info.DBRoot, backupName))
+		assert.NoError(t, err)
+		assert.True(t, stat.IsDir())
+	}
+	assert.Equal(t, uint64(10), readIScore(t, ctx.DB.getQueryDB(ia.Address), ia))
+	assert.Equal(t, uint64(0), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+}
+
+func TestDBJournal_setCalcDoneBH(t *testing.T) {
+	ctx := initTest(1)
+	assert.NoError(t, ctx.DB.setCalcDoneBH(10))
+	assert.NoError(t, ctx.DB.setCalcDoneBH(20))
+
+	// block height and calculation history are written together
+	ctx = reopenContext(ctx, 1)
+	defer finalizeTest(ctx)
+	assert.Equal(t, uint64(20), ctx.DB.getCalcDoneBH())
+	assert.Equal(t, uint64(10), ctx.DB.getPrevCalcDoneBH())
+	prev, ok := ctx.DB.getCalcHistory(20)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), prev)
+}
+
+func TestDBJournal_recoverRollback(t *testing.T) {
+	const dbCount = 2
+	ctx := initTest(dbCount)
+	ctx.DB.SetBackupCount(2)
+	ia := makeIA()
+	emulateCalculation(ctx, ia, 10, true)
+	emulateCalculation(ctx, ia, 20, true)
+	emulateCalculation(ctx, ia, 30, true)
+
+	// stopped while restoring backup account DB of 30
+	const rollbackBH uint64 = 11
+	j := newJournal(JournalRollback, rollbackBH)
+	j.Step = 30
+	ctx.DB.writeJournal(j)
+	ctx.DB.CloseAccountDB()
+	calcDBPostFix := 0
+	if ctx.DB.info.QueryDBIsZero {
+		calcDBPostFix = 1
+	}
+	calcDBName := fmt.Sprintf(AccountDBNameFormat, 1, dbCount, calcDBPostFix)
+	assert.NoError(t, os.RemoveAll(filepath.Join(ctx.DB.info.DBRoot, calcDBName)))
+	assert.NoError(t, os.Rename(filepath.Join(ctx.DB.info.DBRoot, fmt.Sprintf(BackupDBNameFormat, 30, 1)),
+		filepath.Join(ctx.DB.info.DBRoot, calcDBName)))
+	ctx.DB.OpenAccountDB()
+
+	ctx = reopenContext(ctx, dbCount)
+	defer finalizeTest(ctx)
+
+	j, err := ctx.DB.getJournal()
+	assert.NoError(t, err)
+	assert.Nil(t, j)
+
+	assert.Equal(t, uint64(10), ctx.DB.getCalcDoneBH())
+	assert.Equal(t, uint64(10), ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, rollbackBH, ctx.DB.info.ToggleBH)
+	assert.Equal(t, uint64(0), readIScore(t, ctx.DB.getQueryDB(ia.Address), ia))
+	assert.Equal(t, uint64(10), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+
+	bhList, err := ctx.DB.getBackupAccountDBList()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(bhList))
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
 	"math/big"
 	"path/filepath"
@@ -17,9 +18,6 @@ import (
 
 const (
 	MaxDBCount  int    = 256
-
-	NumMainPRep uint64 = 22
-	NumSubPRep  uint64 = 78
 )
 
 type DBInfoDataV1 struct {
@@ -56,11 +54,22 @@ type DBInfoDataV2 struct {
 	ToggleBH      uint64	// Latest account DB toggle block height
 }
 
-type DBInfoData DBInfoDataV2
+type DBInfoDataV3 struct {
+	DBCount       int
+	QueryDBIsZero bool
+	Current       BlockInfo // Latest COMMIT_BLOCK block height and hash
+	CalcDone      uint64    // Latest CALCULATE_DONE block height
+	PrevCalcDone  uint64    // Previous CALCULATE_DONE block height
+	Calculating   uint64    // Latest CALCULATE block height
+	ToggleBH      uint64    // Latest account DB toggle block height
+	DBType        string    // backend of DB. empty for DB made before V3
+	CalculatingHash []byte  // block hash of Calculating
+}
+
+type DBInfoData DBInfoDataV3
 
 type DBInfo struct {
 	DBRoot        string
-	DBType        string
 	DBInfoData
 }
 
@@ -126,6 +135,13 @@ func NewDBInfo(mngDB db.Database, dbPath string, dbType string, dbName string, d
 			log.Panicf("Failed to set DB Information structure\n")
 			return nil, err
 		}
+
+		// DB must be opened with the backend which made it
+		if len(dbInfo.DBType) == 0 {
+			writeToDB = true
+		} else if dbInfo.DBType != dbType {
+			return nil, fmt.Errorf("I-Score DB was made with %s. can't open it with %s", dbInfo.DBType, dbType)
+		}
 	} else {
 		// set DB count
 		dbInfo.DBCount = dbCount
@@ -145,9 +161,17 @@ func NewDBInfo(mngDB db.Database, dbPath string, dbType string, dbName string, d
 	return dbInfo, nil
 }
 
+// openManagementDB opens management DB of I-Score DB. Existing DB must be opened with the backend which made it.
+func openManagementDB(dbPath string, dbType string, dbName string) (db.Database, error) {
+	if backend, ok := db.DetectBackend(dbPath, dbName); ok && string(backend) != dbType {
+		return nil, fmt.Errorf("I-Score DB %s was made with %s. can't open it with %s",
+			filepath.Join(dbPath, dbName), backend, dbType)
+	}
+	return db.Open(dbPath, dbType, dbName), nil
+}
+
 var BigIntTwo = big.NewInt(2)
 var BigInt100 = big.NewInt(100)
-var BigIntIScoreMultiplier = big.NewInt(iScoreMultiplier)
 
 type GVData struct {
 	CalculatedIncentiveRep common.HexInt
@@ -194,22 +218,25 @@ func (gv *GovernanceVariable) SetBytes(bs []byte) error {
 	if err != nil {
 		return err
 	}
-	gv.setReward()
 	return nil
 }
 
-func (gv *GovernanceVariable) setReward() {
+// setReward sets rewards of GV with I-Score multiplier of np
+func (gv *GovernanceVariable) setReward(np *NetworkProfile) {
+	iScoreMultiplier := new(big.Int).SetUint64(np.IScoreMultiplier)
+
 	// block produce reward
 	gv.BlockProduceReward.Mul(&gv.CalculatedIncentiveRep.Int, &gv.MainPRepCount.Int)
-	gv.BlockProduceReward.Mul(&gv.BlockProduceReward.Int, BigIntIScoreMultiplier)
+	gv.BlockProduceReward.Mul(&gv.BlockProduceReward.Int, iScoreMultiplier)
 	gv.BlockProduceReward.Div(&gv.BlockProduceReward.Int, BigIntTwo)
 
 	// Main/Sub P-Rep reward
 	gv.PRepReward.Mul(&gv.CalculatedIncentiveRep.Int, BigInt100)
-	gv.PRepReward.Mul(&gv.PRepReward.Int, BigIntIScoreMultiplier)
+	gv.PRepReward.Mul(&gv.PRepReward.Int, iScoreMultiplier)
 }
 
-func LoadGovernanceVariable(dbi db.Database) ([]*GovernanceVariable, error) {
+// LoadGovernanceVariable reads GVs of management DB and sets their rewards with np
+func LoadGovernanceVariable(dbi db.Database, np *NetworkProfile) ([]*GovernanceVariable, error) {
 	gvList := make([]*GovernanceVariable, 0)
 
 	iter, err := dbi.GetIterator()
@@ -225,6 +252,7 @@ func LoadGovernanceVariable(dbi db.Database) ([]*GovernanceVariable, error) {
 		gvBlockHeight := common.BytesToUint64(iter.Key()[len(db.PrefixGovernanceVariable):])
 
 		gv.SetBytes(iter.Value())
+		gv.setReward(np)
 		gv.BlockHeight = gvBlockHeight
 		gvList = append(gvList, gv)
 	}
@@ -243,14 +271,14 @@ func LoadGovernanceVariable(dbi db.Database) ([]*GovernanceVariable, error) {
 	return gvList, nil
 }
 
-func NewGVFromIISS(iiss *IISSGovernanceVariable) *GovernanceVariable {
+func NewGVFromIISS(iiss *IISSGovernanceVariable, np *NetworkProfile) *GovernanceVariable {
 	gv := new(GovernanceVariable)
 	gv.BlockHeight = iiss.BlockHeight
 	gv.MainPRepCount.SetUint64(iiss.MainPRepCount)
 	gv.SubPRepCount.SetUint64(iiss.SubPRepCount)
 	gv.CalculatedIncentiveRep.SetUint64(iiss.IncentiveRep)
 	gv.RewardRep.SetUint64(iiss.RewardRep)
-	gv.setReward()
+	gv.setReward(np)
 
 	return gv
 }
@@ -94,16 +94,56 @@ func TestDBMNGDBInfo_NewDBInfo(t *testing.T) {
 	assert.Equal(t, dbInfo.Calculating, dbInfo1.Calculating)
 }
 
+func TestDBMNGDBInfo_DBType(t *testing.T) {
+	mngDB := db.Open(testDBDir, string(db.GoLevelDBBackend), testDB)
+	defer mngDB.Close()
+	defer os.RemoveAll(testDBDir)
+
+	// DB information without DB type
+	v2 := DBInfoDataV2{DBCount: 2, CalcDone: 100}
+	bs, _ := codec.MarshalToBytes(&v2)
+	bucket, _ := mngDB.GetBucket(db.PrefixManagement)
+	bucket.Set(new(DBInfo).ID(), bs)
+
+	dbInfo, err := NewDBInfo(mngDB, testDBDir, string(db.GoLevelDBBackend), testDB, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dbInfo.DBCount)
+	assert.Equal(t, uint64(100), dbInfo.CalcDone)
+	assert.Equal(t, string(db.GoLevelDBBackend), dbInfo.DBType)
+
+	// DB type is written
+	_, err = NewDBInfo(mngDB, testDBDir, string(db.BoltDBBackend), testDB, 1)
+	assert.Error(t, err)
+}
+
+func TestDBMNG_openManagementDB(t *testing.T) {
+	ctx := initTest(1)
+	CloseIScoreDB(ctx.DB)
+
+	// I-Score DB can't be opened with other backends
+	for _, backend := range db.GetBackendList() {
+		if backend == testDBType {
+			continue
+		}
+		_, err := NewContext(testDir, backend, "test", 1, "", nil)
+		assert.Error(t, err)
+	}
+
+	ctx, err := NewContext(testDir, testDBType, "test", 1, "", nil)
+	assert.NoError(t, err)
+	finalizeTest(ctx)
+}
+
 
 func makeGV(blockHeight uint64) *GovernanceVariable {
 	gv := new(GovernanceVariable)
 
 	gv.BlockHeight = blockHeight
-	gv.MainPRepCount.SetUint64(NumMainPRep)
-	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(1)
 	gv.RewardRep.SetUint64(2)
-	gv.setReward()
+	gv.setReward(testNetwork)
 
 	return gv
 }
@@ -124,6 +164,8 @@ func TestDBMNGGV_BytesAndSetBytes(t *testing.T) {
 
 	bs, _ := gv.Bytes()
 	gvNew.SetBytes(bs)
+	// rewards are set with network profile
+	gvNew.setReward(testNetwork)
 
 	assert.Equal(t, 0, gv.MainPRepCount.Cmp(&gvNew.MainPRepCount.Int))
 	assert.Equal(t, 0, gv.SubPRepCount.Cmp(&gvNew.SubPRepCount.Int))
@@ -155,7 +197,7 @@ func TestDBMNGGV_LoadGovernanceVariable(t *testing.T) {
 		bucket.Set(gv.ID(), bs)
 	}
 
-	gvListNew, err := LoadGovernanceVariable(mngDB)
+	gvListNew, err := LoadGovernanceVariable(mngDB, testNetwork)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(gvListNew))
@@ -167,7 +209,7 @@ func TestDBMNGGV_LoadGovernanceVariable(t *testing.T) {
 func TestDBMNGGV_NewGVFromIISS(t *testing.T) {
 	iissGV := makeIISSGV()
 
-	gv := NewGVFromIISS(iissGV)
+	gv := NewGVFromIISS(iissGV, testNetwork)
 
 	assert.Equal(t, iissGV.BlockHeight, gv.BlockHeight)
 	assert.Equal(t, iissGV.MainPRepCount, gv.MainPRepCount.Uint64())
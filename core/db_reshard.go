@@ -0,0 +1,357 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// ReshardDirName is the directory in DB root where new account DBs are written before replacing old ones
+	ReshardDirName = "reshard"
+
+	// the number of accounts in a batch to write account DB
+	accountBatchCount = 1000
+)
+
+// reshardSet is account DBs which have the same data with different DB count. ex) query DBs, backup DBs of a calculation
+type reshardSet struct {
+	src []string
+	dst []string
+}
+
+// ReshardAccountDB redistributes accounts in all account DBs(query, calculate and backup) to dbCount account DBs.
+// Reward Calculator must not be running.
+func ReshardAccountDB(dbPath string, dbType string, dbName string, dbCount int) error {
+	if dbCount <= 0 || dbCount > MaxDBCount {
+		return fmt.Errorf("invalid DBCount %d. MIN: 1, MAX: %d", dbCount, MaxDBCount)
+	}
+	if _, err := os.Stat(filepath.Join(dbPath, dbName)); err != nil {
+		return fmt.Errorf("can't find I-Score DB. %v", err)
+	}
+
+	idb := new(IScoreDB)
+	var err error
+	idb.management, err = openManagementDB(dbPath, dbType, dbName)
+	if err != nil {
+		return err
+	}
+	defer idb.management.Close()
+
+	idb.info, err = NewDBInfo(idb.management, dbPath, dbType, dbName, dbCount)
+	if err != nil {
+		return err
+	}
+
+	j, err := idb.getJournal()
+	if err != nil {
+		return err
+	}
+	if j != nil {
+		if j.Op != JournalReshard {
+			return fmt.Errorf("%s is not finished. Run icon_rc to recover it first", journalToString(j.Op))
+		}
+		log.Printf("Resume %s. %s", journalToString(j.Op), j.String())
+		return idb.finishReshardAccountDB(j)
+	}
+
+	if idb.info.DBCount == dbCount {
+		log.Printf("DBCount is already %d", dbCount)
+		return nil
+	}
+
+	if err = idb.buildReshardAccountDB(dbCount); err != nil {
+		return err
+	}
+
+	j = newJournal(JournalReshard, idb.getCalcDoneBH())
+	j.Step = uint64(dbCount)
+	if err = idb.writeJournal(j); err != nil {
+		return err
+	}
+	return idb.finishReshardAccountDB(j)
+}
+
+func (idb *IScoreDB) getReshardSets(dbCount int) ([]reshardSet, error) {
+	sets := make([]reshardSet, 0)
+
+	// query and calculate DBs
+	for postFix := 0; postFix < 2; postFix++ {
+		var set reshardSet
+		for i := 0; i < idb.info.DBCount; i++ {
+			set.src = append(set.src, fmt.Sprintf(AccountDBNameFormat, i+1, idb.info.DBCount, postFix))
+		}
+		for i := 0; i < dbCount; i++ {
+			set.dst = append(set.dst, fmt.Sprintf(AccountDBNameFormat, i+1, dbCount, postFix))
+		}
+		sets = append(sets, set)
+	}
+
+	// backup DBs
+	bhList, err := idb.getBackupAccountDBList()
+	if err != nil {
+		return nil, err
+	}
+	for _, bh := range bhList {
+		var set reshardSet
+		for i := 0; i < idb.info.DBCount; i++ {
+			name := fmt.Sprintf(BackupDBNameFormat, bh, i+1)
+			if _, err = os.Stat(filepath.Join(idb.info.DBRoot, name)); err != nil {
+				return nil, fmt.Errorf("backup account DB of %d is incomplete. %v", bh, err)
+			}
+			set.src = append(set.src, name)
+		}
+		for i := 0; i < dbCount; i++ {
+			set.dst = append(set.dst, fmt.Sprintf(BackupDBNameFormat, bh, i+1))
+		}
+		sets = append(sets, set)
+	}
+
+	return sets, nil
+}
+
+// buildReshardAccountDB writes new account DBs to ReshardDirName and verifies them with account state hash
+func (idb *IScoreDB) buildReshardAccountDB(dbCount int) error {
+	reshardRoot := filepath.Join(idb.info.DBRoot, ReshardDirName)
+	if err := os.RemoveAll(reshardRoot); err != nil {
+		log.Printf("Failed to remove old reshard directory. %v", err)
+		return err
+	}
+
+	sets, err := idb.getReshardSets(dbCount)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Reshard %d sets of account DBs. DBCount: %d -> %d", len(sets), idb.info.DBCount, dbCount)
+	for _, set := range sets {
+		srcDBs := openAccountDBList(idb.info.DBRoot, idb.info.DBType, set.src)
+		dstDBs := openAccountDBList(reshardRoot, idb.info.DBType, set.dst)
+
+		err = reshardAccounts(srcDBs, dstDBs)
+		if err == nil {
+			err = verifyReshardAccounts(srcDBs, dstDBs)
+		}
+
+		closeAccountDBList(srcDBs)
+		closeAccountDBList(dstDBs)
+		if err != nil {
+			log.Printf("Failed to reshard %s. %v", set.src[0], err)
+			return err
+		}
+	}
+	return nil
+}
+
+// finishReshardAccountDB replaces old account DBs with new ones in ReshardDirName and writes new DB count.
+// It can be called again if it was stopped.
+func (idb *IScoreDB) finishReshardAccountDB(j *Journal) error {
+	dbCount := int(j.Step)
+	reshardRoot := filepath.Join(idb.info.DBRoot, ReshardDirName)
+
+	// remove query and calculate DBs of old DB count
+	for postFix := 0; postFix < 2; postFix++ {
+		for i := 0; i < idb.info.DBCount; i++ {
+			name := fmt.Sprintf(AccountDBNameFormat, i+1, idb.info.DBCount, postFix)
+			if err := os.RemoveAll(filepath.Join(idb.info.DBRoot, name)); err != nil {
+				log.Printf("Failed to remove old account DB %s. %v", name, err)
+				return err
+			}
+		}
+	}
+
+	// move new account DBs
+	files, err := ioutil.ReadDir(reshardRoot)
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to read reshard directory. %v", err)
+		return err
+	}
+	for _, f := range files {
+		path := filepath.Join(idb.info.DBRoot, f.Name())
+		if err = os.RemoveAll(path); err != nil {
+			log.Printf("Failed to remove old account DB %s. %v", f.Name(), err)
+			return err
+		}
+		if err = os.Rename(filepath.Join(reshardRoot, f.Name()), path); err != nil {
+			log.Printf("Failed to move new account DB %s. %v", f.Name(), err)
+			return err
+		}
+	}
+
+	// remove backup DBs over new DB count
+	backups, err := filepath.Glob(filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+"*"))
+	if err != nil {
+		return err
+	}
+	for _, f := range backups {
+		var backupBH uint64
+		var index int
+		_, backupName := filepath.Split(f)
+		if n, _ := fmt.Sscanf(backupName, BackupDBNameFormat, &backupBH, &index); n == 2 && index > dbCount {
+			if err = os.RemoveAll(f); err != nil {
+				log.Printf("Failed to remove old backup account DB %s. %v", backupName, err)
+				return err
+			}
+		}
+	}
+
+	idb.info.DBCount = dbCount
+	if err = idb.writeToDBWithJournal(nil); err != nil {
+		return err
+	}
+	os.RemoveAll(reshardRoot)
+
+	log.Printf("Finish reshard. DBCount: %d", dbCount)
+	return nil
+}
+
+func openAccountDBList(dir string, dbType string, names []string) []db.Database {
+	dbs := make([]db.Database, len(names))
+	for i, name := range names {
+		dbs[i] = db.Open(dir, dbType, name)
+	}
+	return dbs
+}
+
+func closeAccountDBList(dbs []db.Database) {
+	for _, aDB := range dbs {
+		aDB.Close()
+	}
+}
+
+// reshardAccounts copies accounts in src DBs to dst DBs with the account DB index of len(dst) DBs
+func reshardAccounts(src []db.Database, dst []db.Database) error {
+	batches := make([]db.Batch, len(dst))
+	for i, dDB := range dst {
+		batches[i], _ = dDB.GetBatch()
+		batches[i].New()
+	}
+
+	prefix := util.BytesPrefix([]byte(db.PrefixIScore))
+	for _, sDB := range src {
+		iter, _ := sDB.GetIterator()
+		iter.New(nil, nil)
+		for iter.Next() {
+			key := iter.Key()
+			if !bytes.HasPrefix(key, prefix.Start) {
+				iter.Release()
+				return fmt.Errorf("invalid key in account DB. %s", hex.EncodeToString(key))
+			}
+			address := common.NewAddress(key[len(db.PrefixIScore):])
+			batch := batches[int(address.ID()[0])%len(dst)]
+			batch.Set(key, iter.Value())
+			if batch.Len() >= accountBatchCount {
+				if err := batch.Write(); err != nil {
+					iter.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range batches {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	return nil
+}
+
+func verifyReshardAccounts(src []db.Database, dst []db.Database) error {
+	srcHash, srcCount, err := accountStateHash(src)
+	if err != nil {
+		return err
+	}
+	dstHash, dstCount, err := accountStateHash(dst)
+	if err != nil {
+		return err
+	}
+	if srcCount != dstCount || bytes.Compare(srcHash, dstHash) != 0 {
+		return fmt.Errorf("account state hash mismatch. %d accounts %s -> %d accounts %s",
+			srcCount, hex.EncodeToString(srcHash), dstCount, hex.EncodeToString(dstHash))
+	}
+	log.Printf("Verified %d accounts. account state hash: %s", dstCount, hex.EncodeToString(dstHash))
+	return nil
+}
+
+// accountStateHash returns hash of all accounts in address order and the number of accounts.
+// Unlike stateHash of calculation, it does not depend on DB count.
+func accountStateHash(dbs []db.Database) ([]byte, uint64, error) {
+	h := sha3.NewShake256()
+	var count uint64
+	err := mergeAccountDBs(dbs, func(key []byte, value []byte) error {
+		h.Write(key)
+		h.Write(value)
+		count++
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hash := make([]byte, 64)
+	h.Read(hash)
+	return hash, count, nil
+}
+
+// mergeAccountDBs calls f with accounts of all account DBs in key order
+func mergeAccountDBs(dbs []db.Database, f func(key []byte, value []byte) error) error {
+	iters := make([]db.Iterator, len(dbs))
+	for i, aDB := range dbs {
+		iters[i], _ = aDB.GetIterator()
+	}
+	return mergeIterators(iters, nil, f)
+}
+
+// mergeIterators calls f with entries of iters from start in key order
+func mergeIterators(iters []db.Iterator, start []byte, f func(key []byte, value []byte) error) error {
+	valid := make([]bool, len(iters))
+	for i, iter := range iters {
+		iter.New(start, nil)
+		valid[i] = iter.Next()
+	}
+	defer func() {
+		for _, iter := range iters {
+			iter.Release()
+		}
+	}()
+
+	for {
+		min := -1
+		for i, iter := range iters {
+			if valid[i] && (min == -1 || bytes.Compare(iter.Key(), iters[min].Key()) < 0) {
+				min = i
+			}
+		}
+		if min == -1 {
+			break
+		}
+
+		if err := f(iters[min].Key(), iters[min].Value()); err != nil {
+			return err
+		}
+		valid[min] = iters[min].Next()
+	}
+
+	for _, iter := range iters {
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
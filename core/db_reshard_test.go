@@ -0,0 +1,133 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func emulateReshardTestCalculations(ctx *Context, accounts []*IScoreAccount) {
+	ctx.DB.SetBackupCount(2)
+	for _, bh := range []uint64{10, 20, 30} {
+		ctx.DB.setCalculatingBH(bh, nil)
+		ctx.DB.toggleAccountDB(bh + 1)
+		ctx.DB.resetAccountDB(bh)
+		for _, ia := range accounts {
+			ia.IScore.SetUint64(bh)
+			bucket, _ := ctx.DB.getCalculateDB(ia.Address).GetBucket(db.PrefixIScore)
+			bucket.Set(ia.ID(), ia.Bytes())
+		}
+		ctx.DB.setCalcDoneBH(bh)
+		WriteCalculationResult(ctx.DB.getCalculateResultDB(), bh, nil, nil, nil, nil)
+		ctx.DB.deleteOldBackupAccountDB()
+	}
+}
+
+func checkReshardTestAccounts(t *testing.T, ctx *Context, accounts []*IScoreAccount, dbCount int) {
+	assert.Equal(t, dbCount, ctx.DB.info.DBCount)
+	assert.Equal(t, dbCount, len(ctx.DB.getQueryDBList()))
+	for _, ia := range accounts {
+		assert.Equal(t, uint64(20), readIScore(t, ctx.DB.getQueryDB(ia.Address), ia))
+		assert.Equal(t, uint64(30), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+	}
+
+	bhList, err := ctx.DB.getBackupAccountDBList()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{30, 20}, bhList)
+	for _, bh := range bhList {
+		backups, _ := filepath.Glob(ctx.DB.backupAccountDBPattern(bh))
+		assert.Equal(t, dbCount, len(backups))
+	}
+
+	_, err = os.Stat(filepath.Join(ctx.DB.info.DBRoot, ReshardDirName))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDBReshard_ReshardAccountDB(t *testing.T) {
+	accounts := makeTestAccounts(20)
+	for _, counts := range [][]int{{2, 5}, {5, 3}} {
+		ctx := initTest(counts[0])
+		emulateReshardTestCalculations(ctx, accounts)
+		CloseIScoreDB(ctx.DB)
+
+		err := ReshardAccountDB(testDir, testDBType, "test", counts[1])
+		assert.NoError(t, err)
+
+		ctx, err = NewContext(testDir, testDBType, "test", counts[0], "debugConfigPath", nil)
+		assert.NoError(t, err)
+		checkReshardTestAccounts(t, ctx, accounts, counts[1])
+
+		// rollback with resharded backup DB
+		err = ctx.DB.rollbackAccountDB(25)
+		assert.NoError(t, err)
+		for _, ia := range accounts {
+			assert.Equal(t, uint64(10), readIScore(t, ctx.DB.getQueryDB(ia.Address), ia))
+			assert.Equal(t, uint64(20), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+		}
+		finalizeTest(ctx)
+	}
+
+	// invalid DB count
+	ctx := initTest(2)
+	CloseIScoreDB(ctx.DB)
+	assert.Error(t, ReshardAccountDB(testDir, testDBType, "test", 0))
+	assert.Error(t, ReshardAccountDB(testDir, testDBType, "test", MaxDBCount+1))
+	os.RemoveAll(testDir)
+}
+
+func TestDBReshard_recoverReshard(t *testing.T) {
+	const dbCount = 2
+	const newDBCount = 4
+	accounts := makeTestAccounts(20)
+	ctx := initTest(dbCount)
+	emulateReshardTestCalculations(ctx, accounts)
+
+	// stopped while replacing account DBs
+	ctx.DB.CloseAccountDB()
+	assert.NoError(t, ctx.DB.buildReshardAccountDB(newDBCount))
+	j := newJournal(JournalReshard, ctx.DB.getCalcDoneBH())
+	j.Step = newDBCount
+	assert.NoError(t, ctx.DB.writeJournal(j))
+	name := fmt.Sprintf(AccountDBNameFormat, 1, newDBCount, 0)
+	assert.NoError(t, os.Rename(filepath.Join(ctx.DB.info.DBRoot, ReshardDirName, name),
+		filepath.Join(ctx.DB.info.DBRoot, name)))
+	ctx.DB.OpenAccountDB()
+
+	ctx = reopenContext(ctx, dbCount)
+	defer finalizeTest(ctx)
+
+	j, err := ctx.DB.getJournal()
+	assert.NoError(t, err)
+	assert.Nil(t, j)
+	checkReshardTestAccounts(t, ctx, accounts, newDBCount)
+}
+
+func TestDBReshard_accountStateHash(t *testing.T) {
+	accounts := makeTestAccounts(10)
+	ctx := initTest(3)
+	defer finalizeTest(ctx)
+	emulateReshardTestCalculations(ctx, accounts)
+
+	srcDBs := ctx.DB.GetCalcDBList()
+	dstDBs := openAccountDBList(testDir, testDBType, []string{"dst_1"})
+	defer closeAccountDBList(dstDBs)
+
+	assert.NoError(t, reshardAccounts(srcDBs, dstDBs))
+
+	srcHash, srcCount, err := accountStateHash(srcDBs)
+	assert.NoError(t, err)
+	dstHash, dstCount, err := accountStateHash(dstDBs)
+	assert.NoError(t, err)
+	assert.Equal(t, srcHash, dstHash)
+	assert.Equal(t, uint64(len(accounts)), srcCount)
+	assert.Equal(t, srcCount, dstCount)
+
+	// different accounts
+	srcHash, _, err = accountStateHash(ctx.DB.getQueryDBList())
+	assert.NoError(t, err)
+	assert.NotEqual(t, srcHash, dstHash)
+}
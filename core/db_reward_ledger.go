@@ -0,0 +1,192 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"math/big"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	RewardLedgerDBName = "reward_ledger"
+)
+
+const (
+	rewardBeta1 = iota
+	rewardBeta2
+	rewardBeta3
+)
+
+type RewardLedgerData struct {
+	Beta1 common.HexInt
+	Beta2 common.HexInt
+	Beta3 common.HexInt
+}
+
+// RewardLedger is I-Score of an account earned in a term. BlockHeight is the block height of calculation.
+type RewardLedger struct {
+	BlockHeight uint64
+	Address     common.Address
+	RewardLedgerData
+}
+
+func (rl *RewardLedger) ID() []byte {
+	return rewardLedgerKey(rl.BlockHeight, rl.Address)
+}
+
+func (rl *RewardLedger) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&rl.RewardLedgerData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (rl *RewardLedger) String() string {
+	b, err := json.Marshal(rl)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (rl *RewardLedger) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &rl.RewardLedgerData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (rl *RewardLedger) IScore() *common.HexInt {
+	total := new(common.HexInt)
+	total.Add(&rl.Beta1.Int, &rl.Beta2.Int)
+	total.Add(&total.Int, &rl.Beta3.Int)
+	return total
+}
+
+func (rl *RewardLedger) add(beta int, reward *big.Int) {
+	switch beta {
+	case rewardBeta1:
+		rl.Beta1.Add(&rl.Beta1.Int, reward)
+	case rewardBeta2:
+		rl.Beta2.Add(&rl.Beta2.Int, reward)
+	case rewardBeta3:
+		rl.Beta3.Add(&rl.Beta3.Int, reward)
+	}
+}
+
+// rewardLedgerKey is block height(8 bytes) + address. Entries of a term are adjacent.
+func rewardLedgerKey(blockHeight uint64, address common.Address) []byte {
+	addr := address.Bytes()
+	bs := make([]byte, BlockHeightSize+len(addr))
+	bh := common.Uint64ToBytes(blockHeight)
+	copy(bs[BlockHeightSize-len(bh):], bh)
+	copy(bs[BlockHeightSize:], addr)
+	return bs
+}
+
+func rewardLedgerPrefix(blockHeight uint64) []byte {
+	bs := make([]byte, BlockHeightSize)
+	bh := common.Uint64ToBytes(blockHeight)
+	copy(bs[BlockHeightSize-len(bh):], bh)
+	return bs
+}
+
+func getRewardLedger(rlDB db.Database, blockHeight uint64, address common.Address) (*RewardLedger, error) {
+	rl := new(RewardLedger)
+	rl.BlockHeight = blockHeight
+	rl.Address = address
+
+	bucket, _ := rlDB.GetBucket(db.PrefixRewardLedger)
+	bs, err := bucket.Get(rl.ID())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	if err = rl.SetBytes(bs); err != nil {
+		return nil, err
+	}
+	return rl, nil
+}
+
+// updateRewardLedger adds reward to the reward ledger of the account. Do nothing if reward ledger is disabled.
+func updateRewardLedger(ctx *Context, blockHeight uint64, address common.Address, beta int, reward *big.Int) {
+	rlDB := ctx.DB.getRewardLedgerDB()
+	if rlDB == nil || reward.Sign() == 0 {
+		return
+	}
+
+	rl, err := getRewardLedger(rlDB, blockHeight, address)
+	if err != nil {
+		log.Printf("Failed to read reward ledger of %s. %v", address.String(), err)
+		return
+	}
+	if rl == nil {
+		rl = &RewardLedger{BlockHeight: blockHeight, Address: address}
+	}
+	rl.add(beta, reward)
+	writeRewardLedger(rlDB, rl)
+}
+
+// setRewardLedger replaces the reward ledger of the account with reward. Do nothing if reward ledger is disabled.
+// calculateDB writes the first reward of the account in a term with it, so it can be done again from checkpoint.
+func setRewardLedger(ctx *Context, blockHeight uint64, address common.Address, beta int, reward *big.Int) {
+	rlDB := ctx.DB.getRewardLedgerDB()
+	if rlDB == nil || reward.Sign() == 0 {
+		return
+	}
+
+	rl := &RewardLedger{BlockHeight: blockHeight, Address: address}
+	rl.add(beta, reward)
+	writeRewardLedger(rlDB, rl)
+}
+
+func writeRewardLedger(rlDB db.Database, rl *RewardLedger) {
+	bucket, _ := rlDB.GetBucket(db.PrefixRewardLedger)
+	bs, _ := rl.Bytes()
+	if err := bucket.Set(rl.ID(), bs); err != nil {
+		log.Printf("Failed to write reward ledger %s. %v", rl.String(), err)
+	}
+}
+
+// deleteRewardLedger deletes reward ledger of all accounts for the term
+func deleteRewardLedger(rlDB db.Database, blockHeight uint64) {
+	if rlDB == nil {
+		return
+	}
+
+	keys := make([][]byte, 0)
+	iter, _ := rlDB.GetIterator()
+	prefix := util.BytesPrefix(rewardLedgerPrefix(blockHeight))
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		key := make([]byte, len(iter.Key()))
+		copy(key, iter.Key())
+		keys = append(keys, key)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		log.Printf("There is error while reward ledger iteration. %+v", err)
+	}
+
+	batch, _ := rlDB.GetBatch()
+	batch.New()
+	for _, key := range keys {
+		batch.Delete(key)
+	}
+	if err := batch.Write(); err != nil {
+		log.Printf("Failed to delete reward ledger of %d. %v", blockHeight, err)
+		return
+	}
+	batch.Reset()
+
+	if len(keys) > 0 {
+		log.Printf("Delete %d reward ledger of %d", len(keys), blockHeight)
+	}
+}
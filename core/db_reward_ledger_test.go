@@ -0,0 +1,92 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBRewardLedger_update(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	address := *common.NewAddressFromString("hx11")
+
+	// reward ledger is disabled
+	updateRewardLedger(ctx, 100, address, rewardBeta1, big.NewInt(10))
+	assert.Nil(t, ctx.DB.getRewardLedgerDB())
+
+	ctx.DB.OpenRewardLedgerDB()
+	rlDB := ctx.DB.getRewardLedgerDB()
+
+	rl, err := getRewardLedger(rlDB, 100, address)
+	assert.NoError(t, err)
+	assert.Nil(t, rl)
+
+	updateRewardLedger(ctx, 100, address, rewardBeta1, big.NewInt(10))
+	updateRewardLedger(ctx, 100, address, rewardBeta2, big.NewInt(20))
+	updateRewardLedger(ctx, 100, address, rewardBeta3, big.NewInt(30))
+	updateRewardLedger(ctx, 100, address, rewardBeta3, big.NewInt(-5))
+	updateRewardLedger(ctx, 200, address, rewardBeta1, big.NewInt(1))
+
+	rl, err = getRewardLedger(rlDB, 100, address)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), rl.Beta1.Int64())
+	assert.Equal(t, int64(20), rl.Beta2.Int64())
+	assert.Equal(t, int64(25), rl.Beta3.Int64())
+	assert.Equal(t, int64(55), rl.IScore().Int64())
+
+	// delete reward ledger of block height 100
+	deleteRewardLedger(rlDB, 100)
+	rl, err = getRewardLedger(rlDB, 100, address)
+	assert.NoError(t, err)
+	assert.Nil(t, rl)
+
+	rl, err = getRewardLedger(rlDB, 200, address)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rl.Beta1.Int64())
+}
+
+func TestMsgRewardLedger_DoQueryRewardLedger(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	address := *common.NewAddressFromString("hx11")
+	req := &QueryRewardLedger{Address: address}
+
+	// reward ledger is disabled
+	resp, err := DoQueryRewardLedger(ctx, req)
+	assert.Error(t, err)
+	assert.Equal(t, 0, resp.IScore.Sign())
+
+	ctx.DB.OpenRewardLedgerDB()
+	ctx.DB.setCalcDoneBH(100)
+	updateRewardLedger(ctx, 50, address, rewardBeta1, big.NewInt(1))
+	updateRewardLedger(ctx, 100, address, rewardBeta1, big.NewInt(10))
+	updateRewardLedger(ctx, 100, address, rewardBeta3, big.NewInt(30))
+
+	// the latest calculation
+	resp, err = DoQueryRewardLedger(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, address, resp.Address)
+	assert.Equal(t, uint64(100), resp.BlockHeight)
+	assert.Equal(t, int64(10), resp.Beta1.Int64())
+	assert.Equal(t, int64(0), resp.Beta2.Int64())
+	assert.Equal(t, int64(30), resp.Beta3.Int64())
+	assert.Equal(t, int64(40), resp.IScore.Int64())
+
+	// specific calculation
+	req.BlockHeight = 50
+	resp, err = DoQueryRewardLedger(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50), resp.BlockHeight)
+	assert.Equal(t, int64(1), resp.IScore.Int64())
+
+	// no reward
+	req.BlockHeight = 70
+	resp, err = DoQueryRewardLedger(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, resp.IScore.Sign())
+}
@@ -1,12 +1,12 @@
 package core
 
 import (
-	"encoding/json"
 	"fmt"
-	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/common"
 	"github.com/icon-project/rewardcalculator/common/ipc"
 	"log"
 	"math"
+	"net/http"
 	"path/filepath"
 	"sync"
 )
@@ -15,34 +15,10 @@ const (
 	DebugAddress = "/tmp/.icon-rc-monitor.sock"
 )
 
-type RcConfig struct {
-	IISSDataDir   string `json:"IISSData"`
-	DBDir         string `json:"IScoreDB"`
-	IpcNet        string `json:"IPCNet"`
-	IpcAddr       string `json:"IPCAddress"`
-	ClientMode    bool   `json:"ClientMode"`
-	DBCount       int    `json:"DBCount"`
-	Monitor       bool   `json:"Monitor"`
-	LogFile       string `json:"LogFile"`
-	LogMaxSize    int    `json:"LogMaxSize"`
-	LogMaxBackups int    `json:"LogMaxBackups"`
-	CalcDebugConf string `json:"CalcDebugConf"`
-	FileName      string
-}
-
-func (cfg *RcConfig) Print() {
-	b, err := json.Marshal(cfg)
-	if err != nil {
-		log.Printf("Can't covert configuration to json")
-		return
-	}
-
-	log.Printf("Running config %s\n", string(b))
-}
-
 type Manager interface {
 	Loop() error
 	Close() error
+	Reload(cfg *RcConfig) error
 }
 
 type manager struct {
@@ -51,35 +27,51 @@ type manager struct {
 	server      ipc.Server
 	conn        ipc.Connection
 
+	cfg     RcConfig
+	monitor *manager
+	metrics *http.Server
+
 	ctx       *Context
 	waitGroup *sync.WaitGroup
+	conns     *connRegistry
+	auth      *msgAuth // verifier of signed messages. nil if IPC authentication is disabled
+
+	// connection to ICON Service in client mode
+	clientLock sync.Mutex
+	pending    []pendingNotification
+	closing    chan struct{}
+	closeOnce  sync.Once
 }
 
 func (m *manager) Loop() error {
 	if m.clientMode {
-		for {
-			err := m.conn.HandleMessage()
-			if err != nil {
-				log.Printf("Failed to handle message err=%+v", err)
-				m.Close()
-				return err
-			}
-		}
+		return m.loopClient()
 	} else {
 		return m.server.Loop()
 	}
 }
 
 func (m *manager) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.closing)
+	})
 	m.ctx.CancelCalculation.notifyExit()
 	m.WaitMsgTasksDone()
 	if m.clientMode {
-		m.conn.Close()
+		if conn := m.getClientConn(); conn != nil {
+			conn.Close()
+		}
 	} else {
 		if err := m.server.Close(); err != nil {
 			log.Printf("Failed to close IPC server err=%+v", err)
 		}
 	}
+	m.closeMonitor()
+	if m.metrics != nil {
+		if err := m.metrics.Close(); err != nil {
+			log.Printf("Failed to close metrics server err=%+v", err)
+		}
+	}
 
 	CloseIScoreDB(m.ctx.DB)
 	log.Printf("Exit Reward Calculator")
@@ -89,17 +81,113 @@ func (m *manager) Close() error {
 // ConnectionHandler.OnConnect
 func (m *manager) OnConnect(c ipc.Connection) error {
 	_, err := newConnection(m, c)
+	if err != nil {
+		m.conns.remove(c)
+	}
 	return err
 }
 
 // ConnectionHandler.OnClose
+// Requests in flight of the connection finish, but their replies are dropped.
 func (m *manager) OnClose(c ipc.Connection) error {
-	// TODO finalize connection
-	// use sync.WaitGroup ?
+	tc := m.conns.remove(c)
+	if tc == nil {
+		return nil
+	}
+
+	// stop CALCULATE_PROGRESS notification
+	m.ctx.progress.unsubscribe(tc)
+
+	info := tc.info()
+	log.Printf("Close connection %d. %d requests in flight %v", info.ID, len(info.InFlight), info.InFlight)
+	return nil
+}
+
+// Reload applies the configuration values which can be changed while running.
+// Log settings, calculation debug configuration and monitoring channel.
+func (m *manager) Reload(cfg *RcConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if changed := m.cfg.staticChanged(cfg); len(changed) > 0 {
+		log.Printf("Ignore changes of %v. Restart Reward Calculator to apply them", changed)
+	}
+
+	// log settings
+	if m.cfg.LogFile != cfg.LogFile || m.cfg.LogMaxSize != cfg.LogMaxSize ||
+		m.cfg.LogMaxBackups != cfg.LogMaxBackups {
+		common.SetLog(cfg.LogFile, cfg.LogMaxSize, cfg.LogMaxBackups, true)
+		m.cfg.LogFile = cfg.LogFile
+		m.cfg.LogMaxSize = cfg.LogMaxSize
+		m.cfg.LogMaxBackups = cfg.LogMaxBackups
+	}
+
+	// calculation debug configuration. file contents may be changed with same path
+	if err := ReloadCalcDebugConfig(m.ctx, cfg.CalcDebugConf); err != nil {
+		log.Printf("Failed to reload calculation debug config %s. %v", cfg.CalcDebugConf, err)
+	} else {
+		m.cfg.CalcDebugConf = cfg.CalcDebugConf
+	}
+
+	// monitoring channel
+	if m.cfg.Monitor != cfg.Monitor {
+		if cfg.Monitor {
+			if err := m.openMonitor(); err != nil {
+				log.Printf("Failed to open monitoring channel. %v", err)
+				return err
+			}
+		} else {
+			m.closeMonitor()
+		}
+		m.cfg.Monitor = cfg.Monitor
+	}
+
+	log.Printf("Reload configuration")
+	m.cfg.Print()
+
+	return nil
+}
+
+func (m *manager) openMonitor() error {
+	if m.monitor != nil {
+		return nil
+	}
+
+	monitor := new(manager)
+	monitor.ctx = m.ctx
+	monitor.monitorMode = true
+	monitor.waitGroup = m.waitGroup
+	monitor.conns = m.conns
 
+	srv := ipc.NewServer()
+	err := srv.Listen("unix", DebugAddress)
+	if err != nil {
+		return err
+	}
+	srv.SetHandler(monitor)
+	monitor.server = srv
+	m.monitor = monitor
+
+	go monitor.Loop()
+
+	log.Printf("Open monitoring channel %s", DebugAddress)
 	return nil
 }
 
+func (m *manager) closeMonitor() {
+	if m.monitor == nil {
+		return
+	}
+
+	if err := m.monitor.server.Close(); err != nil {
+		log.Printf("Failed to close monitoring channel err=%+v", err)
+	}
+	m.monitor = nil
+
+	log.Printf("Close monitoring channel %s", DebugAddress)
+}
+
 func (m *manager) AddMsgTask() {
 	m.waitGroup.Add(1)
 }
@@ -119,12 +207,39 @@ func InitManager(cfg *RcConfig) (*manager, error) {
 	m := new(manager)
 	m.clientMode = cfg.ClientMode
 	m.waitGroup = waitGroup
+	m.conns = newConnRegistry()
+	m.closing = make(chan struct{})
+	m.cfg = *cfg
+	if m.auth, err = newMsgAuth(cfg.IpcAuthKeys); err != nil {
+		return nil, err
+	}
+	if m.auth != nil {
+		log.Printf("IPC authentication is enabled with %d keys", len(m.auth.keys))
+	}
 
 	// Initialize DB and load context values
-	m.ctx, err = NewContext(cfg.DBDir, string(db.GoLevelDBBackend), "IScore", cfg.DBCount, cfg.CalcDebugConf)
+	network, err := cfg.GetNetworkProfile()
 	if err != nil {
 		return nil, err
 	}
+	m.ctx, err = NewContext(cfg.DBDir, cfg.DBType, "IScore", cfg.DBCount, cfg.CalcDebugConf, network)
+	if err != nil {
+		return nil, err
+	}
+	if m.ctx.DB.info.DBCount != cfg.DBCount {
+		log.Printf("DBCount %d is ignored. I-Score DB has %d account DBs. Use 'dbtool reshard' to change it",
+			cfg.DBCount, m.ctx.DB.info.DBCount)
+	}
+	m.ctx.DB.SetBackupCount(cfg.BackupCount)
+	if len(cfg.NodeKey) > 0 {
+		if m.ctx.nodeKey, err = LoadNodeKey(cfg.NodeKey); err != nil {
+			return nil, fmt.Errorf("failed to load node key. %v", err)
+		}
+		log.Printf("Sign calculation results with node key %s", m.ctx.nodeKey.PublicKey().String())
+	}
+	if cfg.RewardLedger {
+		m.ctx.DB.OpenRewardLedgerDB()
+	}
 
 	m.ctx.Print()
 
@@ -133,17 +248,25 @@ func InitManager(cfg *RcConfig) (*manager, error) {
 
 	// Initialize ipc channel
 	if m.clientMode {
-		// connect to server
-		conn, err := ipc.Dial(cfg.IpcNet, cfg.IpcAddr)
-		if err != nil {
-			return nil, err
+		// connect to server. Loop retries with backoff if it fails
+		if conn, err := m.dial(); err != nil {
+			log.Printf("Failed to connect to %s:%s. %v", cfg.IpcNet, cfg.IpcAddr, err)
+		} else if err = m.setClientConn(conn); err != nil {
+			log.Printf("Failed to send READY to %s:%s. %v", cfg.IpcNet, cfg.IpcAddr, err)
+			conn.Close()
 		}
-		m.OnConnect(conn)
-		m.conn = conn
 	} else {
 		// IPC Server
 		srv := ipc.NewServer()
-		err = srv.Listen(cfg.IpcNet, cfg.IpcAddr)
+		if tlsConfig := cfg.IPCTLSConfig(); tlsConfig != nil {
+			err = srv.ListenTLS(cfg.IpcNet, cfg.IpcAddr, tlsConfig)
+		} else {
+			if ipc.IsTCP(cfg.IpcNet) {
+				log.Printf("IPC channel %s is not authenticated. Set IPCTLSCert, IPCTLSKey and IPCTLSCA",
+					cfg.IpcAddr)
+			}
+			err = srv.Listen(cfg.IpcNet, cfg.IpcAddr)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -153,20 +276,18 @@ func InitManager(cfg *RcConfig) (*manager, error) {
 
 	// Initialize debug channel
 	if cfg.Monitor == true {
-		monitor := new(manager)
-		monitor.ctx = m.ctx
-		monitor.monitorMode = true
-		monitor.waitGroup = waitGroup
-
-		srv := ipc.NewServer()
-		err = srv.Listen("unix", DebugAddress)
+		err = m.openMonitor()
 		if err != nil {
 			return nil, err
 		}
-		srv.SetHandler(monitor)
-		monitor.server = srv
+	}
 
-		go monitor.Loop()
+	// Initialize metrics server for Prometheus
+	if len(cfg.MetricsAddr) > 0 {
+		m.metrics, err = startMetricsServer(m.ctx, cfg.MetricsAddr)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return m, err
@@ -180,11 +301,13 @@ func reloadIISSData(ctx *Context, dir string) {
 		var req CalculateRequest
 		req.Path = filepath.Join(dir, fmt.Sprintf(IISSDataDBFormat, ctx.DB.getCalculatingBH()))
 		req.BlockHeight = reloadBlockHeight
+		req.BlockHash = ctx.DB.getCalculatingHash()
 
 		log.Printf("Reload IISS Data. %s", req.Path)
 		err, _, _, _ := DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, reloadMsgID)
 
 		if err != nil {
+			observeCalculationFailure()
 			log.Printf("Failed to reload IISS Data. %s. %v", req.Path, err)
 		} else {
 			log.Printf("Succeeded to reload IISS Data. %s", req.Path)
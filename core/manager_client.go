@@ -0,0 +1,160 @@
+package core
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+// backoff of reconnection to ICON Service in client mode
+var (
+	reconnectMinInterval = time.Second
+	reconnectMaxInterval = 30 * time.Second
+)
+
+// maxPendingNotifications is the number of notifications kept while disconnected. The oldest one is dropped
+var maxPendingNotifications = 64
+
+var errManagerClosed = errors.New("manager closed")
+
+type pendingNotification struct {
+	msg  uint
+	data interface{}
+}
+
+// loopClient handles messages of ICON Service and reconnects to it when the connection is broken.
+// Calculation keeps running while reconnecting.
+func (m *manager) loopClient() error {
+	for {
+		conn := m.getClientConn()
+		if conn == nil {
+			// the first connection failed
+			if err := m.reconnect(); err != nil {
+				return m.reconnectError(err)
+			}
+			continue
+		}
+		err := conn.HandleMessage()
+		if err == nil {
+			continue
+		}
+		if m.isClosing() {
+			return nil
+		}
+
+		log.Printf("Failed to handle message err=%+v. Reconnect to %s:%s", err, m.cfg.IpcNet, m.cfg.IpcAddr)
+		m.OnClose(conn)
+		conn.Close()
+		if err = m.reconnect(); err != nil {
+			return m.reconnectError(err)
+		}
+	}
+}
+
+// reconnectError returns nil if reconnection stopped because manager is closed
+func (m *manager) reconnectError(err error) error {
+	if err == errManagerClosed {
+		return nil
+	}
+	return err
+}
+
+// reconnect dials ICON Service with exponential backoff until it succeeds or manager is closed
+func (m *manager) reconnect() error {
+	interval := reconnectMinInterval
+	for {
+		select {
+		case <-m.closing:
+			return errManagerClosed
+		case <-time.After(interval):
+		}
+
+		conn, err := m.dial()
+		if err == nil {
+			if err = m.setClientConn(conn); err == nil {
+				log.Printf("Reconnected to %s:%s", m.cfg.IpcNet, m.cfg.IpcAddr)
+				return nil
+			}
+			conn.Close()
+		}
+
+		if interval *= 2; interval > reconnectMaxInterval {
+			interval = reconnectMaxInterval
+		}
+		log.Printf("Failed to reconnect to %s:%s. Retry after %s. %v", m.cfg.IpcNet, m.cfg.IpcAddr, interval, err)
+	}
+}
+
+// dial connects to ICON Service with TLS if it is configured
+func (m *manager) dial() (ipc.Connection, error) {
+	if tlsConfig := m.cfg.IPCTLSConfig(); tlsConfig != nil {
+		return ipc.DialTLS(m.cfg.IpcNet, m.cfg.IpcAddr, tlsConfig)
+	}
+	return ipc.Dial(m.cfg.IpcNet, m.cfg.IpcAddr)
+}
+
+// setClientConn sends READY to the new connection of ICON Service and
+// notifications which were not sent while disconnected
+func (m *manager) setClientConn(conn ipc.Connection) error {
+	m.clientLock.Lock()
+	defer m.clientLock.Unlock()
+
+	if err := m.OnConnect(conn); err != nil {
+		return err
+	}
+	m.conn = conn
+
+	tc := m.conns.get(conn)
+	for len(m.pending) > 0 {
+		n := m.pending[0]
+		log.Printf("Send message kept while disconnected. (msg:%s)", MsgToString(n.msg))
+		if err := tc.Send(n.msg, 0, n.data); err != nil {
+			log.Printf("Failed to send %s. %v", MsgToString(n.msg), err)
+			break
+		}
+		m.pending = m.pending[1:]
+	}
+	return nil
+}
+
+func (m *manager) getClientConn() ipc.Connection {
+	m.clientLock.Lock()
+	defer m.clientLock.Unlock()
+	return m.conn
+}
+
+func (m *manager) isClosing() bool {
+	select {
+	case <-m.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendNotification sends message without id like CALCULATE_DONE to the peer of c.
+// In client mode, it is sent to the current connection of ICON Service and
+// kept until reconnection if the connection is broken.
+func (m *manager) sendNotification(c ipc.Connection, msg uint, data interface{}) error {
+	if !m.clientMode {
+		return c.Send(msg, 0, data)
+	}
+
+	m.clientLock.Lock()
+	defer m.clientLock.Unlock()
+
+	if tc := m.conns.get(m.conn); tc != nil {
+		if err := tc.Send(msg, 0, data); err == nil {
+			return nil
+		}
+	}
+	log.Printf("Keep %s until reconnection", MsgToString(msg))
+	if len(m.pending) >= maxPendingNotifications {
+		log.Printf("Drop %s kept while disconnected. too many notifications", MsgToString(m.pending[0].msg))
+		m.pending = m.pending[1:]
+	}
+	m.pending = append(m.pending, pendingNotification{msg: msg, data: data})
+	return nil
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/ipc"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessage struct {
+	msg  uint
+	conn ipc.Connection
+}
+
+// testService is ICON Service which receives messages from Reward Calculator in client mode
+type testService struct {
+	msgs  chan testMessage
+	conns chan ipc.Connection
+}
+
+func (s *testService) OnConnect(c ipc.Connection) error {
+	c.SetHandler(MsgReady, s)
+	c.SetHandler(MsgCalculateDone, s)
+	s.conns <- c
+	return nil
+}
+
+func (s *testService) OnClose(c ipc.Connection) error {
+	return nil
+}
+
+func (s *testService) HandleMessage(c ipc.Connection, msg uint, id uint32, data []byte) error {
+	s.msgs <- testMessage{msg: msg, conn: c}
+	return nil
+}
+
+func (s *testService) listen(t *testing.T, address string) ipc.Server {
+	srv := ipc.NewServer()
+	assert.NoError(t, srv.Listen("unix", address))
+	srv.SetHandler(s)
+	go srv.Loop()
+	return srv
+}
+
+func (s *testService) receive(t *testing.T) testMessage {
+	select {
+	case m := <-s.msgs:
+		return m
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout")
+		return testMessage{}
+	}
+}
+
+func TestManager_reconnect(t *testing.T) {
+	interval := reconnectMinInterval
+	reconnectMinInterval = 10 * time.Millisecond
+	defer func() {
+		reconnectMinInterval = interval
+	}()
+
+	dir, err := ioutil.TempDir("", "client")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	address := filepath.Join(dir, "icon.sock")
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	ctx.DB.setCurrentBlockInfo(10, make([]byte, BlockHashSize))
+
+	service := &testService{msgs: make(chan testMessage, 10), conns: make(chan ipc.Connection, 10)}
+	srv := service.listen(t, address)
+
+	m := newTestManager(ctx)
+	m.clientMode = true
+	m.cfg.IpcNet = "unix"
+	m.cfg.IpcAddr = address
+	m.closing = make(chan struct{})
+	conn, err := ipc.Dial("unix", address)
+	assert.NoError(t, err)
+	assert.NoError(t, m.setClientConn(conn))
+	assert.Equal(t, uint(MsgReady), service.receive(t).msg)
+
+	done := make(chan error)
+	go func() {
+		done <- m.Loop()
+	}()
+
+	// ICON Service restarts
+	srv.Close()
+	(<-service.conns).Close()
+
+	// notification is kept while disconnected
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, m.sendNotification(nil, MsgCalculateDone, &CalculateDone{BlockHeight: 10}))
+	srv = service.listen(t, address)
+	defer srv.Close()
+
+	// READY and the kept notification are sent to new connection
+	ready := service.receive(t)
+	assert.Equal(t, uint(MsgReady), ready.msg)
+	calcDone := service.receive(t)
+	assert.Equal(t, uint(MsgCalculateDone), calcDone.msg)
+	assert.Equal(t, ready.conn, calcDone.conn)
+	assert.Equal(t, 0, len(m.pending))
+
+	// send notification to the current connection
+	assert.NoError(t, m.sendNotification(nil, MsgCalculateDone, &CalculateDone{BlockHeight: 20}))
+	assert.Equal(t, uint(MsgCalculateDone), service.receive(t).msg)
+
+	// stop
+	close(m.closing)
+	m.getClientConn().Close()
+	select {
+	case err = <-done:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestManager_connectAfterFirstDialFailure(t *testing.T) {
+	interval := reconnectMinInterval
+	reconnectMinInterval = 10 * time.Millisecond
+	defer func() {
+		reconnectMinInterval = interval
+	}()
+
+	dir, err := ioutil.TempDir("", "client")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	address := filepath.Join(dir, "icon.sock")
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	// ICON Service is not ready
+	m := newTestManager(ctx)
+	m.clientMode = true
+	m.cfg.IpcNet = "unix"
+	m.cfg.IpcAddr = address
+	m.closing = make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- m.Loop()
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	service := &testService{msgs: make(chan testMessage, 10), conns: make(chan ipc.Connection, 10)}
+	srv := service.listen(t, address)
+	defer srv.Close()
+	assert.Equal(t, uint(MsgReady), service.receive(t).msg)
+
+	// stop
+	close(m.closing)
+	m.getClientConn().Close()
+	select {
+	case err = <-done:
+		assert.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout")
+	}
+}
+
+func TestManager_maxPendingNotifications(t *testing.T) {
+	limit := maxPendingNotifications
+	maxPendingNotifications = 2
+	defer func() {
+		maxPendingNotifications = limit
+	}()
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	m := newTestManager(ctx)
+	m.clientMode = true
+
+	// the oldest notification is dropped
+	for _, bh := range []uint64{10, 20, 30} {
+		assert.NoError(t, m.sendNotification(nil, MsgCalculateDone, &CalculateDone{BlockHeight: bh}))
+	}
+	assert.Equal(t, 2, len(m.pending))
+	assert.Equal(t, uint64(20), m.pending[0].data.(*CalculateDone).BlockHeight)
+	assert.Equal(t, uint64(30), m.pending[1].data.(*CalculateDone).BlockHeight)
+}
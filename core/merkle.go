@@ -0,0 +1,375 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/crypto"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+const (
+	merkleLeafPrefix byte = 0x00
+	merkleNodePrefix byte = 0x01
+)
+
+// MerkleProofNode is a sibling hash in the path from leaf to root
+type MerkleProofNode struct {
+	Left bool // sibling is the left child
+	Hash []byte
+}
+
+func (n *MerkleProofNode) String() string {
+	return fmt.Sprintf("Left: %t, Hash: %s", n.Left, hex.EncodeToString(n.Hash))
+}
+
+// AccountLeafHash returns the Merkle leaf of account.
+// leaf = SHA3-256(0x00 || address(21 bytes) || block height(8 bytes, big endian) || I-Score)
+func AccountLeafHash(address common.Address, blockHeight uint64, iScore *common.HexInt) []byte {
+	bh := make([]byte, 8)
+	binary.BigEndian.PutUint64(bh, blockHeight)
+	iScoreBytes := iScore.Bytes()
+
+	buf := make([]byte, 0, 1+common.AddressBytes+len(bh)+len(iScoreBytes))
+	buf = append(buf, merkleLeafPrefix)
+	buf = append(buf, address.Bytes()...)
+	buf = append(buf, bh...)
+	buf = append(buf, iScoreBytes...)
+	return crypto.SHA3Sum256(buf)
+}
+
+func merkleNodeHash(left []byte, right []byte) []byte {
+	buf := make([]byte, 0, 1+len(left)+len(right))
+	buf = append(buf, merkleNodePrefix)
+	buf = append(buf, left...)
+	buf = append(buf, right...)
+	return crypto.SHA3Sum256(buf)
+}
+
+// VerifyMerkleProof checks that leaf is in the tree of root
+func VerifyMerkleProof(root []byte, leaf []byte, proof []MerkleProofNode) bool {
+	hash := leaf
+	for _, node := range proof {
+		if node.Left {
+			hash = merkleNodeHash(node.Hash, hash)
+		} else {
+			hash = merkleNodeHash(hash, node.Hash)
+		}
+	}
+	return bytes.Equal(hash, root)
+}
+
+// merkleTree is a binary Merkle tree of all accounts in address order after a calculation.
+// The last node of a level with odd number of nodes is moved up to the next level.
+// Accounts are grouped in chunks of merkleChunkSize accounts and the tree keeps levels from roots of chunks
+// to save memory. Proof in a chunk is made with accounts of the chunk in account DB.
+type merkleTree struct {
+	blockHeight uint64
+	set         int              // account DB set which has accounts of the tree. See IScoreDB.getAccountDBSet
+	chunks      []common.Address // the first address of each chunk
+	levels      [][][]byte       // levels[0] is roots of chunks and the last level is root
+}
+
+const (
+	merkleChunkLevel = 4
+	merkleChunkSize  = 1 << merkleChunkLevel
+)
+
+var (
+	errStateTreeCanceled = errors.New("making Merkle tree was canceled")
+	errChunkFull         = errors.New("chunk is full")
+)
+
+// merkleLevels returns levels of the tree of leaves. levels[0] is leaves and the last level is root
+func merkleLevels(leaves [][]byte) [][][]byte {
+	levels := [][][]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][]byte, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next[i/2] = level[i]
+			} else {
+				next[i/2] = merkleNodeHash(level[i], level[i+1])
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// merkleSiblings returns proof of levels[0][index]
+func merkleSiblings(levels [][][]byte, index int) []MerkleProofNode {
+	proof := make([]MerkleProofNode, 0)
+	for i, level := range levels[:len(levels)-1] {
+		pos := index >> uint(i)
+		sibling := pos ^ 1
+		if sibling < len(level) {
+			proof = append(proof, MerkleProofNode{Left: sibling < pos, Hash: level[sibling]})
+		}
+	}
+	return proof
+}
+
+// merkleTreeBuilder makes merkleTree with accounts in address order
+type merkleTreeBuilder struct {
+	tree  *merkleTree
+	chunk [][]byte
+	roots [][]byte
+}
+
+func newMerkleTreeBuilder(blockHeight uint64) *merkleTreeBuilder {
+	return &merkleTreeBuilder{
+		tree:  &merkleTree{blockHeight: blockHeight},
+		chunk: make([][]byte, 0, merkleChunkSize),
+		roots: make([][]byte, 0),
+	}
+}
+
+func (b *merkleTreeBuilder) add(address common.Address, leaf []byte) {
+	if len(b.chunk) == 0 {
+		b.tree.chunks = append(b.tree.chunks, address)
+	}
+	b.chunk = append(b.chunk, leaf)
+	if len(b.chunk) == merkleChunkSize {
+		b.flush()
+	}
+}
+
+func (b *merkleTreeBuilder) flush() {
+	levels := merkleLevels(b.chunk)
+	b.roots = append(b.roots, levels[len(levels)-1][0])
+	b.chunk = b.chunk[:0]
+}
+
+func (b *merkleTreeBuilder) build() *merkleTree {
+	if len(b.chunk) > 0 {
+		b.flush()
+	}
+	b.tree.levels = merkleLevels(b.roots)
+	return b.tree
+}
+
+func newMerkleTree(blockHeight uint64, addresses []common.Address, leaves [][]byte) *merkleTree {
+	b := newMerkleTreeBuilder(blockHeight)
+	for i, address := range addresses {
+		b.add(address, leaves[i])
+	}
+	return b.build()
+}
+
+// root returns root hash of the tree. Root of empty tree is SHA3-256 of empty bytes
+func (t *merkleTree) root() []byte {
+	top := t.levels[len(t.levels)-1]
+	if len(top) == 0 {
+		return crypto.SHA3Sum256(nil)
+	}
+	return top[0]
+}
+
+// chunkOf returns index of the chunk which may have address. -1 if address is before all accounts
+func (t *merkleTree) chunkOf(address common.Address) int {
+	return sort.Search(len(t.chunks), func(i int) bool {
+		return bytes.Compare(t.chunks[i].Bytes(), address.Bytes()) > 0
+	}) - 1
+}
+
+// proof returns inclusion proof of leaves[index]. leaves are all leaves of the chunk. index is -1 to check leaves only
+func (t *merkleTree) proof(chunk int, leaves [][]byte, index int) ([]MerkleProofNode, error) {
+	levels := merkleLevels(leaves)
+	if len(leaves) == 0 || !bytes.Equal(levels[len(levels)-1][0], t.levels[0][chunk]) {
+		return nil, fmt.Errorf("accounts of chunk %d are not in the tree of %d", chunk, t.blockHeight)
+	}
+	if index < 0 {
+		return nil, nil
+	}
+	return append(merkleSiblings(levels, index), merkleSiblings(t.levels, chunk)...), nil
+}
+
+// buildStateTree makes Merkle tree of accounts in account DBs. The tree does not depend on DB count.
+// It returns errStateTreeCanceled if quit is closed.
+func buildStateTree(quit <-chan struct{}, blockHeight uint64, dbs []db.Database) (*merkleTree, error) {
+	iters := make([]db.Iterator, len(dbs))
+	for i, aDB := range dbs {
+		iters[i], _ = aDB.GetIterator()
+	}
+	return buildStateTreeWithIterators(quit, blockHeight, iters)
+}
+
+func buildStateTreeWithIterators(quit <-chan struct{}, blockHeight uint64, iters []db.Iterator) (*merkleTree, error) {
+	b := newMerkleTreeBuilder(blockHeight)
+	err := mergeIterators(iters, nil, func(key []byte, value []byte) error {
+		select {
+		case <-quit:
+			return errStateTreeCanceled
+		default:
+		}
+		ia, err := NewIScoreAccountFromBytes(value)
+		if err != nil {
+			return err
+		}
+		address := common.NewAddress(key[len(db.PrefixIScore):])
+		b.add(*address, AccountLeafHash(*address, ia.BlockHeight, &ia.IScore))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.build(), nil
+}
+
+// readStateTreeChunk reads accounts of the chunk of tree from account DBs
+func readStateTreeChunk(tree *merkleTree, chunk int, dbs []db.Database) ([]*IScoreAccount, error) {
+	iters := make([]db.Iterator, len(dbs))
+	for i, aDB := range dbs {
+		iters[i], _ = aDB.GetIterator()
+	}
+	start := append([]byte(db.PrefixIScore), tree.chunks[chunk].Bytes()...)
+	accounts := make([]*IScoreAccount, 0, merkleChunkSize)
+	err := mergeIterators(iters, start, func(key []byte, value []byte) error {
+		ia, err := NewIScoreAccountFromBytes(value)
+		if err != nil {
+			return err
+		}
+		ia.Address = *common.NewAddress(key[len(db.PrefixIScore):])
+		accounts = append(accounts, ia)
+		if len(accounts) == merkleChunkSize {
+			return errChunkFull
+		}
+		return nil
+	})
+	if err != nil && err != errChunkFull {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// snapshotIterator reads db.Snapshot with db.Iterator
+type snapshotIterator struct {
+	snapshot db.Snapshot
+}
+
+func (i *snapshotIterator) New(start []byte, limit []byte) {
+	i.snapshot.NewIterator(start, limit)
+}
+
+func (i *snapshotIterator) Next() bool {
+	return i.snapshot.IterNext()
+}
+
+func (i *snapshotIterator) Key() []byte {
+	return i.snapshot.IterKey()
+}
+
+func (i *snapshotIterator) Value() []byte {
+	return i.snapshot.IterValue()
+}
+
+func (i *snapshotIterator) Release() {
+	i.snapshot.ReleaseIterator()
+}
+
+func (i *snapshotIterator) Error() error {
+	return nil
+}
+
+func (ctx *Context) setStateTree(tree *merkleTree) {
+	ctx.stateTreeLock.Lock()
+	ctx.stateTree = tree
+	ctx.stateTreeLock.Unlock()
+}
+
+// getStateRoot returns state root of the calculation if its Merkle tree is cached
+func (ctx *Context) getStateRoot(blockHeight uint64) []byte {
+	ctx.stateTreeLock.Lock()
+	defer ctx.stateTreeLock.Unlock()
+	if ctx.stateTree == nil || ctx.stateTree.blockHeight != blockHeight {
+		return nil
+	}
+	return ctx.stateTree.root()
+}
+
+func (ctx *Context) getCachedStateTree() *merkleTree {
+	ctx.stateTreeLock.Lock()
+	defer ctx.stateTreeLock.Unlock()
+	return ctx.stateTree
+}
+
+// getStateTree returns Merkle tree of the latest calculation. If the tree is not cached, it makes the tree
+// with snapshots of account DBs without viewLock. The set of the last tree is tried first.
+func (ctx *Context) getStateTree() (*merkleTree, error) {
+	ctx.stateTreeBuildLock.Lock()
+	defer ctx.stateTreeBuildLock.Unlock()
+
+	isDB := ctx.DB
+	isDB.viewLock.RLock()
+	calcDoneBH := isDB.getCalcDoneBH()
+	cr, err := ReadCalculationResult(isDB.getCalculateResultDB(), calcDoneBH)
+	if err != nil || cr == nil {
+		isDB.viewLock.RUnlock()
+		return nil, fmt.Errorf("no calculation result of %d", calcDoneBH)
+	}
+	if len(cr.StateRoot) == 0 {
+		isDB.viewLock.RUnlock()
+		return nil, fmt.Errorf("no state root in calculation result of %d", calcDoneBH)
+	}
+	cached := ctx.getCachedStateTree()
+	if cached != nil && cached.blockHeight == calcDoneBH && bytes.Equal(cached.root(), cr.StateRoot) {
+		isDB.viewLock.RUnlock()
+		return cached, nil
+	}
+
+	// accounts of the latest calculation are in calculate DB.
+	// They are in query DB after next calculation toggled account DB or rollback restored backup account DB
+	sets := []int{isDB.getCalcDBSet()}
+	if cached != nil {
+		sets[0] = cached.set
+	}
+	sets = append(sets, 1-sets[0])
+	snapshots := make([][]db.Snapshot, len(sets))
+	isDB.closeLock.RLock()
+	defer isDB.closeLock.RUnlock()
+	for i, set := range sets {
+		for _, aDB := range isDB.getAccountDBSet(set) {
+			s, _ := aDB.GetSnapshot()
+			if e := s.New(); e != nil {
+				err = e
+				break
+			}
+			snapshots[i] = append(snapshots[i], s)
+		}
+	}
+	isDB.viewLock.RUnlock()
+	defer func() {
+		for _, ss := range snapshots {
+			for _, s := range ss {
+				s.Release()
+			}
+		}
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, ss := range snapshots {
+		iters := make([]db.Iterator, len(ss))
+		for j, s := range ss {
+			iters[j] = &snapshotIterator{s}
+		}
+		tree, err := buildStateTreeWithIterators(nil, calcDoneBH, iters)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(tree.root(), cr.StateRoot) {
+			tree.set = sets[i]
+			ctx.setStateTree(tree)
+			return tree, nil
+		}
+	}
+	return nil, fmt.Errorf("can't find accounts of state root %s", hex.EncodeToString(cr.StateRoot))
+}
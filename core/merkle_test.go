@@ -0,0 +1,97 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkle_proof(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 15, 16, 17, 33, 50} {
+		addresses := make([]common.Address, n)
+		leaves := make([][]byte, n)
+		for i := 0; i < n; i++ {
+			addresses[i] = *common.NewAddressFromString(fmt.Sprintf("hx%040x", i+1))
+			leaves[i] = AccountLeafHash(addresses[i], uint64(i), common.NewHexIntFromUint64(uint64(i*100)))
+		}
+		tree := newMerkleTree(100, addresses, leaves)
+		root := tree.root()
+		assert.Equal(t, merkleLevels(leaves)[len(merkleLevels(leaves))-1], tree.levels[len(tree.levels)-1])
+		if n == 0 {
+			assert.Equal(t, crypto.SHA3Sum256(nil), root)
+		}
+
+		for i, address := range addresses {
+			chunk := tree.chunkOf(address)
+			assert.Equal(t, i/merkleChunkSize, chunk)
+			start := chunk * merkleChunkSize
+			end := start + merkleChunkSize
+			if end > n {
+				end = n
+			}
+			proof, err := tree.proof(chunk, leaves[start:end], i-start)
+			assert.NoError(t, err)
+			assert.True(t, VerifyMerkleProof(root, leaves[i], proof), "n=%d, i=%d", n, i)
+			if n > 1 {
+				assert.False(t, VerifyMerkleProof(root, leaves[(i+1)%n], proof))
+			}
+
+			// leaves of other chunk
+			if end < n {
+				_, err = tree.proof(chunk, leaves[start+1:end+1], i-start)
+				assert.Error(t, err)
+			}
+		}
+		// address before all accounts
+		assert.Equal(t, -1, tree.chunkOf(*common.NewAddressFromString(fmt.Sprintf("hx%040x", 0))))
+
+		if n == 3 {
+			assert.Equal(t, merkleNodeHash(merkleNodeHash(leaves[0], leaves[1]), leaves[2]), root)
+		}
+	}
+}
+
+func TestMerkle_buildStateTree(t *testing.T) {
+	accounts := makeTestAccounts(40)
+	ctx := initTest(3)
+	defer finalizeTest(ctx)
+
+	for i, ia := range accounts {
+		ia.IScore.SetUint64(uint64(i * 10))
+	}
+	writeTestAccounts(accounts, ctx.DB.getCalculateDB)
+	tree, err := buildStateTree(nil, 100, ctx.DB.GetCalcDBList())
+	assert.NoError(t, err)
+	assert.Equal(t, (len(accounts)+merkleChunkSize-1)/merkleChunkSize, len(tree.levels[0]))
+
+	// accounts of a chunk
+	chunk, err := readStateTreeChunk(tree, 1, ctx.DB.GetCalcDBList())
+	assert.NoError(t, err)
+	assert.Equal(t, merkleChunkSize, len(chunk))
+	assert.Equal(t, tree.chunks[1], chunk[0].Address)
+	chunk, err = readStateTreeChunk(tree, 2, ctx.DB.GetCalcDBList())
+	assert.NoError(t, err)
+	assert.Equal(t, len(accounts)-2*merkleChunkSize, len(chunk))
+
+	// state root does not depend on DB count
+	dstDBs := openAccountDBList(testDir, testDBType, []string{"dst_1"})
+	defer closeAccountDBList(dstDBs)
+	assert.NoError(t, reshardAccounts(ctx.DB.GetCalcDBList(), dstDBs))
+	dstTree, err := buildStateTree(nil, 100, dstDBs)
+	assert.NoError(t, err)
+	assert.Equal(t, tree.root(), dstTree.root())
+
+	// different accounts
+	emptyTree, err := buildStateTree(nil, 100, ctx.DB.getQueryDBList())
+	assert.NoError(t, err)
+	assert.NotEqual(t, tree.root(), emptyTree.root())
+
+	// canceled
+	quit := make(chan struct{})
+	close(quit)
+	_, err = buildStateTree(quit, 100, ctx.DB.GetCalcDBList())
+	assert.Equal(t, errStateTreeCanceled, err)
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	metricsNamespace = "icon_rc"
+	metricsPath      = "/metrics"
+)
+
+var (
+	metricMsgCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "ipc",
+		Name:      "messages_total",
+		Help:      "The number of IPC messages handled",
+	}, []string{"msg"})
+	metricMsgError = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "ipc",
+		Name:      "message_errors_total",
+		Help:      "The number of IPC messages failed to handle",
+	}, []string{"msg"})
+	metricMsgDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "ipc",
+		Name:      "message_duration_seconds",
+		Help:      "Time taken to handle IPC message",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 12),
+	}, []string{"msg"})
+
+	metricCalcDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "calculate",
+		Name:      "duration_seconds",
+		Help:      "Time taken to calculate I-Score of a term",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+	metricCalcAccounts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "calculate",
+		Name:      "accounts",
+		Help:      "The number of accounts in the latest calculation",
+	})
+	metricCalcCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "calculate",
+		Name:      "total",
+		Help:      "The number of calculations",
+	}, []string{"result"})
+	metricRollbackCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "rollback_total",
+		Help:      "The number of rollbacks",
+	}, []string{"result"})
+)
+
+func observeMessage(msg uint, startTime time.Time, err error) {
+	name := MsgToString(msg)
+	metricMsgCount.WithLabelValues(name).Inc()
+	metricMsgDuration.WithLabelValues(name).Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		metricMsgError.WithLabelValues(name).Inc()
+	}
+}
+
+func observeCalculation(elapsedTime time.Duration, stats *Statistics) {
+	metricCalcCount.WithLabelValues("success").Inc()
+	metricCalcDuration.Observe(elapsedTime.Seconds())
+	metricCalcAccounts.Set(float64(stats.Accounts))
+}
+
+func observeCalculationFailure() {
+	metricCalcCount.WithLabelValues("failure").Inc()
+}
+
+func observeRollback(err error) {
+	if err != nil {
+		metricRollbackCount.WithLabelValues("failure").Inc()
+	} else {
+		metricRollbackCount.WithLabelValues("success").Inc()
+	}
+}
+
+// statusCollector reads status of I-Score DB when metrics are gathered
+type statusCollector struct {
+	ctx *Context
+
+	blockHeight   *prometheus.Desc
+	iScore        *prometheus.Desc
+	preCommitSize *prometheus.Desc
+}
+
+func newStatusCollector(ctx *Context) *statusCollector {
+	return &statusCollector{
+		ctx: ctx,
+		blockHeight: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "block_height"),
+			"Block height of Reward Calculator status",
+			[]string{"type"}, nil),
+		iScore: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "calculate", "iscore"),
+			"I-Score calculated in the latest calculation",
+			[]string{"type"}, nil),
+		preCommitSize: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "precommit_db_bytes"),
+			"Size of preCommit DB in bytes",
+			nil, nil),
+	}
+}
+
+func (sc *statusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sc.blockHeight
+	ch <- sc.iScore
+	ch <- sc.preCommitSize
+}
+
+func (sc *statusCollector) Collect(ch chan<- prometheus.Metric) {
+	idb := sc.ctx.DB
+
+	// block heights
+	heights := map[string]uint64{
+		"current":        idb.getCurrentBlockInfo().BlockHeight,
+		"calc_done":      idb.getCalcDoneBH(),
+		"prev_calc_done": idb.getPrevCalcDoneBH(),
+		"calculating":    idb.getCalculatingBH(),
+		"rollback_limit": idb.getRollbackLimitBH(),
+	}
+	for t, bh := range heights {
+		ch <- prometheus.MustNewConstMetric(sc.blockHeight, prometheus.GaugeValue, float64(bh), t)
+	}
+
+	// Beta1, Beta2, Beta3 and total I-Score of the latest calculation
+	bucket, _ := idb.getCalculateResultDB().GetBucket(db.PrefixCalcResult)
+	bs, _ := bucket.Get(common.Uint64ToBytes(idb.getCalcDoneBH()))
+	if bs != nil {
+		if cr, err := NewCalculationResultFromBytes(bs); err == nil {
+			ch <- prometheus.MustNewConstMetric(sc.iScore, prometheus.GaugeValue, hexIntToFloat(&cr.IScore), "total")
+			ch <- prometheus.MustNewConstMetric(sc.iScore, prometheus.GaugeValue, hexIntToFloat(&cr.Beta1), "beta1")
+			ch <- prometheus.MustNewConstMetric(sc.iScore, prometheus.GaugeValue, hexIntToFloat(&cr.Beta2), "beta2")
+			ch <- prometheus.MustNewConstMetric(sc.iScore, prometheus.GaugeValue, hexIntToFloat(&cr.Beta3), "beta3")
+		}
+	}
+
+	// size of preCommit DB
+	ch <- prometheus.MustNewConstMetric(sc.preCommitSize, prometheus.GaugeValue,
+		float64(dirSize(filepath.Join(idb.info.DBRoot, "preCommit"))))
+}
+
+func hexIntToFloat(v *common.HexInt) float64 {
+	f, _ := new(big.Float).SetInt(&v.Int).Float64()
+	return f
+}
+
+func dirSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func newMetricsRegistry(ctx *Context) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		prometheus.NewGoCollector(),
+		metricMsgCount,
+		metricMsgError,
+		metricMsgDuration,
+		metricCalcDuration,
+		metricCalcAccounts,
+		metricCalcCount,
+		metricRollbackCount,
+		newStatusCollector(ctx),
+	)
+	return reg
+}
+
+type metricsHandler struct {
+	gatherer prometheus.Gatherer
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mfs, err := h.gatherer.Gather()
+	if err != nil {
+		log.Printf("Failed to gather metrics. %v", err)
+		if len(mfs) == 0 {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	contentType := expfmt.Negotiate(r.Header)
+	w.Header().Set("Content-Type", string(contentType))
+	enc := expfmt.NewEncoder(w, contentType)
+	for _, mf := range mfs {
+		if err := enc.Encode(mf); err != nil {
+			log.Printf("Failed to encode metrics. %v", err)
+			return
+		}
+	}
+}
+
+// startMetricsServer serves metrics for Prometheus at http://address/metrics
+func startMetricsServer(ctx *Context, address string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, &metricsHandler{newMetricsRegistry(ctx)})
+	srv := &http.Server{Addr: listener.Addr().String(), Handler: mux}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server stopped. %v", err)
+		}
+	}()
+	log.Printf("Serve metrics at http://%s%s", srv.Addr, metricsPath)
+
+	return srv, nil
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_startMetricsServer(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	// write calculation result
+	ctx.DB.setCalcDoneBH(100)
+	stats := new(Statistics)
+	stats.Accounts = 3
+	stats.Beta1.SetUint64(10)
+	stats.Beta2.SetUint64(20)
+	stats.Beta3.SetUint64(30)
+	stats.TotalReward.SetUint64(60)
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), 100, stats, nil, nil, nil)
+
+	observeMessage(MsgQuery, time.Now(), nil)
+	observeCalculation(time.Second, stats)
+	observeRollback(nil)
+
+	srv, err := startMetricsServer(ctx, "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer srv.Close()
+
+	resp, err := http.Get("http://" + srv.Addr + metricsPath)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	metrics := string(body)
+
+	assert.Contains(t, metrics, `icon_rc_ipc_messages_total{msg="QUERY"} 1`)
+	assert.Contains(t, metrics, `icon_rc_ipc_message_duration_seconds_count{msg="QUERY"} 1`)
+	assert.Contains(t, metrics, `icon_rc_calculate_accounts 3`)
+	assert.Contains(t, metrics, `icon_rc_calculate_total{result="success"} 1`)
+	assert.Contains(t, metrics, `icon_rc_rollback_total{result="success"} 1`)
+	assert.Contains(t, metrics, `icon_rc_block_height{type="calc_done"} 100`)
+	assert.Contains(t, metrics, `icon_rc_calculate_iscore{type="beta1"} 10`)
+	assert.Contains(t, metrics, `icon_rc_calculate_iscore{type="beta2"} 20`)
+	assert.Contains(t, metrics, `icon_rc_calculate_iscore{type="beta3"} 30`)
+	assert.Contains(t, metrics, `icon_rc_calculate_iscore{type="total"} 60`)
+	assert.Contains(t, metrics, `icon_rc_precommit_db_bytes`)
+}
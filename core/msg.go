@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"time"
 
 	"github.com/icon-project/rewardcalculator/common"
 	"github.com/icon-project/rewardcalculator/common/codec"
@@ -16,23 +17,32 @@ import (
 )
 
 const (
-	IPCVersion uint64 = 2
-
-	MsgVersion              uint = 0
-	MsgClaim                     = 1
-	MsgQuery                     = 2
-	MsgCalculate                 = 3
-	MsgCommitBlock               = 4
-	MsgCommitClaim               = 5
-	MsgQueryCalculateStatus      = 6
-	MsgQueryCalculateResult      = 7
-	MsgRollBack                  = 8
-	MsgINIT                      = 9
-	MsgStartBlock                = 10
-
-	MsgNotify        = 100
-	MsgReady         = MsgNotify + 0
-	MsgCalculateDone = MsgNotify + 1
+	// IPCVersion is the latest version of IPC protocol. Version and capabilities are negotiated from it
+	IPCVersion uint64 = 3
+	// IPCMinVersion is the oldest version. READY and VERSION without negotiation have it
+	IPCMinVersion uint64 = 2
+
+	MsgVersion                uint = 0
+	MsgClaim                       = 1
+	MsgQuery                       = 2
+	MsgCalculate                   = 3
+	MsgCommitBlock                 = 4
+	MsgCommitClaim                 = 5
+	MsgQueryCalculateStatus        = 6
+	MsgQueryCalculateResult        = 7
+	MsgRollBack                    = 8
+	MsgINIT                        = 9
+	MsgStartBlock                  = 10
+	MsgQueryRewardLedger           = 11
+	MsgQueryBatch                  = 12
+	MsgQueryProof                  = 13
+	MsgQueryEstimate               = 14
+	MsgQueryCalculateProgress      = 15
+
+	MsgNotify            = 100
+	MsgReady             = MsgNotify + 0
+	MsgCalculateDone     = MsgNotify + 1
+	MsgCalculateProgress = MsgNotify + 2
 
 	MsgDebug = 1000
 )
@@ -65,6 +75,18 @@ func MsgToString(msg uint) string {
 		return "INIT"
 	case MsgStartBlock:
 		return "START_BLOCK"
+	case MsgQueryRewardLedger:
+		return "QUERY_REWARD_LEDGER"
+	case MsgQueryBatch:
+		return "QUERY_BATCH"
+	case MsgQueryProof:
+		return "QUERY_PROOF"
+	case MsgQueryEstimate:
+		return "QUERY_ESTIMATE"
+	case MsgQueryCalculateProgress:
+		return "QUERY_CALCULATE_PROGRESS"
+	case MsgCalculateProgress:
+		return "CALCULATE_PROGRESS"
 	case MsgDebug:
 		return "DEBUG"
 	default:
@@ -72,6 +94,11 @@ func MsgToString(msg uint) string {
 	}
 }
 
+// IsNotification returns true for messages which Reward Calculator sends without request
+func IsNotification(msg uint) bool {
+	return msg >= MsgNotify && msg < MsgDebug
+}
+
 func MsgDataToString(data interface{}) string {
 	b, err := json.Marshal(data)
 	if err != nil {
@@ -82,19 +109,24 @@ func MsgDataToString(data interface{}) string {
 
 type msgHandler struct {
 	mgr  *manager
-	conn ipc.Connection
+	conn *trackedConn
 }
 
 func newConnection(m *manager, c ipc.Connection) (*msgHandler, error) {
 	handler := &msgHandler{
 		mgr:  m,
-		conn: c,
+		conn: m.conns.add(c, m.monitorMode),
 	}
 
 	c.SetHandler(MsgVersion, handler)
 	c.SetHandler(MsgQuery, handler)
 	c.SetHandler(MsgQueryCalculateStatus, handler)
 	c.SetHandler(MsgQueryCalculateResult, handler)
+	c.SetHandler(MsgQueryRewardLedger, handler)
+	c.SetHandler(MsgQueryBatch, handler)
+	c.SetHandler(MsgQueryProof, handler)
+	c.SetHandler(MsgQueryEstimate, handler)
+	c.SetHandler(MsgQueryCalculateProgress, handler)
 	if m.monitorMode == true {
 		c.SetHandler(MsgDebug, handler)
 	} else {
@@ -109,11 +141,11 @@ func newConnection(m *manager, c ipc.Connection) (*msgHandler, error) {
 
 	// send READY message to peer
 	cBI := handler.mgr.ctx.DB.getCurrentBlockInfo()
-	err := sendVersion(c, MsgReady, 0, cBI.BlockHeight, cBI.BlockHash)
+	err := sendVersion(handler.conn, MsgReady, 0, cBI.BlockHeight, cBI.BlockHash)
 	if err != nil {
 		log.Printf("Failed to send READY message")
 	} else {
-		log.Printf("Accept new connection and send READY message")
+		log.Printf("Accept new connection %d and send READY message", handler.conn.id)
 	}
 
 	return handler, err
@@ -121,38 +153,86 @@ func newConnection(m *manager, c ipc.Connection) (*msgHandler, error) {
 
 func (mh *msgHandler) HandleMessage(c ipc.Connection, msg uint, id uint32, data []byte) error {
 	log.Printf("Get message. (msg:%s, id:%d)", MsgToString(msg), id)
+	if mh.mgr.auth != nil && isSignedMessage(msg) {
+		var err error
+		if data, err = mh.mgr.auth.verify(mh.conn, msg, id, data); err != nil {
+			log.Printf("Failed to authenticate %s message of connection %d. %v",
+				MsgToString(msg), mh.conn.id, err)
+			return err
+		}
+	}
+	var handler func(ipc.Connection, uint32, []byte) error
 	switch msg {
 	case MsgVersion:
-		go mh.version(c, id)
+		// negotiated capabilities apply to the following messages
+		mh.beginTask(msg)
+		return mh.instrument(msg, mh.version, id, data)
 	case MsgClaim:
-		go mh.claim(c, id, data)
+		handler = mh.claim
 	case MsgQuery:
-		go mh.query(c, id, data)
+		handler = mh.query
 	case MsgCalculate:
-		go mh.calculate(c, id, data)
+		handler = mh.calculate
 	case MsgStartBlock:
-		go mh.startBlock(c, id, data)
+		handler = mh.startBlock
 	case MsgCommitBlock:
-		go mh.commitBlock(c, id, data)
+		handler = mh.commitBlock
 	case MsgDebug:
-		go mh.debug(c, id, data)
+		handler = mh.debug
 	case MsgCommitClaim:
-		go mh.commitClaim(c, id, data)
+		handler = mh.commitClaim
 	case MsgQueryCalculateStatus:
-		go mh.queryCalculateStatus(c, id, data)
+		handler = mh.queryCalculateStatus
 	case MsgQueryCalculateResult:
-		go mh.queryCalculateResult(c, id, data)
+		handler = mh.queryCalculateResult
 	case MsgRollBack:
 		// do not process other messages while process Rollback message
-		return mh.rollback(c, id, data)
+		mh.beginTask(msg)
+		return mh.instrument(msg, mh.rollback, id, data)
 	case MsgINIT:
-		go mh.init(c, id, data)
+		handler = mh.init
+	case MsgQueryRewardLedger:
+		handler = mh.queryRewardLedger
+	case MsgQueryBatch:
+		handler = mh.queryBatch
+	case MsgQueryProof:
+		handler = mh.queryProof
+	case MsgQueryEstimate:
+		handler = mh.queryEstimate
+	case MsgQueryCalculateProgress:
+		handler = mh.queryCalculateProgress
 	default:
 		return errors.Errorf("UnknownMessage(%d)", msg)
 	}
+	if name := messageCapability(msg); len(name) > 0 && !mh.conn.hasCapability(name) {
+		return errors.Errorf("NotNegotiatedMessage(%s)", name)
+	}
+
+	mh.beginTask(msg)
+	go mh.instrument(msg, handler, id, data)
 	return nil
 }
 
+// beginTask registers the request as a message task and a request in flight of the connection.
+// instrument finishes it.
+func (mh *msgHandler) beginTask(msg uint) {
+	mh.mgr.AddMsgTask()
+	mh.conn.begin(msg)
+}
+
+// instrument calls message handler with the connection and updates metrics of the message.
+// Replies to the connection are dropped after it is closed.
+func (mh *msgHandler) instrument(msg uint, handler func(ipc.Connection, uint32, []byte) error,
+	id uint32, data []byte) error {
+	defer mh.mgr.DoneMsgTask()
+	defer mh.conn.end(msg)
+
+	startTime := time.Now()
+	err := handler(mh.conn, id, data)
+	observeMessage(msg, startTime, err)
+	return err
+}
+
 type ResponseVersion struct {
 	Version     uint64
 	BlockHeight uint64
@@ -164,16 +244,58 @@ func (rv *ResponseVersion) String() string {
 		rv.Version, rv.BlockHeight, hex.EncodeToString(rv.BlockHash[:]))
 }
 
-func (mh *msgHandler) version(c ipc.Connection, id uint32) error {
-	mh.mgr.AddMsgTask()
+// version responds with ResponseVersion to VERSION without data.
+// With VersionRequest, it negotiates version and capabilities and responds with ResponseVersionNegotiation.
+func (mh *msgHandler) version(c ipc.Connection, id uint32, data []byte) error {
 	cBI := mh.mgr.ctx.DB.getCurrentBlockInfo()
-	mh.mgr.DoneMsgTask()
-	return sendVersion(c, MsgVersion, id, cBI.BlockHeight, cBI.BlockHash)
+
+	var req *VersionRequest
+	if len(data) > 0 {
+		if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+			return err
+		}
+	}
+	if req == nil {
+		return sendVersion(c, MsgVersion, id, cBI.BlockHeight, cBI.BlockHash)
+	}
+	log.Printf("\t VERSION request: Version: %d, MinVersion: %d, Capabilities: %v",
+		req.Version, req.MinVersion, req.Capabilities)
+
+	required := make([]string, 0)
+	if mh.mgr.auth != nil {
+		required = append(required, CapSignedMessage)
+	}
+	version, caps, negotiateErr := negotiate(req, required)
+	resp := ResponseVersionNegotiation{
+		Version:      version,
+		BlockHeight:  cBI.BlockHeight,
+		BlockHash:    cBI.BlockHash,
+		MinVersion:   IPCMinVersion,
+		Capabilities: SupportedCapabilities(),
+	}
+	if negotiateErr == nil {
+		mh.conn.setCapabilities(caps)
+		resp.Capabilities = caps.names()
+		if mh.mgr.auth != nil {
+			resp.Nonce = mh.conn.nonce
+		}
+	}
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgVersion), id, resp.String())
+	if err := c.Send(MsgVersion, id, &resp); err != nil {
+		return err
+	}
+	if negotiateErr != nil {
+		// connection is closed with the error
+		log.Printf("Refuse connection %d. %v", mh.conn.id, negotiateErr)
+		return negotiateErr
+	}
+	return nil
 }
 
 func sendVersion(c ipc.Connection, msg uint, id uint32, blockHeight uint64, blockHash [BlockHashSize]byte) error {
 	resp := ResponseVersion{
-		Version:     IPCVersion,
+		Version:     IPCMinVersion,
 		BlockHeight: blockHeight,
 		BlockHash:   blockHash,
 	}
@@ -213,7 +335,6 @@ func (rq *ResponseQuery) String() string {
 
 func (mh *msgHandler) query(c ipc.Connection, id uint32, data []byte) error {
 	var req Query
-	mh.mgr.AddMsgTask()
 	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
 		return err
 	}
@@ -221,25 +342,23 @@ func (mh *msgHandler) query(c ipc.Connection, id uint32, data []byte) error {
 
 	resp := DoQuery(mh.mgr.ctx, &req)
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQuery), id, resp.String())
 	return c.Send(MsgQuery, id, &resp)
 }
 
 func DoQuery(ctx *Context, req *Query) *ResponseQuery {
-	var claim *Claim = nil
-	var ia *IScoreAccount = nil
 	isDB := ctx.DB
 
-	// make response
-	var resp ResponseQuery
-	resp.Address = req.Address
+	isDB.viewLock.RLock()
+	defer isDB.viewLock.RUnlock()
 
 	// search from preCommit DB
 	if len(req.TXHash) != 0 {
 		pcDB := isDB.getPreCommitDB()
 		pc, _ := findPreCommit(pcDB, req.Address, req.BlockHeight, req.BlockHash[:])
 		if pc != nil && bytes.Compare(pc.TXHash, req.TXHash[:]) == 0 && !pc.IsEmpty() {
+			var resp ResponseQuery
+			resp.Address = req.Address
 			resp.BlockHeight = pc.Data.BlockHeight
 			resp.IScore.SetInt64(0)
 			return &resp
@@ -249,25 +368,31 @@ func DoQuery(ctx *Context, req *Query) *ResponseQuery {
 	// read from claim DB
 	cDB := isDB.getClaimDB()
 	bucket, _ := cDB.GetBucket(db.PrefixIScore)
-	bs, _ := bucket.Get(req.Address.Bytes())
-	if bs != nil {
-		claim, _ = NewClaimFromBytes(bs)
-	}
+	claimBytes, _ := bucket.Get(req.Address.Bytes())
 
 	// read from Query DB
 	qDB := isDB.getQueryDB(req.Address)
 	bucket, _ = qDB.GetBucket(db.PrefixIScore)
-	bs, _ = bucket.Get(req.Address.Bytes())
-	if bs != nil {
-		ia, _ = NewIScoreAccountFromBytes(bs)
-		resp.BlockHeight = ia.BlockHeight
-	} else {
+	bs, _ := bucket.Get(req.Address.Bytes())
+
+	return newResponseQuery(req.Address, bs, claimBytes)
+}
+
+// newResponseQuery makes QUERY response with the account and the claim in DB. nil means there is no data in DB
+func newResponseQuery(address common.Address, accountBytes []byte, claimBytes []byte) *ResponseQuery {
+	var resp ResponseQuery
+	resp.Address = address
+
+	if accountBytes == nil {
 		// No Info. about account
 		return &resp
 	}
+	ia, _ := NewIScoreAccountFromBytes(accountBytes)
+	resp.BlockHeight = ia.BlockHeight
 
-	if claim != nil {
+	if claimBytes != nil {
 		// subtract claimed I-Score
+		claim, _ := NewClaimFromBytes(claimBytes)
 		ia.IScore.Sub(&ia.IScore.Int, &claim.Data.IScore.Int)
 	}
 
@@ -288,7 +413,6 @@ func (resp *ResponseInit) String() string {
 
 func (mh *msgHandler) init(c ipc.Connection, id uint32, data []byte) error {
 	var blockHeight uint64
-	mh.mgr.AddMsgTask()
 	if _, err := codec.MP.UnmarshalFromBytes(data, &blockHeight); err != nil {
 		return err
 	}
@@ -301,7 +425,6 @@ func (mh *msgHandler) init(c ipc.Connection, id uint32, data []byte) error {
 		resp.Success = false
 	}
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgINIT), id, resp.String())
 	return c.Send(MsgINIT, id, &resp)
 }
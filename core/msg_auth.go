@@ -0,0 +1,137 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/crypto"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+// SignedMessage is the data of a mutating message when IPC authentication is enabled.
+// Signature is [R|S|V] of SHA3-256(msg(8 bytes) || id(4 bytes) || nonce || Data).
+// nonce is issued by Reward Calculator for each connection in the response of VERSION
+type SignedMessage struct {
+	Data      []byte // msgpack encoded data of the message
+	Signature []byte
+}
+
+func messageHash(msg uint, id uint32, nonce []byte, data []byte) []byte {
+	buf := make([]byte, 12+len(nonce)+len(data))
+	binary.BigEndian.PutUint64(buf, uint64(msg))
+	binary.BigEndian.PutUint32(buf[8:], id)
+	copy(buf[12:], nonce)
+	copy(buf[12+len(nonce):], data)
+	return crypto.SHA3Sum256(buf)
+}
+
+// NewSignedMessage encodes data and signs it with the message, id and nonce of the connection
+func NewSignedMessage(key *crypto.PrivateKey, nonce []byte, msg uint, id uint32, data interface{}) (*SignedMessage, error) {
+	bs, err := codec.MP.MarshalToBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.NewSignature(messageHash(msg, id, nonce, bs), key)
+	if err != nil {
+		return nil, err
+	}
+	sm := &SignedMessage{Data: bs}
+	if sm.Signature, err = sig.SerializeRSV(); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+// isSignedMessage returns true for messages which change I-Score DB
+func isSignedMessage(msg uint) bool {
+	switch msg {
+	case MsgClaim, MsgCommitClaim, MsgCommitBlock, MsgCalculate, MsgRollBack, MsgINIT:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseAuthKeys parses comma separated public keys in hex
+func ParseAuthKeys(keys string) ([]*crypto.PublicKey, error) {
+	pubKeys := make([]*crypto.PublicKey, 0)
+	for _, s := range strings.Split(keys, ",") {
+		s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+		if len(s) == 0 {
+			continue
+		}
+		bs, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s. %v", s, err)
+		}
+		pubKey, err := crypto.ParsePublicKey(bs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %s. %v", s, err)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	return pubKeys, nil
+}
+
+// msgAuth verifies signed messages with keys of ICON Service
+type msgAuth struct {
+	keys []*crypto.PublicKey
+}
+
+func newMsgAuth(keys string) (*msgAuth, error) {
+	pubKeys, err := ParseAuthKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(pubKeys) == 0 {
+		return nil, nil
+	}
+	return &msgAuth{keys: pubKeys}, nil
+}
+
+// verify checks the signature and the id of the message and returns its data.
+// Signature must have the nonce of the connection, so messages of other connections can't be replayed,
+// and id must be greater than the id of the last signed message of the connection.
+func (a *msgAuth) verify(tc *trackedConn, msg uint, id uint32, data []byte) ([]byte, error) {
+	var sm SignedMessage
+	if _, err := codec.MP.UnmarshalFromBytes(data, &sm); err != nil {
+		return nil, fmt.Errorf("invalid signed message. %v", err)
+	}
+	sig, err := crypto.ParseSignature(sm.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature. %v", err)
+	}
+	pubKey, err := sig.RecoverPublicKey(messageHash(msg, id, tc.nonce, sm.Data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature. %v", err)
+	}
+	if !a.allowed(pubKey) {
+		return nil, fmt.Errorf("not allowed key %s", pubKey.String())
+	}
+	if err = tc.checkSignedID(id); err != nil {
+		return nil, err
+	}
+	return sm.Data, nil
+}
+
+func (a *msgAuth) allowed(pubKey *crypto.PublicKey) bool {
+	for _, key := range a.keys {
+		if key.Equal(pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageSigner signs mutating messages to Reward Calculator with the nonce of the connection
+func messageSigner(key *crypto.PrivateKey, nonce func() []byte) ipc.Encoder {
+	return func(msg uint, id uint32, data interface{}) (interface{}, error) {
+		if !isSignedMessage(msg) {
+			return data, nil
+		}
+		return NewSignedMessage(key, nonce(), msg, id, data)
+	}
+}
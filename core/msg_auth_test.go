@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAuthKeys(t *testing.T) {
+	_, pub1 := crypto.GenerateKeyPair()
+	_, pub2 := crypto.GenerateKeyPair()
+
+	keys, err := ParseAuthKeys("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(keys))
+
+	keys, err = ParseAuthKeys(pub1.String() + ", " + hex.EncodeToString(pub2.SerializeUncompressed()))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(keys))
+	assert.True(t, keys[0].Equal(pub1))
+	assert.True(t, keys[1].Equal(pub2))
+
+	_, err = ParseAuthKeys("0x1234")
+	assert.Error(t, err)
+	_, err = ParseAuthKeys("xyz")
+	assert.Error(t, err)
+}
+
+func TestMsgHandler_signedMessage(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	key, pub := crypto.GenerateKeyPair()
+	otherKey, _ := crypto.GenerateKeyPair()
+	m := newTestManager(ctx)
+	m.auth = &msgAuth{keys: []*crypto.PublicKey{pub}}
+
+	conn := new(testConn)
+	assert.NoError(t, m.OnConnect(conn))
+	mh := &msgHandler{mgr: m, conn: m.conns.conns[conn]}
+
+	signed := func(key *crypto.PrivateKey, msg uint, id uint32, data interface{}) []byte {
+		sm, err := NewSignedMessage(key, mh.conn.nonce, msg, id, data)
+		assert.NoError(t, err)
+		return codec.MP.MustMarshalToBytes(sm)
+	}
+	blockHeight := uint64(1)
+
+	// not signed
+	assert.Error(t, mh.HandleMessage(conn, MsgINIT, 1, codec.MP.MustMarshalToBytes(blockHeight)))
+	// signed with other key
+	assert.Error(t, mh.HandleMessage(conn, MsgINIT, 1, signed(otherKey, MsgINIT, 1, blockHeight)))
+	// signature of other message
+	assert.Error(t, mh.HandleMessage(conn, MsgINIT, 1, signed(key, MsgCalculate, 1, blockHeight)))
+	assert.Equal(t, 1, conn.count())
+
+	// signed with allowed key
+	assert.NoError(t, mh.HandleMessage(conn, MsgINIT, 2, signed(key, MsgINIT, 2, blockHeight)))
+	assert.True(t, waitMsgTasks(m))
+	assert.Equal(t, 2, conn.count())
+	assert.Equal(t, uint(MsgINIT), conn.msgs[1])
+	assert.Equal(t, ResponseInit{Success: true, BlockHeight: blockHeight}, *conn.data[1].(*ResponseInit))
+
+	// replay
+	assert.Error(t, mh.HandleMessage(conn, MsgINIT, 2, signed(key, MsgINIT, 2, blockHeight)))
+	assert.Error(t, mh.HandleMessage(conn, MsgINIT, 1, signed(key, MsgINIT, 1, blockHeight)))
+
+	// messages which don't change DB are not signed
+	assert.NoError(t, mh.HandleMessage(conn, MsgVersion, 3, nil))
+	assert.True(t, waitMsgTasks(m))
+	assert.Equal(t, 3, conn.count())
+
+	// message of the connection is replayed on a new connection
+	replayed := signed(key, MsgINIT, 4, blockHeight)
+	conn2 := new(testConn)
+	assert.NoError(t, m.OnConnect(conn2))
+	mh2 := &msgHandler{mgr: m, conn: m.conns.conns[conn2]}
+	assert.NotEqual(t, mh.conn.nonce, mh2.conn.nonce)
+	assert.Error(t, mh2.HandleMessage(conn2, MsgINIT, 4, replayed))
+
+	// signed with the nonce of the new connection
+	sm, err := NewSignedMessage(key, mh2.conn.nonce, MsgINIT, 1, blockHeight)
+	assert.NoError(t, err)
+	assert.NoError(t, mh2.HandleMessage(conn2, MsgINIT, 1, codec.MP.MustMarshalToBytes(sm)))
+	assert.True(t, waitMsgTasks(m))
+}
+
+func TestMsgHandler_versionNonce(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	_, pub := crypto.GenerateKeyPair()
+	m := newTestManager(ctx)
+	m.auth = &msgAuth{keys: []*crypto.PublicKey{pub}}
+
+	conn := new(testConn)
+	assert.NoError(t, m.OnConnect(conn))
+	mh := &msgHandler{mgr: m, conn: m.conns.conns[conn]}
+
+	req := VersionRequest{Version: IPCVersion, MinVersion: IPCMinVersion, Capabilities: []string{CapSignedMessage}}
+	assert.NoError(t, mh.HandleMessage(conn, MsgVersion, 1, codec.MP.MustMarshalToBytes(&req)))
+	assert.True(t, waitMsgTasks(m))
+	resp := conn.data[1].(*ResponseVersionNegotiation)
+	assert.Equal(t, connNonceSize, len(resp.Nonce))
+	assert.Equal(t, mh.conn.nonce, resp.Nonce)
+}
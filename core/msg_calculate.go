@@ -20,19 +20,8 @@ import (
 
 const (
 	writeBatchCount = 10
-
-	minRewardRep = 200
-
-	blocksPerYear    = 15552000
-	gvDivider        = 10000
-	iScoreMultiplier = 1000
-	rewardDivider    = blocksPerYear * gvDivider / iScoreMultiplier
-
-	MinDelegation = blocksPerYear / iScoreMultiplier * (gvDivider / minRewardRep)
 )
 
-var BigIntRewardDivider = big.NewInt(rewardDivider)
-
 type CalculateRequest struct {
 	Path        string
 	BlockHeight uint64
@@ -82,14 +71,38 @@ type CalculateDone struct {
 	BlockHeight uint64
 	IScore      common.HexInt
 	StateHash   []byte
+	StateRoot   []byte // Merkle root of all accounts
+	Beta1       common.HexInt
+	Beta2       common.HexInt
+	Beta3       common.HexInt
+	Signature   []byte // signature of ResultAttestation with node key. empty if node key is not set
 }
 
 func (cd *CalculateDone) String() string {
-	return fmt.Sprintf("Success: %s, BlockHeight: %d, IScore: %s, StateHash: %s",
+	return fmt.Sprintf("Success: %s, BlockHeight: %d, IScore: %s, StateHash: %s, StateRoot: %s, Signature: %s",
 		strconv.FormatBool(cd.Success),
 		cd.BlockHeight,
 		cd.IScore.String(),
-		hex.EncodeToString(cd.StateHash))
+		hex.EncodeToString(cd.StateHash),
+		hex.EncodeToString(cd.StateRoot),
+		hex.EncodeToString(cd.Signature))
+}
+
+// encodeFor omits StateRoot and fields of signed result which are not negotiated
+func (cd *CalculateDone) encodeFor(caps capabilities) interface{} {
+	if caps.has(CapSignedResult) {
+		return cd
+	}
+	fields := []interface{}{cd.Success, cd.BlockHeight, &cd.IScore, cd.StateHash}
+	if caps.has(CapStateRoot) {
+		fields = append(fields, cd.StateRoot)
+	}
+	return fields
+}
+
+func (cd *CalculateDone) Attestation() *ResultAttestation {
+	return newResultAttestation(cd.BlockHeight, cd.StateHash, cd.StateRoot, &cd.IScore,
+		&cd.Beta1, &cd.Beta2, &cd.Beta3)
 }
 
 func calculateDelegationReward(ctx *Context, delegationInfo *DelegateData, start uint64, end uint64,
@@ -105,32 +118,18 @@ func calculateDelegationReward(ctx *Context, delegationInfo *DelegateData, start
 
 	total := new(common.HexInt)
 
-	// period in gv
-	for i, gv := range ctx.GV {
-		var s, e = start, end
-
-		if start < gv.BlockHeight {
-			s = gv.BlockHeight
-		}
-		if i+1 < len(ctx.GV) && ctx.GV[i+1].BlockHeight < end {
-			e = ctx.GV[i+1].BlockHeight
-		}
-
-		if e <= s {
-			continue
+	// period in gv and reward policy
+	ctx.forEachRewardPeriod(start, end, func(s uint64, e uint64, gv *GovernanceVariable, policy RewardPolicy) {
+		if policy.MinDelegation(ctx.network) > delegationInfo.Delegate.Uint64() {
+			// not enough delegation
+			return
 		}
-		period := common.NewHexIntFromUint64(e - s)
-
-		// reward = delegation amount * period * GV / rewardDivider
-		var reward common.HexInt
-		reward.Mul(&delegationInfo.Delegate.Int, &period.Int)
-		reward.Mul(&reward.Int, &gv.RewardRep.Int)
-		reward.Div(&reward.Int, BigIntRewardDivider)
+		reward := policy.DelegationReward(ctx.network, &delegationInfo.Delegate, e-s, gv)
 
 		// update total
 		total.Add(&total.Int, &reward.Int)
-		WriteBeta3Info(ctx, rewardAddress, gv.RewardRep.Uint64(), delegationInfo, period.Uint64(), e)
-	}
+		WriteBeta3Info(ctx, rewardAddress, gv.RewardRep.Uint64(), delegationInfo, e-s, e)
+	})
 
 	return total
 }
@@ -150,11 +149,6 @@ func calculateIScore(ctx *Context, ia *IScoreAccount, blockHeight uint64) (bool,
 	}
 
 	for _, dg := range ia.Delegations {
-		if MinDelegation > dg.Delegate.Uint64() {
-			// not enough delegation
-			continue
-		}
-
 		_, ok := ctx.PRepCandidates[dg.Address]
 		if ok == false {
 			// there is no P-Rep
@@ -180,19 +174,57 @@ func calculateIScore(ctx *Context, ia *IScoreAccount, blockHeight uint64) (bool,
 	return true, totalReward
 }
 
-func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db.Database, ctx *Context, blockHeight uint64, batchCount uint64) (uint64, *Statistics, []byte) {
+func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db.Database, ctx *Context,
+	blockHeight uint64, blockHash []byte, header *IISSHeader, batchCount uint64,
+	checkpoint *CalcCheckpoint) (uint64, *Statistics, []byte) {
 
 	iter, _ := readDB.GetIterator()
 	bucket, _ := writeDB.GetBucket(db.PrefixIScore)
 	batch, _ := writeDB.GetBatch()
-	var entries, count uint64 = 0, 0
+	var entries, count, calculated uint64 = 0, 0, 0
 	h := sha3.NewShake256()
 	stateHash := make([]byte, 64)
 	stats := new(Statistics)
 	checkInterrupt := false
+	var start, lastKey []byte
+
+	// continue from checkpoint
+	if checkpoint != nil {
+		var err error
+		if count, err = restoreCalcCheckpoint(checkpoint, writeDB, h); err != nil {
+			log.Printf("Failed to restore checkpoint of %d. calculate from the first account. %v", index, err)
+			h.Reset()
+			count = 0
+		} else {
+			stats.Accounts = checkpoint.Accounts
+			ctx.progress.restoreAccounts(index, checkpoint.Accounts)
+			stats.Beta3.Set(&checkpoint.Beta3.Int)
+			lastKey = append(lastKey, checkpoint.LastKey...)
+			if len(lastKey) > 0 {
+				start = nextKey(lastKey)
+			}
+			log.Printf("Continue calculate %d from checkpoint. %s", index, checkpoint.String())
+		}
+	}
+
+	writeBatch := func() {
+		if batchCount > 0 {
+			if err := batch.Write(); err != nil {
+				log.Printf("Failed to write batch\n")
+			}
+			batch.Reset()
+		}
+	}
+	writeCheckpoint := func() {
+		writeBatch()
+		cp := newCalcCheckpoint(index, blockHeight, blockHash, header, ctx.DB.info.DBCount, lastKey, count, stats)
+		ctx.DB.writeCalcCheckpoint(cp)
+	}
 
-	batch.New()
-	iter.New(nil, nil)
+	if batchCount > 0 {
+		batch.New()
+	}
+	iter.New(start, nil)
 	for entries = 0; iter.Next(); entries++ {
 		// check quit message
 		select {
@@ -213,39 +245,44 @@ func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db
 			return 0, stats, nil
 		}
 		ia.Address = *common.NewAddress(key)
+		lastKey = append(lastKey[:0], iter.Key()...)
 
 		// update Statistics account
 		stats.Increase("Accounts", uint64(1))
+		ctx.progress.addAccount(index)
 
 		// calculate
 		ok, reward := calculateIScore(ctx, ia, blockHeight)
-		if ok == false {
-			continue
-		}
-
-		if batchCount > 0 {
-			batch.Set(iter.Key(), ia.Bytes())
-
-			// write batch to DB
-			if entries == batchCount {
-				err = batch.Write()
-				if err != nil {
-					log.Printf("Failed to write batch\n")
+		if ok == true {
+			if batchCount > 0 {
+				batch.Set(iter.Key(), ia.Bytes())
+
+				// write batch to DB
+				if entries == batchCount {
+					writeBatch()
+					entries = 0
 				}
-				batch.Reset()
-				entries = 0
+			} else {
+				bucket.Set(key, ia.Bytes())
 			}
-		} else {
-			bucket.Set(key, ia.Bytes())
-		}
 
-		// update stateHash
-		h.Write(ia.BytesForHash())
+			// update stateHash
+			h.Write(ia.BytesForHash())
 
-		// update Statistics
-		stats.Increase("Beta3", *reward)
+			// update Statistics
+			stats.Increase("Beta3", *reward)
 
-		count++
+			// update reward ledger
+			setRewardLedger(ctx, blockHeight, ia.Address, rewardBeta3, &reward.Int)
+
+			count++
+		}
+
+		// write checkpoint periodically
+		calculated++
+		if calculated%calcCheckpointCount == 0 {
+			writeCheckpoint()
+		}
 	}
 	// finalize iterator
 	iter.Release()
@@ -255,14 +292,8 @@ func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db
 	}
 
 	if checkInterrupt != true {
-		// write batch to DB
-		if batchCount > 0 {
-			err := batch.Write()
-			if err != nil {
-				log.Printf("Failed to write batch\n")
-			}
-			batch.Reset()
-		}
+		// write batch to DB and checkpoint of the end
+		writeCheckpoint()
 
 		// get stateHash if there is update
 		if count > 0 {
@@ -273,7 +304,9 @@ func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db
 
 		return count, stats, stateHash
 	} else {
-		batch.Reset()
+		if batchCount > 0 {
+			batch.Reset()
+		}
 		h.Reset()
 		log.Printf("Quit calculate %d with signal", index)
 		return 0, stats, nil
@@ -294,7 +327,6 @@ func sendCalculateACK(c ipc.Connection, id uint32, status uint16, blockHeight ui
 func (mh *msgHandler) calculate(c ipc.Connection, id uint32, data []byte) error {
 	success := true
 	var req CalculateRequest
-	mh.mgr.AddMsgTask()
 	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
 		return err
 	}
@@ -310,6 +342,7 @@ func (mh *msgHandler) calculate(c ipc.Connection, id uint32, data []byte) error
 	if err == nil {
 		cleanupIISSData(req.Path)
 	} else {
+		observeCalculationFailure()
 		log.Printf("Failed to calculate. %v", err)
 		success = false
 	}
@@ -324,18 +357,22 @@ func (mh *msgHandler) calculate(c ipc.Connection, id uint32, data []byte) error
 		resp.IScore.SetUint64(0)
 	}
 	resp.StateHash = stateHash
+	if success {
+		resp.StateRoot = ctx.getStateRoot(blockHeight)
+		if cr, _ := ReadCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight); cr != nil {
+			resp.Beta1 = cr.Beta1
+			resp.Beta2 = cr.Beta2
+			resp.Beta3 = cr.Beta3
+			resp.Signature = cr.Signature
+		}
+	}
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgCalculateDone), 0, resp.String())
-	return c.Send(MsgCalculateDone, 0, &resp)
+	return mh.mgr.sendNotification(c, MsgCalculateDone, &resp)
 }
 
 func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ipc.Connection, id uint32) (error, uint64, *Statistics, []byte) {
-	h := sha3.NewShake256()
-	stateHash := make([]byte, 64)
-	stats := new(Statistics)
 	reload := isReloadRequest(req.BlockHeight, id)
-	var newAccount uint64
 
 	iScoreDB := ctx.DB
 	blockHeight := req.BlockHeight
@@ -370,7 +407,7 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 		blockHeight = iScoreDB.getCalcDoneBH() + 1
 	}
 
-	ctx.DB.setCalculatingBH(blockHeight)
+	startCalculation(ctx, blockHeight, req.BlockHash)
 
 	// check blockHeight and blockHash
 	calcDoneBH := iScoreDB.getCalcDoneBH()
@@ -390,17 +427,110 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 	}
 
 	// set toggle block height with Term start block height
-	ctx.DB.toggleAccountDB(blockHeight + 1)
+	err := ctx.DB.toggleAccountDB(blockHeight + 1)
 
-	// send response of CALCULATE after toggle DB
+	// send response of CALCULATE after toggle DB. CALCULATE_DONE reports failure of toggle
 	sendCalculateACK(c, id, CalcRespStatusOK, blockHeight)
+	if err != nil {
+		ctx.DB.resetCalculatingBH()
+		return fmt.Errorf("failed to toggle account DB. %v", err), blockHeight, nil, nil
+	}
+
+	success := false
+	ctx.progress.start(ctx, blockHeight)
+	defer func() {
+		ctx.progress.finish(success)
+	}()
 
 	// close and backup old query DB and open new calculate DB
-	ctx.DB.resetAccountDB(blockHeight, ctx.DB.getCalcDoneBH())
+	if err := ctx.DB.resetAccountDB(blockHeight); err != nil {
+		return fmt.Errorf("failed to backup account DB. %v", err), blockHeight, nil, nil
+	}
+
+	// continue calculation with checkpoints when Reward Calculator restarted while calculating
+	resume := reload && iScoreDB.hasCalcCheckpoint(blockHeight, req.BlockHash, header)
+	if !resume {
+		// delete reward ledger and checkpoints written by canceled calculation
+		deleteRewardLedger(ctx.DB.getRewardLedgerDB(), blockHeight)
+		iScoreDB.deleteCalcCheckpoint()
+	}
+
+	stats, stateHash, totalCount := calculateAccounts(quit, ctx, iissDB, header, gvList, prepList, blockHeight,
+		req.BlockHash, writeBatchCount, resume)
+	if stats == nil {
+		return calcCancelError(ctx, blockHeight), blockHeight, nil, nil
+	}
+	ctx.stats = stats
+	ctx.progress.setPhase(CalcPhaseCommit)
+
+	// make Merkle tree of all accounts for state root and account proof
+	stateTree, err := buildStateTree(quit, blockHeight, iScoreDB.GetCalcDBList())
+	if err == errStateTreeCanceled {
+		return calcCancelError(ctx, blockHeight), blockHeight, nil, nil
+	} else if err != nil {
+		return fmt.Errorf("failed to make state root. %v", err), blockHeight, nil, nil
+	}
+	stateTree.set = iScoreDB.getCalcDBSet()
+
+	elapsedTime := time.Since(startTime)
+	log.Printf("Finish calculation: Duration: %s, block height: %d -> %d, DB: %d, batch: %d, %d entries",
+		elapsedTime, ctx.DB.getCalcDoneBH(), blockHeight, iScoreDB.info.DBCount, writeBatchCount, totalCount)
+	log.Printf("%s", stats.String())
+	log.Printf("stateHash : %s", hex.EncodeToString(stateHash))
+	log.Printf("stateRoot : %s", hex.EncodeToString(stateTree.root()))
+	observeCalculation(elapsedTime, stats)
+
+	if NeedToUpdateCalcDebugResult(ctx) {
+		log.Printf("CalculationResult : %s", ctx.calcDebug.result.String())
+		WriteCalcDebugResult(ctx)
+		ResetCalcDebugResults(ctx)
+	}
+
+	// set blockHeight
+	if err = ctx.DB.setCalcDoneBH(blockHeight); err != nil {
+		return fmt.Errorf("failed to write calculation block height. %v", err), blockHeight, nil, nil
+	}
+
+	// write calculation result
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight, stats, stateHash, stateTree.root(), ctx.nodeKey)
+	ctx.setStateTree(stateTree)
+
+	// delete backup account DB which is out of rollback range
+	ctx.DB.deleteOldBackupAccountDB()
+	success = true
+
+	return nil, blockHeight, ctx.stats, stateHash
+}
+
+// calcCancelError returns the error of canceled calculation and resets cancel code
+func calcCancelError(ctx *Context, blockHeight uint64) error {
+	var err error
+	switch ctx.CancelCalculation.cancelCode {
+	case CancelExit:
+		err = &CalcCancelByExit{blockHeight}
+	case CancelRollback:
+		err = &CalcCancelByRollbackError{blockHeight}
+	}
+	ctx.CancelCalculation.cancelCode = CancelNone
+	return err
+}
+
+// calculateAccounts updates context with IISS data and I-Score of all accounts in calculate DB.
+// It returns nil Statistics if the calculation was canceled.
+func calculateAccounts(quit <-chan struct{}, ctx *Context, iissDB db.Database, header *IISSHeader,
+	gvList []*IISSGovernanceVariable, prepList []*PRep, blockHeight uint64, blockHash []byte, batchCount uint64,
+	resume bool) (*Statistics, []byte, uint64) {
+	h := sha3.NewShake256()
+	stateHash := make([]byte, 64)
+	stats := new(Statistics)
+	iScoreDB := ctx.DB
+	var newAccount uint64
+
+	ctx.stateLock.Lock()
 
 	// Update header Info.
 	if header != nil {
-		ctx.Revision = header.Revision
+		ctx.updateRevision(header, blockHeight)
 	}
 
 	// Update GV
@@ -412,15 +542,18 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 	// Update P-Rep candidate with IISS TX(P-Rep register/unregister)
 	ctx.UpdatePRepCandidate(iissDB)
 
+	ctx.stateLock.Unlock()
+
 	ctx.Print()
 
-	InitCalcDebugResult(ctx, blockHeight, req.BlockHash)
+	InitCalcDebugResult(ctx, blockHeight, blockHash)
 
 	//
 	// Calculate I-Score @ Account DB
 	//
 
 	// calculate delegation reward
+	ctx.progress.setPhase(CalcPhaseAccountDB)
 	var totalCount uint64
 	var wait sync.WaitGroup
 	wait.Add(iScoreDB.info.DBCount)
@@ -429,31 +562,27 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 	calcDBList := iScoreDB.GetCalcDBList()
 	stateHashList := make([][]byte, iScoreDB.info.DBCount)
 	statsList := make([]*Statistics, iScoreDB.info.DBCount)
+	countList := make([]uint64, iScoreDB.info.DBCount)
 	for i, cDB := range calcDBList {
-		go func(ch <-chan struct{}, index int, read db.Database, write db.Database) {
+		var checkpoint *CalcCheckpoint
+		if resume {
+			checkpoint, _ = iScoreDB.getCalcCheckpoint(i, blockHeight, blockHash, header)
+		}
+		go func(ch <-chan struct{}, index int, read db.Database, write db.Database, cp *CalcCheckpoint) {
 			defer wait.Done()
 
-			var count uint64
-
 			// Update all Accounts in the calculate DB
-			count, statsList[index], stateHashList[index] =
-				calculateDB(ch, index, read, write, ctx, blockHeight, writeBatchCount)
-
-			totalCount += count
-		}(quit, i, queryDBList[i], cDB)
+			countList[index], statsList[index], stateHashList[index] =
+				calculateDB(ch, index, read, write, ctx, blockHeight, blockHash, header, batchCount, cp)
+		}(quit, i, queryDBList[i], cDB, checkpoint)
 	}
 	wait.Wait()
+	for _, count := range countList {
+		totalCount += count
+	}
 
 	if quit != ctx.CancelCalculation.GetChannel() {
-		var err error
-		switch ctx.CancelCalculation.cancelCode {
-		case CancelExit:
-			err = &CalcCancelByExit{blockHeight}
-		case CancelRollback:
-			err = &CalcCancelByRollbackError{blockHeight}
-		}
-		ctx.CancelCalculation.cancelCode = CancelNone
-		return err, blockHeight, nil, nil
+		return nil, nil, 0
 	}
 
 	// update Statistics
@@ -466,72 +595,67 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 		stats.Increase("TotalReward", s.Beta3)
 	}
 
+	// Rewards below are not idempotent. Calculation starts from the beginning if it stops after here
+	iScoreDB.deleteCalcCheckpoint()
+
 	reward := new(common.HexInt)
 	var hashValue []byte
 
 	// Update calculate DB with delegate TX
-	newAccount, reward, hashValue = calculateIISSTX(ctx, iissDB, blockHeight, false)
+	ctx.progress.setPhase(CalcPhaseIISSTX)
+	newAccount, reward, hashValue = calculateIISSTX(ctx, iissDB, blockHeight, batchCount, false)
 	stats.Increase("Accounts", newAccount)
 	stats.Increase("Beta3", *reward)
 	stats.Increase("TotalReward", *reward)
 	h.Write(hashValue)
 
 	// Update block produce reward
-	newAccount, reward, hashValue = calculateIISSBlockProduce(ctx, iissDB, blockHeight, false)
+	ctx.progress.setPhase(CalcPhaseBlockProduce)
+	newAccount, reward, hashValue = calculateIISSBlockProduce(ctx, iissDB, blockHeight, batchCount, false)
 	stats.Increase("Accounts", newAccount)
 	stats.Increase("Beta1", *reward)
 	stats.Increase("TotalReward", *reward)
 	h.Write(hashValue)
 
 	// Update P-Rep delegated reward
-	newAccount, reward, hashValue = calculatePRepReward(ctx, blockHeight)
+	ctx.progress.setPhase(CalcPhasePRepReward)
+	newAccount, reward, hashValue = calculatePRepReward(ctx, blockHeight, batchCount)
 	stats.Increase("Accounts", newAccount)
 	stats.Increase("Beta2", *reward)
 	stats.Increase("TotalReward", *reward)
 	h.Write(hashValue)
 
-	ctx.stats = stats
-
 	// make stateHash
 	for _, hash := range stateHashList {
 		h.Write(hash)
 	}
 	h.Read(stateHash)
 
-	elapsedTime := time.Since(startTime)
-	log.Printf("Finish calculation: Duration: %s, block height: %d -> %d, DB: %d, batch: %d, %d entries",
-		elapsedTime, ctx.DB.getCalcDoneBH(), blockHeight, iScoreDB.info.DBCount, writeBatchCount, totalCount)
-	log.Printf("%s", stats.String())
-	log.Printf("stateHash : %s", hex.EncodeToString(stateHash))
-
-	if NeedToUpdateCalcDebugResult(ctx) {
-		log.Printf("CalculationResult : %s", ctx.calcDebug.result.String())
-		WriteCalcDebugResult(ctx)
-		ResetCalcDebugResults(ctx)
-	}
-
-	// set blockHeight
-	ctx.DB.setCalcDoneBH(blockHeight)
-
-	// write calculation result
-	WriteCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight, stats, stateHash)
+	return stats, stateHash, totalCount
+}
 
-	return nil, blockHeight, ctx.stats, stateHash
+// delegateTX is a delegate TX with its order in IISS data
+type delegateTX struct {
+	seq int
+	tx  *IISSTX
 }
 
-// Update I-Score of account in TX list
-func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbose bool) (
+// Update I-Score of account in TX list.
+// TXs are applied to each account DB concurrently in TX order and stateHash is made in TX order.
+func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, batchCount uint64, verbose bool) (
 	uint64, *common.HexInt, []byte) {
 	h := sha3.NewShake256()
 	stateHash := make([]byte, 64)
-	stats := new(common.HexInt)
-	var tx IISSTX
-	var entries, newAccount uint64 = 0, 0
+	dbCount := ctx.DB.info.DBCount
+	var entries, seq uint64
 
+	// read TXs and split them with account DB
+	shardTXs := make([][]delegateTX, dbCount)
 	iter, _ := iissDB.GetIterator()
 	prefix := util.BytesPrefix([]byte(db.PrefixIISSTX))
 	iter.New(prefix.Start, prefix.Limit)
 	for entries = 0; iter.Next(); entries++ {
+		tx := new(IISSTX)
 		err := tx.SetBytes(iter.Value())
 		if err != nil {
 			log.Printf("Failed to load IISS TX data")
@@ -543,23 +667,42 @@ func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbo
 		}
 		switch tx.DataType {
 		case TXDataTypeDelegate:
-			// get Calculate DB for account
-			cDB := ctx.DB.getCalculateDB(tx.Address)
-			bucket, _ := cDB.GetBucket(db.PrefixIScore)
+			index := ctx.DB.getAccountDBIndex(tx.Address)
+			shardTXs[index] = append(shardTXs[index], delegateTX{seq: int(seq), tx: tx})
+			seq++
+		case TXDataTypePrepReg:
+		case TXDataTypePrepUnReg:
+		}
+	}
+	iter.Release()
+	err := iter.Error()
+	if err != nil {
+		log.Printf("There is error while calculate IISS TX iteration. %+v", err)
+	}
+
+	hashes := make([][]byte, seq)
+	newAccounts := make([]uint64, dbCount)
+	statsList := make([]common.HexInt, dbCount)
+	calcDBList := ctx.DB.GetCalcDBList()
+	runShards(dbCount, func(index int) {
+		w := newShardWriter(calcDBList[index], batchCount)
+		stats := &statsList[index]
+		for _, dtx := range shardTXs[index] {
+			tx := dtx.tx
 
 			// update I-Score
-			newIA := NewIScoreAccountFromIISS(&tx)
+			newIA := NewIScoreAccountFromIISS(tx)
 
-			data, _ := bucket.Get(tx.Address.Bytes())
+			data := w.get(tx.Address)
 			if data != nil {
 				ia, err := NewIScoreAccountFromBytes(data)
 				if err != nil {
 					log.Printf("Failed to make Account Info. from IISS TX(%s). err=%+v", tx.String(), err)
-					break
+					continue
 				}
 				if ia.BlockHeight != blockHeight {
 					log.Printf("Invalid account Info. from calculate DB(%s)", ia.String())
-					break
+					continue
 				}
 
 				// backup original I-Score that calculated to blockHeight
@@ -575,8 +718,11 @@ func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbo
 
 				// Statistics
 				stats.Sub(&stats.Int, &ia.IScore.Int)
+
+				// reward ledger
+				updateRewardLedger(ctx, blockHeight, tx.Address, rewardBeta3, new(big.Int).Neg(&ia.IScore.Int))
 			} else {
-				newAccount++
+				newAccounts[index]++
 			}
 
 			// calculate I-Score from tx.BlockHeight to blockHeight with new delegation Info.
@@ -584,6 +730,7 @@ func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbo
 			// Statistics
 			if ok == true {
 				stats.Add(&stats.Int, &reward.Int)
+				updateRewardLedger(ctx, blockHeight, tx.Address, rewardBeta3, &reward.Int)
 			}
 
 			if verbose {
@@ -591,39 +738,43 @@ func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbo
 			}
 
 			// write to account DB
-			bucket.Set(newIA.ID(), newIA.Bytes())
+			w.set(newIA)
 
-			// update stateHash
-			h.Write(newIA.BytesForHash())
-		case TXDataTypePrepReg:
-		case TXDataTypePrepUnReg:
+			// for stateHash
+			hashes[dtx.seq] = newIA.BytesForHash()
 		}
-	}
-	iter.Release()
-	err := iter.Error()
-	if err != nil {
-		log.Printf("There is error while calculate IISS TX iteration. %+v", err)
-	}
+		w.flush()
+	})
 
-	// get stateHash
+	// get stateHash in TX order
+	for _, hash := range hashes {
+		h.Write(hash)
+	}
 	h.Read(stateHash)
 
+	totalReward := new(common.HexInt)
+	var newAccount uint64
+	for i := 0; i < dbCount; i++ {
+		totalReward.Add(&totalReward.Int, &statsList[i].Int)
+		newAccount += newAccounts[i]
+	}
+
 	log.Printf("IISS TX: TX count: %d, new account: %d, I-Score: %s, stateHash: %s",
-		entries, newAccount, stats.String(), hex.EncodeToString(stateHash))
+		entries, newAccount, totalReward.String(), hex.EncodeToString(stateHash))
 
-	return newAccount, stats, stateHash
+	return newAccount, totalReward, stateHash
 }
 
 // Calculate Block produce reward
-func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uint64, verbose bool) (
-	uint64, *common.HexInt, []byte) {
+func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uint64, batchCount uint64,
+	verbose bool) (uint64, *common.HexInt, []byte) {
 	h := sha3.NewShake256()
 	stateHash := make([]byte, 64)
 	bpMap := make(map[common.Address]common.HexInt)
 
 	// calculate reward
 	var bp IISSBlockProduceInfo
-	var entries, newAccount uint64
+	var entries uint64
 	iter, _ := iissDB.GetIterator()
 	prefix := util.BytesPrefix([]byte(db.PrefixIISSBPInfo))
 	iter.New(prefix.Start, prefix.Limit)
@@ -644,9 +795,11 @@ func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uin
 			continue
 		}
 
+		genReward, valReward := ctx.getRewardPolicy(bp.BlockHeight).BlockProduceReward(gv, len(bp.Validator))
+
 		// update Generator reward
 		generator := bpMap[bp.Generator]
-		generator.Add(&generator.Int, &gv.BlockProduceReward.Int)
+		generator.Add(&generator.Int, &genReward.Int)
 		bpMap[bp.Generator] = generator
 
 		// set block validator reward value
@@ -654,10 +807,6 @@ func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uin
 			continue
 		}
 
-		var valReward common.HexInt
-		valCount := common.NewHexInt(int64(len(bp.Validator)))
-		valReward.Div(&gv.BlockProduceReward.Int, &valCount.Int)
-
 		// update Validator reward
 		for _, v := range bp.Validator {
 			validator := bpMap[v]
@@ -672,51 +821,70 @@ func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uin
 		log.Printf("There is error while calculate IISS BP iteration. %+v", err)
 	}
 
-	totalReward := new(common.HexInt)
-	iaSlice := make([]*IScoreAccount, 0)
-
-	// write to account DB
-	for addr, reward := range bpMap {
-		// get Account DB for account
-		cDB := ctx.DB.getCalculateDB(addr)
-		bucket, _ := cDB.GetBucket(db.PrefixIScore)
+	// split rewards with account DB
+	dbCount := ctx.DB.info.DBCount
+	shardAddresses := make([][]common.Address, dbCount)
+	for addr := range bpMap {
+		index := ctx.DB.getAccountDBIndex(addr)
+		shardAddresses[index] = append(shardAddresses[index], addr)
+	}
 
-		// update IScoreAccount
-		var ia *IScoreAccount
-		var err error
-		data, _ := bucket.Get(addr.Bytes())
-		if data != nil {
-			ia, err = NewIScoreAccountFromBytes(data)
-			if err != nil {
-				log.Printf("Failed to make Account Info. for Block produce reward(%s). err=%+v", addr.String(), err)
-				break
-			}
+	newAccounts := make([]uint64, dbCount)
+	rewardList := make([]common.HexInt, dbCount)
+	iaList := make([][]*IScoreAccount, dbCount)
+	calcDBList := ctx.DB.GetCalcDBList()
 
-			// update I-Score
-			ia.IScore.Add(&ia.IScore.Int, &reward.Int)
-			ia.Address = addr
-			//log.Printf("Block produce reward: %s, %s", ia.String(), reward.String())
+	// write to account DB
+	runShards(dbCount, func(index int) {
+		w := newShardWriter(calcDBList[index], batchCount)
+		for _, addr := range shardAddresses[index] {
+			reward := bpMap[addr]
+
+			// update IScoreAccount
+			var ia *IScoreAccount
+			var err error
+			data := w.get(addr)
+			if data != nil {
+				ia, err = NewIScoreAccountFromBytes(data)
+				if err != nil {
+					log.Printf("Failed to make Account Info. for Block produce reward(%s). err=%+v",
+						addr.String(), err)
+					continue
+				}
 
-			// do not update block height of IA
-		} else {
-			// there is no account in DB
-			ia = new(IScoreAccount)
-			ia.IScore.Set(&reward.Int)
-			ia.Address = addr
-			ia.BlockHeight = blockHeight // has no delegation. Set blockHeight to blocHeight of calculation msg
+				// update I-Score
+				ia.IScore.Add(&ia.IScore.Int, &reward.Int)
+				ia.Address = addr
 
-			newAccount++
-		}
+				// do not update block height of IA
+			} else {
+				// there is no account in DB
+				ia = new(IScoreAccount)
+				ia.IScore.Set(&reward.Int)
+				ia.Address = addr
+				ia.BlockHeight = blockHeight // has no delegation. Set blockHeight to blocHeight of calculation msg
 
-		// write to account DB
-		if ia != nil {
-			bucket.Set(ia.ID(), ia.Bytes())
+				newAccounts[index]++
+			}
 
-			totalReward.Add(&totalReward.Int, &reward.Int)
+			// write to account DB
+			w.set(ia)
+			rewardList[index].Add(&rewardList[index].Int, &reward.Int)
+			updateRewardLedger(ctx, blockHeight, addr, rewardBeta1, &reward.Int)
 
 			// for state root hash
-			iaSlice = append(iaSlice, ia)
+			iaList[index] = append(iaList[index], ia)
 		}
+		w.flush()
+	})
+
+	totalReward := new(common.HexInt)
+	var newAccount uint64
+	iaSlice := make([]*IScoreAccount, 0, len(bpMap))
+	for i := 0; i < dbCount; i++ {
+		totalReward.Add(&totalReward.Int, &rewardList[i].Int)
+		newAccount += newAccounts[i]
+		iaSlice = append(iaSlice, iaList[i]...)
 	}
 
 	// sort data and make state root hash
@@ -734,17 +902,28 @@ func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uin
 	return newAccount, totalReward, stateHash
 }
 
-// Calculate Main/Sub P-Rep reward
-func calculatePRepReward(ctx *Context, to uint64) (uint64, *common.HexInt, []byte) {
+// prepReward is P-Rep reward of an account for a period of Main/Sub P-Rep list
+type prepReward struct {
+	seq         int
+	address     common.Address
+	iScore      common.HexInt
+	blockHeight uint64 // end of the last period which has reward
+	end         uint64
+}
+
+// Calculate Main/Sub P-Rep reward.
+// Rewards are applied to each account DB concurrently in P-Rep list order and stateHash is made in the same order.
+func calculatePRepReward(ctx *Context, to uint64, batchCount uint64) (uint64, *common.HexInt, []byte) {
 	h := sha3.NewShake256()
 	stateHash := make([]byte, 64)
 	start := ctx.DB.getCalcDoneBH()
 	end := to
+	dbCount := ctx.DB.info.DBCount
 
-	totalReward := new(common.HexInt)
-	var newAccount uint64
-
-	// calculate for PRep list
+	// calculate rewards for PRep list and split them with account DB
+	shardRewards := make([][]*prepReward, dbCount)
+	groups := make([]int, 0) // the first seq of rewards of each P-Rep list
+	var seq int
 	for i, prep := range ctx.PRep {
 		//log.Printf("[P-Rep reward] P-Rep : %s", prep.String())
 		if prep.TotalDelegation.Sign() == 0 {
@@ -765,117 +944,105 @@ func calculatePRepReward(ctx *Context, to uint64) (uint64, *common.HexInt, []byt
 			continue
 		}
 
-		// calculate P-Rep reward for Governance variable and write to calculate DB
-		account, reward, hash := setPRepReward(ctx, s, e, prep, to)
-		h.Write(hash)
-		totalReward.Add(&totalReward.Int, &reward.Int)
-		newAccount += account
+		// calculate P-Rep reward for Governance variable
+		groups = append(groups, seq)
+		for _, reward := range getPRepRewards(ctx, s, e, prep) {
+			reward.seq = seq
+			index := ctx.DB.getAccountDBIndex(reward.address)
+			shardRewards[index] = append(shardRewards[index], reward)
+			seq++
+		}
 	}
 
-	// get stateHash
-	h.Read(stateHash)
+	hashes := make([][]byte, seq)
+	newAccounts := make([]uint64, dbCount)
+	rewardList := make([]common.HexInt, dbCount)
+	calcDBList := ctx.DB.GetCalcDBList()
+	policy := ctx.getRewardPolicy(to)
 
-	return newAccount, totalReward, stateHash
-}
+	// write to account DB
+	runShards(dbCount, func(index int) {
+		w := newShardWriter(calcDBList[index], batchCount)
+		for _, reward := range shardRewards[index] {
+			// update IScoreAccount
+			var ia *IScoreAccount
+			var err error
+			data := w.get(reward.address)
+			if data != nil {
+				ia, err = NewIScoreAccountFromBytes(data)
+				if err != nil {
+					log.Printf("Failed to make Account Info. for P-Rep reward(%s). err=%+v",
+						reward.address.String(), err)
+					continue
+				}
 
-func setPRepReward(ctx *Context, start uint64, end uint64, prep *PRep, blockHeight uint64) (uint64, *common.HexInt, []byte) {
-	type reward struct {
-		iScore      common.HexInt
-		blockHeight uint64
-	}
-	rewards := make([]reward, len(prep.List))
-	h := sha3.NewShake256()
-	stateHash := make([]byte, 64)
+				// update I-Score
+				ia.IScore.Add(&ia.IScore.Int, &reward.iScore.Int)
+				ia.BlockHeight = policy.PRepRewardBlockHeight(ia, reward.blockHeight, reward.end, to)
+			} else {
+				// there is no account in DB
+				ia = new(IScoreAccount)
+				ia.IScore.Set(&reward.iScore.Int)
+				ia.BlockHeight = policy.PRepRewardBlockHeight(nil, reward.blockHeight, reward.end, to)
 
-	// calculate P-Rep reward for Governance variable
-	for i, gv := range ctx.GV {
-		//log.Printf("setPRepReward: gv: %s", gv.String())
-		var s, e = start, end
+				newAccounts[index]++
+			}
 
-		if s <= gv.BlockHeight {
-			s = gv.BlockHeight
+			// write to account DB
+			ia.Address = reward.address
+			//log.Printf("[P-Rep reward] Write to DB %s, increased reward: %s", ia.String(), reward.iScore.String())
+			w.set(ia)
+			hashes[reward.seq] = ia.BytesForHash()
+			rewardList[index].Add(&rewardList[index].Int, &reward.iScore.Int)
+			updateRewardLedger(ctx, to, reward.address, rewardBeta2, &reward.iScore.Int)
 		}
+		w.flush()
+	})
 
-		if i+1 < len(ctx.GV) && ctx.GV[i+1].BlockHeight < end {
-			e = ctx.GV[i+1].BlockHeight
+	// get stateHash with stateHash of each P-Rep list
+	groups = append(groups, seq)
+	for i := 0; i+1 < len(groups); i++ {
+		gh := sha3.NewShake256()
+		groupHash := make([]byte, 64)
+		for _, hash := range hashes[groups[i]:groups[i+1]] {
+			gh.Write(hash)
 		}
+		gh.Read(groupHash)
+		h.Write(groupHash)
+	}
+	h.Read(stateHash)
 
-		//log.Printf("[P-Rep reward]setPRepReward: s, e : %d - %d", s, e)
-		if e <= s {
-			continue
-		}
-		period := common.NewHexIntFromUint64(e - s)
+	totalReward := new(common.HexInt)
+	var newAccount uint64
+	for i := 0; i < dbCount; i++ {
+		totalReward.Add(&totalReward.Int, &rewardList[i].Int)
+		newAccount += newAccounts[i]
+	}
+
+	return newAccount, totalReward, stateHash
+}
 
-		// reward = period * GV
-		var rewardRate common.HexInt
-		rewardRate.Mul(&period.Int, &gv.PRepReward.Int)
+// getPRepRewards returns P-Rep reward of accounts in P-Rep list from start to end
+func getPRepRewards(ctx *Context, start uint64, end uint64, prep *PRep) []*prepReward {
+	rewards := make([]*prepReward, len(prep.List))
+	for i, dgInfo := range prep.List {
+		rewards[i] = &prepReward{address: dgInfo.Address, end: end}
+	}
 
+	// calculate P-Rep reward for Governance variable and reward policy
+	ctx.forEachRewardPeriod(start, end, func(s uint64, e uint64, gv *GovernanceVariable, policy RewardPolicy) {
 		// update rewards
 		for i, dgInfo := range prep.List {
-			var iScore common.HexInt
-			iScore.Mul(&rewardRate.Int, &dgInfo.DelegatedAmount.Int)
-			iScore.Div(&iScore.Int, &prep.TotalDelegation.Int)
+			iScore := policy.PRepReward(&dgInfo.DelegatedAmount, &prep.TotalDelegation, e-s, gv)
 			rewards[i].iScore.Add(&rewards[i].iScore.Int, &iScore.Int)
 			rewards[i].blockHeight = e
 			//log.Printf("[P-Rep reward] delegation: %s, reward: %s,%d\n",
 			//	dgInfo.String(), rewards[i].IScore.String(), rewards[i].blockHeight)
 			WriteBeta2Info(ctx, dgInfo, *prep, s, e, gv.PRepReward.Uint64())
 		}
-	}
-
-	totalReward := new(common.HexInt)
-	var newAccount uint64
-
-	// write to account DB
-	for i, dgInfo := range prep.List {
-		// get Account DB for account
-		cDB := ctx.DB.getCalculateDB(dgInfo.Address)
-		bucket, _ := cDB.GetBucket(db.PrefixIScore)
-
-		// update IScoreAccount
-		var ia *IScoreAccount
-		var err error
-		data, _ := bucket.Get(dgInfo.Address.Bytes())
-		if data != nil {
-			ia, err = NewIScoreAccountFromBytes(data)
-			if err != nil {
-				log.Printf("Failed to make Account Info. for P-Rep reward(%s). err=%+v", dgInfo.Address.String(), err)
-				break
-			}
-
-			// update I-Score
-			ia.IScore.Add(&ia.IScore.Int, &rewards[i].iScore.Int)
-			// do not update block height of IA
-			if ctx.Revision < Revision8 {
-				ia.BlockHeight = rewards[i].blockHeight
-			}
-		} else {
-			// there is no account in DB
-			ia = new(IScoreAccount)
-			ia.IScore.Set(&rewards[i].iScore.Int)
-			if ctx.Revision >= Revision8 {
-				ia.BlockHeight = blockHeight
-			} else {
-				ia.BlockHeight = end // Set blockHeight to end
-			}
-
-			newAccount++
-		}
-
-		// write to account DB
-		if ia != nil {
-			ia.Address = dgInfo.Address
-			//log.Printf("[P-Rep reward] Write to DB %s, increased reward: %s", ia.String(), rewards[i].iScore.String())
-			bucket.Set(ia.ID(), ia.Bytes())
-			h.Write(ia.BytesForHash())
-			totalReward.Add(&totalReward.Int, &rewards[i].iScore.Int)
-		}
-	}
-
-	// get stateHash
-	h.Read(stateHash)
+	})
 
-	return newAccount, totalReward, stateHash
+	return rewards
 }
 
 const (
@@ -905,14 +1072,12 @@ func (cs *QueryCalculateStatusResponse) String() string {
 
 func (mh *msgHandler) queryCalculateStatus(c ipc.Connection, id uint32, data []byte) error {
 	ctx := mh.mgr.ctx
-	mh.mgr.AddMsgTask()
 
 	// send QUERY_CALCULATE_STATUS response
 	var resp QueryCalculateStatusResponse
 
 	DoQueryCalculateStatus(ctx, &resp)
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryCalculateStatus), id, resp.String())
 	return c.Send(MsgQueryCalculateStatus, id, &resp)
 }
@@ -939,6 +1104,11 @@ type QueryCalculateResultResponse struct {
 	BlockHeight uint64
 	IScore      common.HexInt
 	StateHash   []byte
+	StateRoot   []byte
+	Beta1       common.HexInt
+	Beta2       common.HexInt
+	Beta3       common.HexInt
+	Signature   []byte
 }
 
 func (cr *QueryCalculateResultResponse) StatusString() string {
@@ -957,16 +1127,34 @@ func (cr *QueryCalculateResultResponse) StatusString() string {
 }
 
 func (cr *QueryCalculateResultResponse) String() string {
-	return fmt.Sprintf("Status: %s, BlockHeight: %d, IScore: %s, StateHash: %s",
+	return fmt.Sprintf("Status: %s, BlockHeight: %d, IScore: %s, StateHash: %s, StateRoot: %s, Signature: %s",
 		cr.StatusString(),
 		cr.BlockHeight,
 		cr.IScore.String(),
-		hex.EncodeToString(cr.StateHash))
+		hex.EncodeToString(cr.StateHash),
+		hex.EncodeToString(cr.StateRoot),
+		hex.EncodeToString(cr.Signature))
+}
+
+// encodeFor omits StateRoot and fields of signed result which are not negotiated
+func (cr *QueryCalculateResultResponse) encodeFor(caps capabilities) interface{} {
+	if caps.has(CapSignedResult) {
+		return cr
+	}
+	fields := []interface{}{cr.Status, cr.BlockHeight, &cr.IScore, cr.StateHash}
+	if caps.has(CapStateRoot) {
+		fields = append(fields, cr.StateRoot)
+	}
+	return fields
+}
+
+func (cr *QueryCalculateResultResponse) Attestation() *ResultAttestation {
+	return newResultAttestation(cr.BlockHeight, cr.StateHash, cr.StateRoot, &cr.IScore,
+		&cr.Beta1, &cr.Beta2, &cr.Beta3)
 }
 
 func (mh *msgHandler) queryCalculateResult(c ipc.Connection, id uint32, data []byte) error {
 	var blockHeight uint64
-	mh.mgr.AddMsgTask()
 	if _, err := codec.MP.UnmarshalFromBytes(data, &blockHeight); err != nil {
 		log.Printf("Failed to unmarshal data. err=%+v", err)
 		return err
@@ -980,7 +1168,6 @@ func (mh *msgHandler) queryCalculateResult(c ipc.Connection, id uint32, data []b
 
 	DoQueryCalculateResult(ctx, blockHeight, &resp)
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryCalculateResult), id, resp.String())
 	return c.Send(MsgQueryCalculateResult, id, &resp)
 }
@@ -997,17 +1184,18 @@ func DoQueryCalculateResult(ctx *Context, blockHeight uint64, resp *QueryCalcula
 	}
 
 	// read from calculate result DB
-	crDB := ctx.DB.getCalculateResultDB()
-	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
-	key := common.Uint64ToBytes(blockHeight)
-	bs, _ := bucket.Get(key)
-	if bs != nil {
-		cr, _ := NewCalculationResultFromBytes(bs)
+	cr, _ := ReadCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight)
+	if cr != nil {
 		resp.BlockHeight = blockHeight
 		if cr.Success {
 			resp.Status = calcSucceeded
 			resp.IScore.Set(&cr.IScore.Int)
 			resp.StateHash = cr.StateHash
+			resp.StateRoot = cr.StateRoot
+			resp.Beta1 = cr.Beta1
+			resp.Beta2 = cr.Beta2
+			resp.Beta3 = cr.Beta3
+			resp.Signature = cr.Signature
 		} else {
 			resp.Status = calcFailed
 		}
@@ -0,0 +1,312 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+// phases of calculation
+const (
+	CalcPhaseNone uint64 = iota
+	CalcPhaseAccountDB
+	CalcPhaseIISSTX
+	CalcPhaseBlockProduce
+	CalcPhasePRepReward
+	CalcPhaseCommit
+)
+
+func CalcPhaseToString(phase uint64) string {
+	switch phase {
+	case CalcPhaseNone:
+		return "None"
+	case CalcPhaseAccountDB:
+		return "Account DB"
+	case CalcPhaseIISSTX:
+		return "IISS TX"
+	case CalcPhaseBlockProduce:
+		return "Block produce"
+	case CalcPhasePRepReward:
+		return "P-Rep reward"
+	case CalcPhaseCommit:
+		return "Commit"
+	default:
+		return "Unknown phase"
+	}
+}
+
+// QueryCalculateProgress requests calculation progress.
+// With positive NotifyInterval, CALCULATE_PROGRESS is sent to the connection every NotifyInterval seconds
+// while calculating and when calculation ends. 0 stops the notification.
+type QueryCalculateProgress struct {
+	NotifyInterval uint64
+}
+
+// CalculateProgress is the response of QUERY_CALCULATE_PROGRESS and the data of CALCULATE_PROGRESS
+type CalculateProgress struct {
+	Status            uint64
+	BlockHeight       uint64
+	Phase             uint64
+	Accounts          []uint64 // processed accounts of each account DB
+	EstimatedAccounts []uint64 // estimated accounts of each account DB. 0 if unknown
+	Elapsed           uint64   // in seconds
+	ETA               uint64   // remaining seconds of account DB phase. 0 if unknown
+}
+
+func (cp *CalculateProgress) String() string {
+	var accounts, estimated uint64
+	for i := range cp.Accounts {
+		accounts += cp.Accounts[i]
+		estimated += cp.EstimatedAccounts[i]
+	}
+	return fmt.Sprintf("Status: %s, BlockHeight: %d, Phase: %s, Accounts: %d/%d, Elapsed: %ds, ETA: %ds",
+		(&QueryCalculateStatusResponse{Status: cp.Status}).StatusString(), cp.BlockHeight,
+		CalcPhaseToString(cp.Phase), accounts, estimated, cp.Elapsed, cp.ETA)
+}
+
+// progressNotifyTick is the period to check notification interval of subscribers
+var progressNotifyTick = time.Second
+
+type progressSubscriber struct {
+	interval time.Duration
+	last     time.Time
+}
+
+// calcProgress tracks progress of the calculation and sends it to subscribers
+type calcProgress struct {
+	lock        sync.Mutex
+	blockHeight uint64
+	phase       uint64
+	startTime   time.Time
+	phaseStart  time.Time
+	accounts    []uint64 // updated with atomic while calculating account DB
+	restored    []uint64 // accounts restored from checkpoint
+	estimated   []uint64
+	lastCount   []uint64 // accounts of each account DB in the last calculation
+
+	subscribers map[ipc.Connection]*progressSubscriber
+	stop        chan struct{}
+	notifier    sync.WaitGroup
+}
+
+func newCalcProgress() *calcProgress {
+	return &calcProgress{subscribers: make(map[ipc.Connection]*progressSubscriber)}
+}
+
+// start resets progress for new calculation. Accounts of the last calculation are the estimate
+func (p *calcProgress) start(ctx *Context, blockHeight uint64) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	dbCount := ctx.DB.info.DBCount
+	stats := ctx.stats
+
+	p.blockHeight = blockHeight
+	p.phase = CalcPhaseNone
+	p.startTime = time.Now()
+	p.phaseStart = p.startTime
+	p.accounts = make([]uint64, dbCount)
+	p.restored = make([]uint64, dbCount)
+	p.estimated = make([]uint64, dbCount)
+	if len(p.lastCount) == dbCount {
+		copy(p.estimated, p.lastCount)
+	} else if stats != nil {
+		for i := range p.estimated {
+			p.estimated[i] = (stats.Accounts + uint64(dbCount) - 1) / uint64(dbCount)
+		}
+	}
+
+	p.stop = make(chan struct{})
+	p.notifier.Add(1)
+	go p.notify(ctx, p.stop)
+}
+
+// finish stops notification and keeps accounts of account DBs for the estimate of the next calculation
+func (p *calcProgress) finish(success bool) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	if success {
+		p.lastCount = make([]uint64, len(p.accounts))
+		for i := range p.accounts {
+			p.lastCount[i] = atomic.LoadUint64(&p.accounts[i])
+		}
+	}
+	p.phase = CalcPhaseNone
+	stop := p.stop
+	p.stop = nil
+	p.lock.Unlock()
+
+	if stop != nil {
+		close(stop)
+		p.notifier.Wait()
+	}
+}
+
+func (p *calcProgress) setPhase(phase uint64) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	log.Printf("Calculation phase: %s, elapsed: %s", CalcPhaseToString(phase), time.Since(p.startTime))
+	p.phase = phase
+	p.phaseStart = time.Now()
+}
+
+// addAccount increases processed accounts of account DB
+func (p *calcProgress) addAccount(index int) {
+	if p == nil || index >= len(p.accounts) {
+		return
+	}
+	atomic.AddUint64(&p.accounts[index], 1)
+}
+
+// restoreAccounts sets processed accounts of account DB with checkpoint
+func (p *calcProgress) restoreAccounts(index int, count uint64) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if index >= len(p.accounts) {
+		return
+	}
+
+	atomic.StoreUint64(&p.accounts[index], count)
+	p.restored[index] = count
+}
+
+func (p *calcProgress) get(ctx *Context) *CalculateProgress {
+	resp := new(CalculateProgress)
+	if !ctx.DB.isCalculating() || p == nil {
+		resp.Status = CalculationDone
+		resp.BlockHeight = ctx.DB.getCalcDoneBH()
+		return resp
+	}
+
+	resp.Status = CalculationDoing
+	resp.BlockHeight = ctx.DB.getCalculatingBH()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.blockHeight != resp.BlockHeight {
+		// calculation is not started yet
+		return resp
+	}
+	resp.Phase = p.phase
+	resp.Elapsed = uint64(time.Since(p.startTime).Seconds())
+	resp.Accounts = make([]uint64, len(p.accounts))
+	resp.EstimatedAccounts = make([]uint64, len(p.accounts))
+
+	var processed, remain, estimated uint64
+	for i := range p.accounts {
+		resp.Accounts[i] = atomic.LoadUint64(&p.accounts[i])
+		resp.EstimatedAccounts[i] = p.estimated[i]
+		if resp.Accounts[i] > resp.EstimatedAccounts[i] {
+			resp.EstimatedAccounts[i] = resp.Accounts[i]
+		}
+		processed += resp.Accounts[i] - p.restored[i]
+		estimated += p.estimated[i]
+		remain += resp.EstimatedAccounts[i] - resp.Accounts[i]
+	}
+	if p.phase == CalcPhaseAccountDB && processed > 0 && estimated > 0 {
+		resp.ETA = uint64(time.Since(p.phaseStart).Seconds() * float64(remain) / float64(processed))
+	}
+
+	return resp
+}
+
+// subscribe sends CALCULATE_PROGRESS to c every interval while calculating. 0 interval unsubscribes
+func (p *calcProgress) subscribe(c ipc.Connection, interval time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if interval == 0 {
+		delete(p.subscribers, c)
+		return
+	}
+	p.subscribers[c] = &progressSubscriber{interval: interval}
+}
+
+// unsubscribe stops notification to the closed connection
+func (p *calcProgress) unsubscribe(c ipc.Connection) {
+	p.subscribe(c, 0)
+}
+
+// notify sends progress to subscribers periodically and the last progress to all subscribers when stopped
+func (p *calcProgress) notify(ctx *Context, stop <-chan struct{}) {
+	defer p.notifier.Done()
+	ticker := time.NewTicker(progressNotifyTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			p.lock.Lock()
+			targets := make([]ipc.Connection, 0, len(p.subscribers))
+			for c := range p.subscribers {
+				targets = append(targets, c)
+			}
+			p.lock.Unlock()
+			p.send(ctx, targets)
+			return
+		case now := <-ticker.C:
+			p.lock.Lock()
+			targets := make([]ipc.Connection, 0)
+			for c, s := range p.subscribers {
+				if now.Sub(s.last) >= s.interval {
+					s.last = now
+					targets = append(targets, c)
+				}
+			}
+			p.lock.Unlock()
+			p.send(ctx, targets)
+		}
+	}
+}
+
+func (p *calcProgress) send(ctx *Context, targets []ipc.Connection) {
+	if len(targets) == 0 {
+		return
+	}
+
+	resp := p.get(ctx)
+	for _, c := range targets {
+		if err := c.Send(MsgCalculateProgress, 0, resp); err != nil {
+			log.Printf("Failed to send %s. stop notification. %v", MsgToString(MsgCalculateProgress), err)
+			p.unsubscribe(c)
+		}
+	}
+}
+
+func (mh *msgHandler) queryCalculateProgress(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryCalculateProgress
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		return err
+	}
+	log.Printf("\t %s request: NotifyInterval: %d", MsgToString(MsgQueryCalculateProgress), req.NotifyInterval)
+
+	ctx := mh.mgr.ctx
+	ctx.progress.subscribe(c, time.Duration(req.NotifyInterval)*time.Second)
+	resp := DoQueryCalculateProgress(ctx)
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryCalculateProgress), id, resp.String())
+	return c.Send(MsgQueryCalculateProgress, id, resp)
+}
+
+// DoQueryCalculateProgress returns progress of the calculation
+func DoQueryCalculateProgress(ctx *Context) *CalculateProgress {
+	return ctx.progress.get(ctx)
+}
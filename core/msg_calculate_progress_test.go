@@ -0,0 +1,131 @@
+package core
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/ipc"
+	"github.com/stretchr/testify/assert"
+)
+
+// testConn records messages sent to it
+type testConn struct {
+	lock sync.Mutex
+	msgs []uint
+	data []interface{}
+	err  error
+}
+
+func (c *testConn) Send(msg uint, id uint32, data interface{}) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	c.msgs = append(c.msgs, msg)
+	c.data = append(c.data, data)
+	return nil
+}
+
+func (c *testConn) SendAndReceive(msg uint, id uint32, data interface{}, buf interface{}) error {
+	return c.Send(msg, id, data)
+}
+
+func (c *testConn) Receive(buf interface{}) (uint, uint32, error)   { return 0, 0, nil }
+func (c *testConn) SetHandler(msg uint, handler ipc.MessageHandler) {}
+func (c *testConn) HandleMessage() error                            { return nil }
+func (c *testConn) Close() error                                    { return nil }
+
+func (c *testConn) count() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.msgs)
+}
+
+func TestCalcProgress_get(t *testing.T) {
+	const blockHeight uint64 = 100
+
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	p := ctx.progress
+
+	// not calculating
+	resp := DoQueryCalculateProgress(ctx)
+	assert.Equal(t, CalculationDone, resp.Status)
+	assert.Equal(t, CalcPhaseNone, resp.Phase)
+
+	// estimate with statistics of the last calculation
+	ctx.stats = &Statistics{Accounts: 9}
+	ctx.DB.setCalculatingBH(blockHeight, nil)
+	p.start(ctx, blockHeight)
+	p.setPhase(CalcPhaseAccountDB)
+	p.restoreAccounts(0, 2)
+	for i := 0; i < 6; i++ {
+		p.addAccount(1)
+	}
+
+	resp = DoQueryCalculateProgress(ctx)
+	assert.Equal(t, CalculationDoing, resp.Status)
+	assert.Equal(t, blockHeight, resp.BlockHeight)
+	assert.Equal(t, CalcPhaseAccountDB, resp.Phase)
+	assert.Equal(t, []uint64{2, 6}, resp.Accounts)
+	assert.Equal(t, []uint64{5, 6}, resp.EstimatedAccounts)
+
+	p.setPhase(CalcPhaseIISSTX)
+	resp = DoQueryCalculateProgress(ctx)
+	assert.Equal(t, CalcPhaseIISSTX, resp.Phase)
+	assert.Equal(t, uint64(0), resp.ETA)
+	p.finish(true)
+
+	// accounts of the last calculation are the estimate of the next one
+	ctx.DB.setCalculatingBH(blockHeight + 1, nil)
+	p.start(ctx, blockHeight+1)
+	resp = DoQueryCalculateProgress(ctx)
+	assert.Equal(t, []uint64{0, 0}, resp.Accounts)
+	assert.Equal(t, []uint64{2, 6}, resp.EstimatedAccounts)
+	p.finish(false)
+}
+
+func TestCalcProgress_subscribe(t *testing.T) {
+	const blockHeight uint64 = 100
+
+	tick := progressNotifyTick
+	progressNotifyTick = 10 * time.Millisecond
+	defer func() {
+		progressNotifyTick = tick
+	}()
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	p := ctx.progress
+
+	conn := new(testConn)
+	closed := &testConn{err: errors.New("closed")}
+	p.subscribe(conn, time.Millisecond)
+	p.subscribe(closed, time.Millisecond)
+
+	ctx.DB.setCalculatingBH(blockHeight, nil)
+	p.start(ctx, blockHeight)
+	time.Sleep(100 * time.Millisecond)
+	p.finish(true)
+
+	// notification is sent while calculating and when calculation ends
+	count := conn.count()
+	assert.True(t, count > 1)
+	assert.Equal(t, uint(MsgCalculateProgress), conn.msgs[0])
+	assert.Equal(t, CalculationDoing, conn.data[0].(*CalculateProgress).Status)
+	assert.Equal(t, blockHeight, conn.data[0].(*CalculateProgress).BlockHeight)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, count, conn.count())
+
+	// failed connection is unsubscribed
+	_, ok := p.subscribers[closed]
+	assert.False(t, ok)
+	_, ok = p.subscribers[conn]
+	assert.True(t, ok)
+
+	p.unsubscribe(conn)
+	assert.Equal(t, 0, len(p.subscribers))
+}
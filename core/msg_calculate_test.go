@@ -22,11 +22,11 @@ func TestMsgCalc_CalculateIISSTX(t *testing.T) {
 	// set GV
 	gv := new(GovernanceVariable)
 	gv.BlockHeight = 0
-	gv.MainPRepCount.SetUint64(NumMainPRep)
-	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(1)
-	gv.RewardRep.SetUint64(minRewardRep)
-	gv.setReward()
+	gv.RewardRep.SetUint64(testNetwork.MinRewardRep)
+	gv.setReward(testNetwork)
 	ctx.GV = append(ctx.GV, gv)
 
 	// set P-Rep candidate
@@ -51,8 +51,8 @@ func TestMsgCalc_CalculateIISSTX(t *testing.T) {
 	// TX 0: Add new delegation at block height 10
 	// iconist delegates MinDelegation to prepA and delegates 2 * MinDelegation to prepB
 	dgDataSlice := []DelegateData{
-		{prepA.Address, *common.NewHexIntFromUint64(MinDelegation)},
-		{prepB.Address, *common.NewHexIntFromUint64(MinDelegation * 2)},
+		{prepA.Address, *common.NewHexIntFromUint64(testNetwork.MinDelegation())},
+		{prepB.Address, *common.NewHexIntFromUint64(testNetwork.MinDelegation() * 2)},
 	}
 	tx := makeIISSTX(TXDataTypeDelegate, iconist.String(), dgDataSlice)
 	tx.Index = 0
@@ -62,8 +62,8 @@ func TestMsgCalc_CalculateIISSTX(t *testing.T) {
 	// TX 1: Modify delegation at block height 20
 	// iconist delegates MinDelegation to prepA and delegates MinDelegation to iconist
 	dgDataSlice = []DelegateData{
-		{prepA.Address, *common.NewHexIntFromUint64(MinDelegation)},
-		{iconist, *common.NewHexIntFromUint64(MinDelegation)},
+		{prepA.Address, *common.NewHexIntFromUint64(testNetwork.MinDelegation())},
+		{iconist, *common.NewHexIntFromUint64(testNetwork.MinDelegation())},
 	}
 	tx = makeIISSTX(TXDataTypeDelegate, iconist.String(), dgDataSlice)
 	tx.Index = 1
@@ -79,8 +79,11 @@ func TestMsgCalc_CalculateIISSTX(t *testing.T) {
 	// write to IISS data DB
 	writeTX(iissDB, txList)
 
+	// enable reward ledger
+	ctx.DB.OpenRewardLedgerDB()
+
 	// calculate IISS TX
-	account, stats, hash := calculateIISSTX(ctx, iissDB, 100, false)
+	account, stats, hash := calculateIISSTX(ctx, iissDB, 100, writeBatchCount, false)
 	assert.Equal(t, uint64(1), account)
 
 	// check Calculate DB
@@ -95,22 +98,29 @@ func TestMsgCalc_CalculateIISSTX(t *testing.T) {
 
 	stateHash := make([]byte, 64)
 	h := sha3.NewShake256()
-	iaHash.IScore.SetUint64(3 * MinDelegation * (100 - 10) * minRewardRep / rewardDivider)
+	iaHash.IScore.SetUint64(3 * testNetwork.MinDelegation() * (100 - 10) * testNetwork.MinRewardRep / testNetwork.RewardDivider())
 	h.Write(iaHash.BytesForHash())
-	iaHash.IScore.SetUint64(3*MinDelegation*(20-10)*minRewardRep/rewardDivider +
-		MinDelegation*(100-20)*minRewardRep/rewardDivider)
+	iaHash.IScore.SetUint64(3*testNetwork.MinDelegation()*(20-10)*testNetwork.MinRewardRep/testNetwork.RewardDivider() +
+		testNetwork.MinDelegation()*(100-20)*testNetwork.MinRewardRep/testNetwork.RewardDivider())
 	h.Write(iaHash.BytesForHash())
-	iaHash.IScore.SetUint64(3*MinDelegation*(20-10)*minRewardRep/rewardDivider +
-		MinDelegation*(30-20)*minRewardRep/rewardDivider)
+	iaHash.IScore.SetUint64(3*testNetwork.MinDelegation()*(20-10)*testNetwork.MinRewardRep/testNetwork.RewardDivider() +
+		testNetwork.MinDelegation()*(30-20)*testNetwork.MinRewardRep/testNetwork.RewardDivider())
 	h.Write(iaHash.BytesForHash())
 	h.Read(stateHash)
 
-	reward := 3*MinDelegation*(20-10)*minRewardRep/rewardDivider +
-		MinDelegation*(30-20)*minRewardRep/rewardDivider
+	reward := 3*testNetwork.MinDelegation()*(20-10)*testNetwork.MinRewardRep/testNetwork.RewardDivider() +
+		testNetwork.MinDelegation()*(30-20)*testNetwork.MinRewardRep/testNetwork.RewardDivider()
 
 	assert.Equal(t, uint64(reward), ia.IScore.Uint64())
 	assert.Equal(t, uint64(reward), stats.Uint64())
 	assert.Equal(t, stateHash, hash)
+
+	// check reward ledger
+	rl, err := getRewardLedger(ctx.DB.getRewardLedgerDB(), 100, iconist)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(reward), rl.Beta3.Uint64())
+	assert.Equal(t, 0, rl.Beta1.Sign())
+	assert.Equal(t, 0, rl.Beta2.Sign())
 }
 
 func TestMsgCalc_CalculateIISSTX_small_delegation(t *testing.T) {
@@ -120,11 +130,11 @@ func TestMsgCalc_CalculateIISSTX_small_delegation(t *testing.T) {
 	// set GV
 	gv := new(GovernanceVariable)
 	gv.BlockHeight = 0
-	gv.MainPRepCount.SetUint64(NumMainPRep)
-	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(1)
-	gv.RewardRep.SetUint64(minRewardRep)
-	gv.setReward()
+	gv.RewardRep.SetUint64(testNetwork.MinRewardRep)
+	gv.setReward(testNetwork)
 	ctx.GV = append(ctx.GV, gv)
 
 	// set P-Rep candidate
@@ -149,7 +159,7 @@ func TestMsgCalc_CalculateIISSTX_small_delegation(t *testing.T) {
 	// TX 0: Add new delegation at block height 10
 	// iconist delegates MinDelegation - 1 to prepA
 	dgDataSlice := []DelegateData{
-		{prepA.Address, *common.NewHexIntFromUint64(MinDelegation - 1)},
+		{prepA.Address, *common.NewHexIntFromUint64(testNetwork.MinDelegation() - 1)},
 	}
 	tx := makeIISSTX(TXDataTypeDelegate, iconist.String(), dgDataSlice)
 	tx.Index = 0
@@ -160,7 +170,7 @@ func TestMsgCalc_CalculateIISSTX_small_delegation(t *testing.T) {
 	writeTX(iissDB, txList)
 
 	// calculate IISS TX
-	account, stats, hash := calculateIISSTX(ctx, iissDB, 100, false)
+	account, stats, hash := calculateIISSTX(ctx, iissDB, 100, writeBatchCount, false)
 	assert.Equal(t, uint64(1), account)
 
 	// check Calculate DB
@@ -195,20 +205,20 @@ func TestMsgCalc_CalculateIISSBlockProduce(t *testing.T) {
 	// set GV
 	gv := new(GovernanceVariable)
 	gv.BlockHeight = gv0BlockHeight
-	gv.MainPRepCount.SetUint64(NumMainPRep)
-	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(1)
 	gv.RewardRep.SetUint64(1)
-	gv.setReward()
+	gv.setReward(testNetwork)
 	ctx.GV = append(ctx.GV, gv)
 
 	gv = new(GovernanceVariable)
 	gv.BlockHeight = gv1BlockHeight
-	gv.MainPRepCount.SetUint64(NumMainPRep)
-	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(10)
 	gv.RewardRep.SetUint64(1)
-	gv.setReward()
+	gv.setReward(testNetwork)
 	ctx.GV = append(ctx.GV, gv)
 
 	// set P-Rep
@@ -256,7 +266,7 @@ func TestMsgCalc_CalculateIISSBlockProduce(t *testing.T) {
 	bucket.Set(bp.ID(), bs)
 
 	// calculate BP
-	account, stats, hash := calculateIISSBlockProduce(ctx, iissDB, 100, false)
+	account, stats, hash := calculateIISSBlockProduce(ctx, iissDB, 100, writeBatchCount, false)
 	assert.Equal(t, uint64(3), account)
 
 	calcDB := ctx.DB.getCalculateDB(iconist)
@@ -373,20 +383,20 @@ func testCalculatePRepReward(t *testing.T, revision uint64) {
 	// set GV
 	gv := new(GovernanceVariable)
 	gv.BlockHeight = BlockHeight0
-	gv.MainPRepCount.SetUint64(NumMainPRep)
-	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(1)
 	gv.RewardRep.SetUint64(1)
-	gv.setReward()
+	gv.setReward(testNetwork)
 	ctx.GV = append(ctx.GV, gv)
 
 	gv = new(GovernanceVariable)
 	gv.BlockHeight = BlockHeight1
-	gv.MainPRepCount.SetUint64(NumMainPRep)
-	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(2)
 	gv.RewardRep.SetUint64(1)
-	gv.setReward()
+	gv.setReward(testNetwork)
 	ctx.GV = append(ctx.GV, gv)
 
 	// P-Rep 0
@@ -430,7 +440,7 @@ func testCalculatePRepReward(t *testing.T, revision uint64) {
 	ctx.PRep = append(ctx.PRep, prep)
 
 	// calculate P-Rep reward
-	account, stats, hash := calculatePRepReward(ctx, BlockHeight2)
+	account, stats, hash := calculatePRepReward(ctx, BlockHeight2, writeBatchCount)
 	assert.Equal(t, uint64(3), account)
 
 	calcDB := ctx.DB.getCalculateDB(prepA)
@@ -554,18 +564,21 @@ func testCalculatePRepReward(t *testing.T, revision uint64) {
 
 func TestMsgCalc_CalculateDB(t *testing.T) {
 	const (
-		rewardRep = minRewardRep
-
 		calculateBlockHeight uint64 = 100
 
-		addr1BlockHeight       uint64 = 1
-		addr1InitIScore               = 100
-		addr1DelegationToPRepA        = 10 + MinDelegation
+		addr1BlockHeight uint64 = 1
+		addr1InitIScore         = 100
+
+		addr2BlockHeight uint64 = 10
+		addr2InitIScore         = 0
+	)
+	var (
+		rewardRep = testNetwork.MinRewardRep
+
+		addr1DelegationToPRepA = 10 + testNetwork.MinDelegation()
 
-		addr2BlockHeight       uint64 = 10
-		addr2InitIScore               = 0
-		addr2DelegationToPRepA        = 20 + MinDelegation
-		addr2DelegationToPRepB        = 30 + MinDelegation
+		addr2DelegationToPRepA = 20 + testNetwork.MinDelegation()
+		addr2DelegationToPRepB = 30 + testNetwork.MinDelegation()
 	)
 	ctx := initTest(1)
 	defer finalizeTest(ctx)
@@ -573,11 +586,11 @@ func TestMsgCalc_CalculateDB(t *testing.T) {
 	// set GV
 	gv := new(GovernanceVariable)
 	gv.BlockHeight = 0
-	gv.MainPRepCount.SetUint64(NumMainPRep)
-	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+	gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
 	gv.CalculatedIncentiveRep.SetUint64(1)
 	gv.RewardRep.SetUint64(rewardRep)
-	gv.setReward()
+	gv.setReward(testNetwork)
 	ctx.GV = append(ctx.GV, gv)
 
 	// set P-Rep candidate
@@ -632,7 +645,7 @@ func TestMsgCalc_CalculateDB(t *testing.T) {
 
 	// calculate
 	count, stats, hash := calculateDB(ctx.CancelCalculation.GetChannel(), 0, queryDB, calcDB, ctx,
-		calculateBlockHeight, writeBatchCount)
+		calculateBlockHeight, nil, nil, writeBatchCount, nil)
 
 	var reward, totalReward uint64
 	stateHash := make([]byte, 64)
@@ -646,7 +659,7 @@ func TestMsgCalc_CalculateDB(t *testing.T) {
 		return
 	}
 	// calculate delegation reward for P-Rep only
-	reward = gv.RewardRep.Uint64()*period*addr1DelegationToPRepA/rewardDivider + addr1InitIScore
+	reward = gv.RewardRep.Uint64()*period*addr1DelegationToPRepA/testNetwork.RewardDivider() + addr1InitIScore
 
 	bucket, _ = calcDB.GetBucket(db.PrefixIScore)
 	bs, _ := bucket.Get(addr1.Bytes())
@@ -667,7 +680,7 @@ func TestMsgCalc_CalculateDB(t *testing.T) {
 		assert.True(t, false)
 		return
 	}
-	reward = gv.RewardRep.Uint64()*period*(addr2DelegationToPRepA+addr2DelegationToPRepB)/rewardDivider + addr2InitIScore
+	reward = gv.RewardRep.Uint64()*period*(addr2DelegationToPRepA+addr2DelegationToPRepB)/testNetwork.RewardDivider() + addr2InitIScore
 
 	bs, _ = bucket.Get(addr2.Bytes())
 	ia, _ = NewIScoreAccountFromBytes(bs)
@@ -697,7 +710,7 @@ func TestMsgCalc_DoCalculate_Error(t *testing.T) {
 	req := CalculateRequest{Path: iissDBDir, BlockHeight: 100, BlockHash: testHash}
 
 	// get CALCULATE message while processing CALCULATE message
-	ctx.DB.setCalculatingBH(uint64(50))
+	ctx.DB.setCalculatingBH(uint64(50), nil)
 	err, blockHeight, _, _ := DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, 0)
 	assert.Error(t, err)
 	assert.True(t, strings.HasPrefix(err.Error(), "calculating now. drop calculate message"), err)
@@ -724,7 +737,7 @@ func TestMsgCalc_DoCalculate_Error(t *testing.T) {
 
 	// get CALCULATE message with duplicated block height
 	ctx.DB.setCalcDoneBH(uint64(100))
-	ctx.DB.setCalculatingBH(uint64(100))
+	ctx.DB.setCalculatingBH(uint64(100), nil)
 	err, blockHeight, _, _ = DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, 0)
 	assert.Error(t, err)
 	assert.True(t, strings.HasPrefix(err.Error(), "duplicated block"))
@@ -732,7 +745,7 @@ func TestMsgCalc_DoCalculate_Error(t *testing.T) {
 
 	// Rollback with ROLLBACK
 	ctx.DB.setCalcDoneBH(uint64(50))
-	ctx.DB.setCalculatingBH(uint64(50))
+	ctx.DB.setCalculatingBH(uint64(50), nil)
 
 	quitChannel := ctx.CancelCalculation.GetChannel()
 	ctx.CancelCalculation.notifyRollback()
@@ -764,6 +777,12 @@ func Test_calculating_value(t *testing.T) {
 	assert.Equal(t, bh, ctx.DB.getCalcDoneBH())
 	assert.Equal(t, bh, ctx.DB.getCalculatingBH())
 
+	// check state root
+	var resp QueryCalculateResultResponse
+	DoQueryCalculateResult(ctx, bh, &resp)
+	assert.NotEmpty(t, resp.StateRoot)
+	assert.Equal(t, ctx.getStateRoot(bh), resp.StateRoot)
+
 	// write IISS data DB BH : 150
 	bh = uint64(150)
 	_, iissDB = writeHeader(testDBDir, "iiss", bh)
@@ -811,7 +830,7 @@ func TestMsgQueryCalc_DoQueryCalculateStatus(t *testing.T) {
 
 	// start calculation
 	calcBH := uint64(1000)
-	ctx.DB.setCalculatingBH(calcBH)
+	ctx.DB.setCalculatingBH(calcBH, nil)
 
 	DoQueryCalculateStatus(ctx, &resp)
 	assert.Equal(t, CalculationDoing, resp.Status)
@@ -837,7 +856,7 @@ func TestMsgQueryCalc_DoQueryCalculateResult(t *testing.T) {
 	assert.Equal(t, blockHeight, resp.BlockHeight)
 
 	// start calculation
-	ctx.DB.setCalculatingBH(blockHeight)
+	ctx.DB.setCalculatingBH(blockHeight, nil)
 
 	DoQueryCalculateResult(ctx, blockHeight, &resp)
 	assert.Equal(t, calcDoing, resp.Status)
@@ -852,7 +871,7 @@ func TestMsgQueryCalc_DoQueryCalculateResult(t *testing.T) {
 	stateHash := make([]byte, 64)
 	binary.BigEndian.PutUint64(stateHash, blockHeight)
 
-	WriteCalculationResult(crDB, blockHeight, stats, stateHash)
+	WriteCalculationResult(crDB, blockHeight, stats, stateHash, nil, nil)
 
 	DoQueryCalculateResult(ctx, blockHeight, &resp)
 	assert.Equal(t, calcSucceeded, resp.Status)
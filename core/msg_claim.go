@@ -13,10 +13,6 @@ import (
 	"github.com/icon-project/rewardcalculator/common/ipc"
 )
 
-const claimMinIScore = 1000
-
-var BigIntClaimMinIScore = big.NewInt(claimMinIScore)
-
 type ClaimMessage struct {
 	Address     common.Address
 	BlockHeight uint64
@@ -45,7 +41,6 @@ func (rc *ResponseClaim) String() string {
 
 func (mh *msgHandler) claim(c ipc.Connection, id uint32, data []byte) error {
 	var req ClaimMessage
-	mh.mgr.AddMsgTask()
 	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
 		log.Printf("Failed to deserialize CLAIM message. err=%+v", err)
 		return err
@@ -61,7 +56,6 @@ func (mh *msgHandler) claim(c ipc.Connection, id uint32, data []byte) error {
 		resp.IScore.Set(&IScore.Int)
 	}
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgClaim), id, resp.String())
 	return c.Send(MsgClaim, id, &resp)
 }
@@ -71,6 +65,9 @@ func (mh *msgHandler) claim(c ipc.Connection, id uint32, data []byte) error {
 // It returns the I-Score block height and I-Score. nil I-Score means zero I-Score.
 // In error case, block height is zero and I-Score is nil.
 func DoClaim(ctx *Context, req *ClaimMessage) (uint64, *common.HexInt) {
+	ctx.DB.viewLock.RLock()
+	defer ctx.DB.viewLock.RUnlock()
+
 	pcDB := ctx.DB.getPreCommitDB()
 	preCommit := newPreCommit(req.BlockHeight, req.BlockHash, req.TXIndex, req.TXHash, req.Address)
 	if preCommit.query(pcDB) == true {
@@ -82,6 +79,7 @@ func DoClaim(ctx *Context, req *ClaimMessage) (uint64, *common.HexInt) {
 	var ia *IScoreAccount = nil
 	var err error
 	isDB := ctx.DB
+	claimMinIScore := new(big.Int).SetUint64(ctx.network.ClaimMinIScore)
 
 	var cDB, qDB db.Database
 	var bucket db.Bucket
@@ -120,12 +118,12 @@ func DoClaim(ctx *Context, req *ClaimMessage) (uint64, *common.HexInt) {
 		ia.IScore.Sub(&ia.IScore.Int, &claim.Data.IScore.Int)
 	}
 
-	// Can't claim an I-Score less than 1000
-	if ia.IScore.Cmp(BigIntClaimMinIScore) == -1 {
+	// Can't claim an I-Score less than ClaimMinIScore of network
+	if ia.IScore.Cmp(claimMinIScore) == -1 {
 		goto NoReward
 	} else {
 		var remain common.HexInt
-		remain.Mod(&ia.IScore.Int, BigIntClaimMinIScore)
+		remain.Mod(&ia.IScore.Int, claimMinIScore)
 		ia.IScore.Sub(&ia.IScore.Int, &remain.Int)
 	}
 
@@ -164,7 +162,6 @@ func (cc *CommitClaim) String() string {
 func (mh *msgHandler) commitClaim(c ipc.Connection, id uint32, data []byte) error {
 	var req CommitClaim
 	var err error
-	mh.mgr.AddMsgTask()
 
 	if _, err = codec.MP.UnmarshalFromBytes(data, &req); nil != err {
 		return err
@@ -177,7 +174,6 @@ func (mh *msgHandler) commitClaim(c ipc.Connection, id uint32, data []byte) erro
 		return nil
 	}
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgCommitClaim), id, "ack")
 	return c.Send(MsgCommitClaim, id, nil)
 }
@@ -215,7 +211,6 @@ func (sb *StartBlock) String() string {
 func (mh *msgHandler) startBlock(c ipc.Connection, id uint32, data []byte) error {
 	var req StartBlock
 	var err error
-	mh.mgr.AddMsgTask()
 	if _, err = codec.MP.UnmarshalFromBytes(data, &req); nil != err {
 		return err
 	}
@@ -230,7 +225,6 @@ func (mh *msgHandler) startBlock(c ipc.Connection, id uint32, data []byte) error
 	var resp StartBlock
 	resp = req
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgStartBlock), id, resp.String())
 	return c.Send(MsgStartBlock, id, &resp)
 }
@@ -251,24 +245,13 @@ func (cb *CommitBlock) String() string {
 func (mh *msgHandler) commitBlock(c ipc.Connection, id uint32, data []byte) error {
 	var req CommitBlock
 	var err error
-	mh.mgr.AddMsgTask()
 	if _, err = codec.MP.UnmarshalFromBytes(data, &req); nil != err {
 		return err
 	}
 	log.Printf("\t COMMIT_BLOCK request: %s", req.String())
 
 	ret := true
-	iDB := mh.mgr.ctx.DB
-	if req.Success == true {
-		err = writePreCommitToClaimDB(iDB.getPreCommitDB(), iDB.getClaimDB(), iDB.getClaimBackupDB(),
-			req.BlockHeight, req.BlockHash)
-		if err == nil {
-			mh.mgr.ctx.DB.setCurrentBlockInfo(req.BlockHeight, req.BlockHash)
-		}
-	} else {
-		err = flushPreCommit(iDB.getPreCommitDB(), req.BlockHeight, req.BlockHash)
-	}
-
+	err = DoCommitBlock(mh.mgr.ctx, &req)
 	if err != nil {
 		log.Printf("Failed to commit block. %+v", err)
 		ret = false
@@ -278,7 +261,27 @@ func (mh *msgHandler) commitBlock(c ipc.Connection, id uint32, data []byte) erro
 	resp = req
 	resp.Success = ret
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgCommitBlock), id, resp.String())
 	return c.Send(MsgCommitBlock, id, &resp)
 }
+
+// DoCommitBlock writes claims of the block to claim DB or flushes them.
+// QUERY and CLAIM wait until it finishes.
+func DoCommitBlock(ctx *Context, req *CommitBlock) error {
+	var err error
+	iDB := ctx.DB
+
+	iDB.viewLock.Lock()
+	defer iDB.viewLock.Unlock()
+
+	if req.Success == true {
+		err = writePreCommitToClaimDB(iDB.getPreCommitDB(), iDB.getClaimDB(), iDB.getClaimBackupDB(),
+			req.BlockHeight, req.BlockHash, iDB.getRollbackLimitBH(), ctx.network.ClaimBackupPeriod)
+		if err == nil {
+			iDB.setCurrentBlockInfo(req.BlockHeight, req.BlockHash)
+		}
+	} else {
+		err = flushPreCommit(iDB.getPreCommitDB(), req.BlockHeight, req.BlockHash)
+	}
+	return err
+}
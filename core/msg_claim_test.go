@@ -10,9 +10,9 @@ import (
 
 
 func TestMsgClaim_DoClaim(t *testing.T) {
-	const (
-		db1IScore = claimMinIScore + 100
-		db2IScore = claimMinIScore + 2000
+	var (
+		db1IScore = testNetwork.ClaimMinIScore + 100
+		db2IScore = testNetwork.ClaimMinIScore + 2000
 	)
 	address := common.NewAddressFromString("hx11")
 	dbContent0 := IScoreAccount { Address: *address }
@@ -58,7 +58,7 @@ func TestMsgClaim_DoClaim(t *testing.T) {
 	// claim I-Score
 	blockHeight, iScore = DoClaim(ctx, &claim)
 	assert.Equal(t, dbContent1.BlockHeight, blockHeight)
-	assert.Equal(t, uint64(db1IScore - (db1IScore % claimMinIScore)), iScore.Uint64())
+	assert.Equal(t, uint64(db1IScore - (db1IScore % testNetwork.ClaimMinIScore)), iScore.Uint64())
 
 	// commit claim - true
 	commit :=
@@ -77,7 +77,7 @@ func TestMsgClaim_DoClaim(t *testing.T) {
 
 	// write claim to DB
 	writePreCommitToClaimDB(ctx.DB.getPreCommitDB(), ctx.DB.getClaimDB(), ctx.DB.getClaimBackupDB(),
-		claim.BlockHeight, claim.BlockHash)
+		claim.BlockHeight, claim.BlockHash, ctx.DB.getRollbackLimitBH(), ctx.network.ClaimBackupPeriod)
 
 	// invalid address
 	blockHeight, iScore = DoClaim(ctx, &invalidAddressClaim)
@@ -99,6 +99,6 @@ func TestMsgClaim_DoClaim(t *testing.T) {
 	var iScoreExpected common.HexInt
 	iScoreExpected.Sub(&dbContent2.IScore.Int, &dbContent1.IScore.Int)
 	// db2Iscore - claimed IScore
-	assert.Equal(t, uint64(db2IScore - (db2IScore % claimMinIScore) - (db1IScore - (db1IScore % claimMinIScore))),
+	assert.Equal(t, uint64(db2IScore - (db2IScore % testNetwork.ClaimMinIScore) - (db1IScore - (db1IScore % testNetwork.ClaimMinIScore))),
 		iScore.Uint64())
 }
@@ -29,6 +29,10 @@ const (
 	DebugCalcAddAddress           = DebugCalc + 2
 	DebugCalcDelAddress           = DebugCalc + 3
 	DebugCalcListAddresses        = DebugCalc + 4
+
+	DebugDryRun uint64 = 300
+
+	DebugConnections uint64 = 400
 )
 
 type DebugMessage struct {
@@ -40,12 +44,12 @@ type MessageData struct {
 	Address     common.Address
 	OutputPath  string
 	BlockHeight uint64
+	Path        string // IISS data path for dry-run calculation
 }
 
 func (mh *msgHandler) debug(c ipc.Connection, id uint32, data []byte) error {
 	var req DebugMessage
 	var result error
-	mh.mgr.AddMsgTask()
 	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
 		log.Printf("Failed to deserialize DEBUG message. err=%+v", err)
 		return err
@@ -80,11 +84,14 @@ func (mh *msgHandler) debug(c ipc.Connection, id uint32, data []byte) error {
 		result = handleCalcDebugAddresses(c, id, ctx)
 	case DebugCalcDebugResult:
 		result = handleQueryCalcDebugResult(c, id, ctx, req.Address, req.BlockHeight)
+	case DebugDryRun:
+		result = handleDryRun(c, id, ctx, req.Path)
+	case DebugConnections:
+		result = handleConnections(c, id, mh.mgr.conns)
 	default:
 		result = fmt.Errorf("unknown debug message %d", req.Cmd)
 	}
 
-	mh.mgr.DoneMsgTask()
 	return result
 }
 
@@ -128,8 +135,9 @@ type ResponseDebugPRep struct {
 func handlePRep(c ipc.Connection, id uint32, ctx *Context) error {
 	var resp ResponseDebugPRep
 	resp.Cmd = DebugPRep
-	resp.PReps = make([]PRep, len(ctx.PRep))
-	for i, p := range ctx.PRep {
+	state := ctx.snapshotState()
+	resp.PReps = make([]PRep, len(state.PRep))
+	for i, p := range state.PRep {
 		resp.PReps[i] = *p
 	}
 
@@ -144,9 +152,10 @@ type ResponseDebugPRepCandidate struct {
 func handlePRepCandidate(c ipc.Connection, id uint32, ctx *Context) error {
 	var resp ResponseDebugPRepCandidate
 	resp.Cmd = DebugPRepCandidate
-	resp.PRepCandidates = make([]PRepCandidate, len(ctx.PRepCandidates))
+	state := ctx.snapshotState()
+	resp.PRepCandidates = make([]PRepCandidate, len(state.PRepCandidates))
 	i := 0
-	for _, p := range ctx.PRepCandidates {
+	for _, p := range state.PRepCandidates {
 		resp.PRepCandidates[i] = *p
 		i++
 	}
@@ -162,9 +171,10 @@ type ResponseDebugGV struct {
 func handleGV(c ipc.Connection, id uint32, ctx *Context) error {
 	var resp ResponseDebugGV
 	resp.Cmd = DebugGV
-	if len(ctx.GV) > 0 {
-		resp.GV = make([]GovernanceVariable, len(ctx.GV))
-		for i, p := range ctx.GV {
+	state := ctx.snapshotState()
+	if len(state.GV) > 0 {
+		resp.GV = make([]GovernanceVariable, len(state.GV))
+		for i, p := range state.GV {
 			resp.GV[i] = *p
 		}
 	} else {
@@ -237,6 +247,30 @@ func handleCalcDebugAddresses(c ipc.Connection, id uint32, ctx *Context) error {
 	return c.Send(MsgDebug, id, &resp)
 }
 
+type ResponseDebugDryRun struct {
+	DebugMessage
+	Success bool
+	Error   string
+	Result  DryRunResult
+}
+
+func handleDryRun(c ipc.Connection, id uint32, ctx *Context, path string) error {
+	var resp ResponseDebugDryRun
+	resp.Cmd = DebugDryRun
+	resp.Path = path
+
+	result, err := DryRunCalculate(ctx, path)
+	if err != nil {
+		log.Printf("Failed to dry-run calculation. %v", err)
+		resp.Error = err.Error()
+	} else {
+		resp.Success = true
+		resp.Result = *result
+	}
+
+	return c.Send(MsgDebug, id, &resp)
+}
+
 type ResponseQueryCalcDebugResult struct {
 	Results []*CalcDebugResult
 }
@@ -246,7 +280,7 @@ func handleQueryCalcDebugResult(c ipc.Connection, id uint32, ctx *Context,
 
 	var resp ResponseQueryCalcDebugResult
 
-	calcDebugDB := db.Open(ctx.DB.info.DBRoot, string(db.GoLevelDBBackend), "calculation_debug")
+	calcDebugDB := db.Open(ctx.DB.info.DBRoot, ctx.DB.info.DBType, "calculation_debug")
 	defer calcDebugDB.Close()
 	CalcDebugKeys, err := GetCalcDebugResultKeys(calcDebugDB, blockHeight)
 	if err != nil {
@@ -327,3 +361,16 @@ func NewCalcDebugResult(key []byte, value []byte) (*CalcDebugResult, error) {
 	dr.BlockHash = "0x" + hex.EncodeToString(blockHash)
 	return dr, nil
 }
+
+type ResponseDebugConnections struct {
+	DebugMessage
+	Connections []ConnectionInfo
+}
+
+func handleConnections(c ipc.Connection, id uint32, conns *connRegistry) error {
+	var resp ResponseDebugConnections
+	resp.Cmd = DebugConnections
+	resp.Connections = conns.list()
+
+	return c.Send(MsgDebug, id, &resp)
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const MaxQueryBatchCount = 1000
+
+// QueryBatch queries I-Score of Addresses. If Addresses is empty, it queries accounts in I-Score DB page by page.
+// Accounts are ordered by account DB index and address. Set Next of the previous response to Cursor for next page.
+type QueryBatch struct {
+	Addresses []common.Address
+	Cursor    []byte // address of the last account in the previous page. empty for the first page
+	Limit     uint64 // the number of accounts in a page. MAX: MaxQueryBatchCount
+}
+
+func (q *QueryBatch) String() string {
+	return fmt.Sprintf("Addresses: %d, Cursor: %s, Limit: %d",
+		len(q.Addresses),
+		hex.EncodeToString(q.Cursor),
+		q.Limit)
+}
+
+type ResponseQueryBatch struct {
+	Results []ResponseQuery
+	Next    []byte // cursor of next page. empty if there is no more account
+	Error   string // reason of failure. Results and Next are empty if it is not empty
+}
+
+func (resp *ResponseQueryBatch) String() string {
+	return fmt.Sprintf("Results: %d, Next: %s, Error: %s", len(resp.Results), hex.EncodeToString(resp.Next),
+		resp.Error)
+}
+
+func (mh *msgHandler) queryBatch(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryBatch
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		return err
+	}
+	log.Printf("\t QUERY_BATCH request: %s", req.String())
+
+	resp, err := DoQueryBatch(mh.mgr.ctx, &req)
+	if err != nil {
+		log.Printf("Failed to query batch. %v", err)
+		resp = &ResponseQueryBatch{Results: make([]ResponseQuery, 0), Error: err.Error()}
+	}
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryBatch), id, resp.String())
+	return c.Send(MsgQueryBatch, id, resp)
+}
+
+// DoQueryBatch returns I-Score of accounts like QUERY. All accounts are read at the same point.
+func DoQueryBatch(ctx *Context, req *QueryBatch) (*ResponseQueryBatch, error) {
+	resp := new(ResponseQueryBatch)
+	resp.Results = make([]ResponseQuery, 0)
+
+	isDB := ctx.DB
+	isDB.viewLock.RLock()
+	defer isDB.viewLock.RUnlock()
+
+	claimBucket, _ := isDB.getClaimDB().GetBucket(db.PrefixIScore)
+
+	if len(req.Addresses) == 0 {
+		return resp, queryAccountPage(isDB, claimBucket, req, resp)
+	}
+
+	if len(req.Addresses) > MaxQueryBatchCount {
+		return resp, fmt.Errorf("too many addresses %d. MAX: %d", len(req.Addresses), MaxQueryBatchCount)
+	}
+
+	// group addresses by account DB index
+	queryDBList := isDB.getQueryDBList()
+	groups := make([][]int, len(queryDBList))
+	for i, address := range req.Addresses {
+		index := isDB.getAccountDBIndex(address)
+		groups[index] = append(groups[index], i)
+	}
+
+	resp.Results = make([]ResponseQuery, len(req.Addresses))
+	for index, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		bucket, _ := queryDBList[index].GetBucket(db.PrefixIScore)
+		for _, i := range group {
+			address := req.Addresses[i]
+			bs, _ := bucket.Get(address.Bytes())
+			claimBytes, _ := claimBucket.Get(address.Bytes())
+			resp.Results[i] = *newResponseQuery(address, bs, claimBytes)
+		}
+	}
+
+	return resp, nil
+}
+
+// queryAccountPage reads accounts after cursor in account DB index and address order
+func queryAccountPage(isDB *IScoreDB, claimBucket db.Bucket, req *QueryBatch, resp *ResponseQueryBatch) error {
+	limit := req.Limit
+	if limit == 0 || limit > MaxQueryBatchCount {
+		limit = MaxQueryBatchCount
+	}
+
+	queryDBList := isDB.getQueryDBList()
+	prefix := util.BytesPrefix([]byte(db.PrefixIScore))
+	startIndex := 0
+	start := prefix.Start
+	if len(req.Cursor) != 0 {
+		cursor := common.NewAddress(req.Cursor)
+		startIndex = isDB.getAccountDBIndex(*cursor)
+		start = append([]byte(db.PrefixIScore), cursor.Bytes()...)
+	}
+
+	for index := startIndex; index < len(queryDBList); index++ {
+		iter, _ := queryDBList[index].GetIterator()
+		if index == startIndex {
+			iter.New(start, prefix.Limit)
+		} else {
+			iter.New(prefix.Start, prefix.Limit)
+		}
+		for iter.Next() {
+			key := iter.Key()
+			if index == startIndex && bytes.Equal(key, start) && len(req.Cursor) != 0 {
+				// skip the last account of the previous page
+				continue
+			}
+			address := common.NewAddress(key[len(db.PrefixIScore):])
+			claimBytes, _ := claimBucket.Get(address.Bytes())
+			resp.Results = append(resp.Results, *newResponseQuery(*address, iter.Value(), claimBytes))
+
+			if uint64(len(resp.Results)) == limit {
+				resp.Next = address.Bytes()
+				break
+			}
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+		if resp.Next != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgQueryBatch_DoQueryBatch(t *testing.T) {
+	ctx := initTest(3)
+	defer finalizeTest(ctx)
+
+	addresses := writeTestAccounts(makeTestAccounts(10), ctx.DB.getQueryDB)
+
+	// claimed account
+	var claim Claim
+	claim.Address = addresses[3]
+	claim.Data.BlockHeight = 101
+	claim.Data.IScore.SetUint64(testNetwork.ClaimMinIScore)
+	bucket, _ := ctx.DB.getClaimDB().GetBucket(db.PrefixIScore)
+	bucket.Set(claim.ID(), claim.Bytes())
+
+	unknown := *common.NewAddressFromString("hx1234")
+	req := &QueryBatch{Addresses: []common.Address{addresses[5], unknown, addresses[3], addresses[0]}}
+	resp, err := DoQueryBatch(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, len(req.Addresses), len(resp.Results))
+	for i, result := range resp.Results {
+		assert.Equal(t, req.Addresses[i], result.Address)
+		assert.Equal(t, DoQuery(ctx, &Query{Address: req.Addresses[i]}).IScore, result.IScore)
+	}
+	assert.Equal(t, uint64(0), resp.Results[1].BlockHeight)
+	assert.Equal(t, testNetwork.ClaimMinIScore*3, resp.Results[2].IScore.Uint64())
+
+	// too many addresses
+	req.Addresses = make([]common.Address, MaxQueryBatchCount+1)
+	resp, err = DoQueryBatch(ctx, req)
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(resp.Results))
+}
+
+func TestMsgQueryBatch_DoQueryBatch_Page(t *testing.T) {
+	ctx := initTest(3)
+	defer finalizeTest(ctx)
+
+	addresses := writeTestAccounts(makeTestAccounts(10), ctx.DB.getQueryDB)
+
+	found := make(map[common.Address]bool)
+	req := &QueryBatch{Limit: 4}
+	pages := 0
+	for {
+		resp, err := DoQueryBatch(ctx, req)
+		assert.NoError(t, err)
+		pages++
+		for _, result := range resp.Results {
+			assert.False(t, found[result.Address])
+			found[result.Address] = true
+			assert.Equal(t, DoQuery(ctx, &Query{Address: result.Address}).IScore, result.IScore)
+		}
+		if len(resp.Next) == 0 {
+			assert.True(t, len(resp.Results) < int(req.Limit))
+			break
+		}
+		assert.Equal(t, int(req.Limit), len(resp.Results))
+		req.Cursor = resp.Next
+	}
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, len(addresses), len(found))
+	for _, address := range addresses {
+		assert.True(t, found[address])
+	}
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+// QueryEstimate requests I-Score of Address which will be calculated at BlockHeight
+type QueryEstimate struct {
+	Address     common.Address
+	BlockHeight uint64
+}
+
+func (q *QueryEstimate) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d", q.Address.String(), q.BlockHeight)
+}
+
+// ResponseQueryEstimate has estimated I-Score of the account at BlockHeight.
+// The estimate assumes that delegations of the account and P-Rep candidates do not change and
+// the latest governance variable is applied after GVBlockHeight. It has delegation reward only.
+type ResponseQueryEstimate struct {
+	Address            common.Address
+	BlockHeight        uint64
+	IScore             common.HexInt // estimated claimable I-Score at BlockHeight
+	CurrentBlockHeight uint64        // block height of the latest calculation of the account
+	CurrentIScore      common.HexInt // claimable I-Score at CurrentBlockHeight. same as QUERY
+
+	// assumptions of the estimate
+	GVBlockHeight uint64
+	RewardRep     common.HexInt
+	Delegations   []*DelegateData // delegations to P-Rep candidates which get reward
+}
+
+func (resp *ResponseQueryEstimate) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d, IScore: %s, CurrentBlockHeight: %d, CurrentIScore: %s, "+
+		"GVBlockHeight: %d, RewardRep: %s, Delegations: %d",
+		resp.Address.String(),
+		resp.BlockHeight,
+		resp.IScore.String(),
+		resp.CurrentBlockHeight,
+		resp.CurrentIScore.String(),
+		resp.GVBlockHeight,
+		resp.RewardRep.String(),
+		len(resp.Delegations))
+}
+
+func (mh *msgHandler) queryEstimate(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryEstimate
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		return err
+	}
+	log.Printf("\t QUERY_ESTIMATE request: %s", req.String())
+
+	resp := DoQueryEstimate(mh.mgr.ctx, &req)
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryEstimate), id, resp.String())
+	return c.Send(MsgQueryEstimate, id, resp)
+}
+
+// DoQueryEstimate calculates delegation reward of the account up to req.BlockHeight in memory
+func DoQueryEstimate(ctx *Context, req *QueryEstimate) *ResponseQueryEstimate {
+	isDB := ctx.DB
+
+	// read account and claim like QUERY
+	isDB.viewLock.RLock()
+	bucket, _ := isDB.getClaimDB().GetBucket(db.PrefixIScore)
+	claimBytes, _ := bucket.Get(req.Address.Bytes())
+	bucket, _ = isDB.getQueryDB(req.Address).GetBucket(db.PrefixIScore)
+	bs, _ := bucket.Get(req.Address.Bytes())
+	isDB.viewLock.RUnlock()
+
+	current := newResponseQuery(req.Address, bs, claimBytes)
+	resp := new(ResponseQueryEstimate)
+	resp.Address = req.Address
+	resp.BlockHeight = req.BlockHeight
+	resp.CurrentBlockHeight = current.BlockHeight
+	resp.CurrentIScore.Set(&current.IScore.Int)
+	resp.IScore.Set(&current.IScore.Int)
+
+	// do not write calculation debug result with estimate
+	estCtx := ctx.snapshotState()
+	estCtx.calcDebug = &CalcDebug{conf: NewCalcDebugConfig()}
+	if len(estCtx.GV) > 0 {
+		gv := estCtx.GV[len(estCtx.GV)-1]
+		resp.GVBlockHeight = gv.BlockHeight
+		resp.RewardRep.Set(&gv.RewardRep.Int)
+	}
+
+	if bs == nil || req.BlockHeight <= current.BlockHeight {
+		return resp
+	}
+	ia, err := NewIScoreAccountFromBytes(bs)
+	if err != nil {
+		log.Printf("Failed to read account %s. %v", req.Address.String(), err)
+		return resp
+	}
+	ia.Address = req.Address
+
+	minDelegation := estCtx.getRewardPolicy(req.BlockHeight).MinDelegation(estCtx.network)
+	for _, dg := range ia.Delegations {
+		if _, ok := estCtx.PRepCandidates[dg.Address]; ok && minDelegation <= dg.Delegate.Uint64() {
+			resp.Delegations = append(resp.Delegations, dg)
+		}
+	}
+
+	// calculate reward from the latest calculation of the account
+	ia.IScore.SetUint64(0)
+	if ok, reward := calculateIScore(estCtx, ia, req.BlockHeight); ok {
+		resp.IScore.Add(&resp.IScore.Int, &reward.Int)
+	}
+	return resp
+}
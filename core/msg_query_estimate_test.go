@@ -0,0 +1,94 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgQueryEstimate_DoQueryEstimate(t *testing.T) {
+	const (
+		accountBH     uint64 = 100
+		gvChangeBH    uint64 = 150
+		estimateBH    uint64 = 200
+		initIScore           = 5000
+		claimedIScore        = 1000
+	)
+	var (
+		delegation = testNetwork.MinDelegation() + 10
+		rewardRep1 = testNetwork.MinRewardRep
+		rewardRep2 = testNetwork.MinRewardRep * 2
+	)
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	for _, v := range []struct {
+		blockHeight uint64
+		rewardRep   uint64
+	}{{0, rewardRep1}, {gvChangeBH, rewardRep2}} {
+		gv := new(GovernanceVariable)
+		gv.BlockHeight = v.blockHeight
+		gv.MainPRepCount.SetUint64(testNetwork.NumMainPRep)
+		gv.SubPRepCount.SetUint64(testNetwork.NumSubPRep)
+		gv.CalculatedIncentiveRep.SetUint64(1)
+		gv.RewardRep.SetUint64(v.rewardRep)
+		gv.setReward(testNetwork)
+		ctx.GV = append(ctx.GV, gv)
+	}
+
+	prep := new(PRepCandidate)
+	prep.Address = *common.NewAddressFromString("hxaa")
+	ctx.PRepCandidates[prep.Address] = prep
+
+	// account with delegations to P-Rep candidate and unknown address
+	ia := new(IScoreAccount)
+	ia.Address = *common.NewAddressFromString("hx11")
+	ia.BlockHeight = accountBH
+	ia.IScore.SetUint64(initIScore)
+	ia.Delegations = append(ia.Delegations, &DelegateData{Address: prep.Address})
+	ia.Delegations[0].Delegate.SetUint64(delegation)
+	ia.Delegations = append(ia.Delegations, &DelegateData{Address: *common.NewAddressFromString("hxbb")})
+	ia.Delegations[1].Delegate.SetUint64(delegation)
+	bucket, _ := ctx.DB.getQueryDB(ia.Address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+
+	var claim Claim
+	claim.Address = ia.Address
+	claim.Data.BlockHeight = accountBH + 1
+	claim.Data.IScore.SetUint64(claimedIScore)
+	bucket, _ = ctx.DB.getClaimDB().GetBucket(db.PrefixIScore)
+	bucket.Set(claim.ID(), claim.Bytes())
+
+	resp := DoQueryEstimate(ctx, &QueryEstimate{Address: ia.Address, BlockHeight: estimateBH})
+	reward := delegation*(gvChangeBH-accountBH)*rewardRep1/testNetwork.RewardDivider() +
+		delegation*(estimateBH-gvChangeBH)*rewardRep2/testNetwork.RewardDivider()
+	assert.Equal(t, estimateBH, resp.BlockHeight)
+	assert.Equal(t, accountBH, resp.CurrentBlockHeight)
+	assert.Equal(t, uint64(initIScore-claimedIScore), resp.CurrentIScore.Uint64())
+	assert.Equal(t, uint64(initIScore-claimedIScore+reward), resp.IScore.Uint64())
+	assert.Equal(t, gvChangeBH, resp.GVBlockHeight)
+	assert.Equal(t, uint64(rewardRep2), resp.RewardRep.Uint64())
+	assert.Equal(t, 1, len(resp.Delegations))
+	assert.Equal(t, prep.Address, resp.Delegations[0].Address)
+
+	// same as QUERY
+	query := DoQuery(ctx, &Query{Address: ia.Address})
+	assert.Equal(t, query.IScore.Uint64(), resp.CurrentIScore.Uint64())
+
+	// account was not changed
+	bucket, _ = ctx.DB.getQueryDB(ia.Address).GetBucket(db.PrefixIScore)
+	bs, _ := bucket.Get(ia.Address.Bytes())
+	assert.Equal(t, ia.Bytes(), bs)
+
+	// past block height
+	resp = DoQueryEstimate(ctx, &QueryEstimate{Address: ia.Address, BlockHeight: accountBH})
+	assert.Equal(t, resp.CurrentIScore.Uint64(), resp.IScore.Uint64())
+
+	// unknown account
+	resp = DoQueryEstimate(ctx, &QueryEstimate{Address: *common.NewAddressFromString("hx1234"), BlockHeight: estimateBH})
+	assert.Equal(t, uint64(0), resp.IScore.Uint64())
+	assert.Equal(t, uint64(0), resp.CurrentBlockHeight)
+	assert.Equal(t, 0, len(resp.Delegations))
+}
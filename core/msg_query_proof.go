@@ -0,0 +1,119 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+// QueryProof requests inclusion proof of Address in the state root of the latest calculation
+type QueryProof struct {
+	Address common.Address
+}
+
+func (q *QueryProof) String() string {
+	return fmt.Sprintf("Address: %s", q.Address.String())
+}
+
+// ResponseQueryProof has account of the latest calculation and its inclusion proof.
+// IScore is not subtracted by claimed I-Score. Proof is empty if there is no account.
+type ResponseQueryProof struct {
+	Address            common.Address
+	BlockHeight        uint64 // block height of the calculation
+	StateRoot          []byte
+	IScore             common.HexInt
+	AccountBlockHeight uint64
+	Proof              []MerkleProofNode
+}
+
+func (resp *ResponseQueryProof) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d, StateRoot: %s, IScore: %s, AccountBlockHeight: %d, Proof: %d",
+		resp.Address.String(),
+		resp.BlockHeight,
+		hex.EncodeToString(resp.StateRoot),
+		resp.IScore.String(),
+		resp.AccountBlockHeight,
+		len(resp.Proof))
+}
+
+// Verify checks the account with Proof and StateRoot
+func (resp *ResponseQueryProof) Verify() bool {
+	leaf := AccountLeafHash(resp.Address, resp.AccountBlockHeight, &resp.IScore)
+	return VerifyMerkleProof(resp.StateRoot, leaf, resp.Proof)
+}
+
+func (mh *msgHandler) queryProof(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryProof
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		return err
+	}
+	log.Printf("\t QUERY_PROOF request: %s", req.String())
+
+	resp, err := DoQueryProof(mh.mgr.ctx, &req)
+	if err != nil {
+		log.Printf("Failed to query proof. %v", err)
+	}
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryProof), id, resp.String())
+	return c.Send(MsgQueryProof, id, resp)
+}
+
+// DoQueryProof returns account of the latest calculation with inclusion proof
+func DoQueryProof(ctx *Context, req *QueryProof) (*ResponseQueryProof, error) {
+	resp := new(ResponseQueryProof)
+	resp.Address = req.Address
+
+	tree, err := ctx.getStateTree()
+	if err != nil {
+		return resp, err
+	}
+	resp.BlockHeight = tree.blockHeight
+	resp.StateRoot = tree.root()
+
+	chunk := tree.chunkOf(req.Address)
+	if chunk < 0 {
+		return resp, nil
+	}
+
+	// read accounts of the chunk from the DB set which has accounts of the tree
+	isDB := ctx.DB
+	isDB.viewLock.RLock()
+	defer isDB.viewLock.RUnlock()
+	isDB.closeLock.RLock()
+	defer isDB.closeLock.RUnlock()
+
+	if calcDoneBH := isDB.getCalcDoneBH(); calcDoneBH != tree.blockHeight {
+		return resp, fmt.Errorf("calculation of %d is done while reading proof of %d", calcDoneBH, tree.blockHeight)
+	}
+	accounts, err := readStateTreeChunk(tree, chunk, isDB.getAccountDBSet(tree.set))
+	if err != nil {
+		return resp, err
+	}
+
+	index := -1
+	leaves := make([][]byte, len(accounts))
+	for i, ia := range accounts {
+		leaves[i] = AccountLeafHash(ia.Address, ia.BlockHeight, &ia.IScore)
+		if ia.Address == req.Address {
+			index = i
+		}
+	}
+	proof, err := tree.proof(chunk, leaves, index)
+	if err != nil {
+		// account DB set was changed. Make the tree again with next query
+		ctx.setStateTree(nil)
+		return resp, err
+	}
+	if index < 0 {
+		return resp, nil
+	}
+
+	resp.IScore.Set(&accounts[index].IScore.Int)
+	resp.AccountBlockHeight = accounts[index].BlockHeight
+	resp.Proof = proof
+	return resp, nil
+}
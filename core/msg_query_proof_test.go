@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgQueryProof_DoQueryProof(t *testing.T) {
+	const calcBH uint64 = 100
+	ctx := initTest(3)
+	defer finalizeTest(ctx)
+
+	accounts := makeTestAccounts(40)
+	for i, ia := range accounts {
+		ia.IScore.SetUint64(uint64(i * 10))
+	}
+	writeTestAccounts(accounts, ctx.DB.getCalculateDB)
+	tree, _ := buildStateTree(nil, calcBH, ctx.DB.GetCalcDBList())
+	ctx.DB.setCalcDoneBH(calcBH)
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), calcBH, nil, nil, tree.root(), nil)
+
+	for _, ia := range accounts {
+		resp, err := DoQueryProof(ctx, &QueryProof{Address: ia.Address})
+		assert.NoError(t, err)
+		assert.Equal(t, calcBH, resp.BlockHeight)
+		assert.Equal(t, tree.root(), resp.StateRoot)
+		assert.Equal(t, ia.IScore.Uint64(), resp.IScore.Uint64())
+		assert.Equal(t, ia.BlockHeight, resp.AccountBlockHeight)
+		assert.True(t, resp.Verify())
+	}
+
+	// unknown account
+	resp, err := DoQueryProof(ctx, &QueryProof{Address: *common.NewAddressFromString("hx1234")})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(resp.Proof))
+	assert.False(t, resp.Verify())
+
+	// accounts are in query DB after next calculation toggled account DB
+	ctx.setStateTree(nil)
+	querySet := ctx.DB.getCalcDBSet()
+	ctx.DB.toggleAccountDB(calcBH + 51)
+	resp, err = DoQueryProof(ctx, &QueryProof{Address: accounts[3].Address})
+	assert.NoError(t, err)
+	assert.True(t, resp.Verify())
+	assert.Equal(t, querySet, ctx.getCachedStateTree().set)
+
+	// modified account
+	resp.IScore.SetUint64(1)
+	assert.False(t, resp.Verify())
+
+	// no state root
+	ctx.DB.setCalcDoneBH(calcBH + 50)
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), calcBH+50, nil, nil, nil, nil)
+	_, err = DoQueryProof(ctx, &QueryProof{Address: accounts[3].Address})
+	assert.Error(t, err)
+}
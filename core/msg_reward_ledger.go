@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+type QueryRewardLedger struct {
+	Address     common.Address
+	BlockHeight uint64 // block height of calculation. 0 means the latest calculation
+}
+
+func (q *QueryRewardLedger) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d", q.Address.String(), q.BlockHeight)
+}
+
+type ResponseQueryRewardLedger struct {
+	Address     common.Address
+	BlockHeight uint64
+	IScore      common.HexInt
+	Beta1       common.HexInt
+	Beta2       common.HexInt
+	Beta3       common.HexInt
+}
+
+func (resp *ResponseQueryRewardLedger) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d, IScore: %s, Beta1: %s, Beta2: %s, Beta3: %s",
+		resp.Address.String(),
+		resp.BlockHeight,
+		resp.IScore.String(),
+		resp.Beta1.String(),
+		resp.Beta2.String(),
+		resp.Beta3.String())
+}
+
+func (mh *msgHandler) queryRewardLedger(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryRewardLedger
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		return err
+	}
+	log.Printf("\t QUERY_REWARD_LEDGER request: %s", req.String())
+
+	resp, err := DoQueryRewardLedger(mh.mgr.ctx, &req)
+	if err != nil {
+		log.Printf("Failed to query reward ledger. %v", err)
+	}
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryRewardLedger), id, resp.String())
+	return c.Send(MsgQueryRewardLedger, id, resp)
+}
+
+// DoQueryRewardLedger returns Beta1, Beta2 and Beta3 I-Score of an account earned in a term.
+// Returns zero I-Score if reward ledger is disabled or there is no reward in the term.
+func DoQueryRewardLedger(ctx *Context, req *QueryRewardLedger) (*ResponseQueryRewardLedger, error) {
+	resp := new(ResponseQueryRewardLedger)
+	resp.Address = req.Address
+	resp.BlockHeight = req.BlockHeight
+	if resp.BlockHeight == 0 {
+		resp.BlockHeight = ctx.DB.getCalcDoneBH()
+	}
+
+	rlDB := ctx.DB.getRewardLedgerDB()
+	if rlDB == nil {
+		return resp, fmt.Errorf("reward ledger is disabled")
+	}
+
+	rl, err := getRewardLedger(rlDB, resp.BlockHeight, req.Address)
+	if err != nil || rl == nil {
+		return resp, err
+	}
+
+	resp.Beta1.Set(&rl.Beta1.Int)
+	resp.Beta2.Set(&rl.Beta2.Int)
+	resp.Beta3.Set(&rl.Beta3.Int)
+	resp.IScore.Set(&rl.IScore().Int)
+
+	return resp, nil
+}
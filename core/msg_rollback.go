@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
 	"github.com/icon-project/rewardcalculator/common/ipc"
 )
 
@@ -33,7 +34,6 @@ func (mh *msgHandler) rollback(c ipc.Connection, id uint32, data []byte) error {
 	success := true
 	var req RollBackRequest
 	var err error
-	mh.mgr.AddMsgTask()
 	if _, err = codec.MP.UnmarshalFromBytes(data, &req); err != nil {
 		return err
 	}
@@ -42,6 +42,7 @@ func (mh *msgHandler) rollback(c ipc.Connection, id uint32, data []byte) error {
 	ctx := mh.mgr.ctx
 
 	err = DoRollBack(ctx, &req)
+	observeRollback(err)
 
 	if err != nil {
 		log.Printf("Failed to rollback %d. %v", req.BlockHeight, err)
@@ -55,7 +56,6 @@ func (mh *msgHandler) rollback(c ipc.Connection, id uint32, data []byte) error {
 	resp.BlockHash = make([]byte, BlockHashSize)
 	copy(resp.BlockHash, req.BlockHash)
 
-	mh.mgr.DoneMsgTask()
 	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgRollBack), id, resp.String())
 	return c.Send(MsgRollBack, id, &resp)
 }
@@ -75,6 +75,10 @@ func DoRollBack(ctx *Context, req *RollBackRequest) error {
 	// notify rollback to other goroutines
 	ctx.CancelCalculation.notifyRollback()
 
+	// QUERY and CLAIM wait until claim DB and account DB are rolled back
+	idb.viewLock.Lock()
+	defer idb.viewLock.Unlock()
+
 	// must Rollback claim DB first
 	err = rollbackClaimDB(ctx, blockHeight, req.BlockHash)
 	if err != nil {
@@ -93,15 +97,25 @@ func DoRollBack(ctx *Context, req *RollBackRequest) error {
 	// rollback GV and Main/Sub P-Rep list
 	ctx.RollbackManagementDB(blockHeight)
 
+	// checkpoints of the canceled calculation can't be resumed
+	idb.deleteCalcCheckpoint()
+
 	return nil
 }
 
 func checkRollback(ctx *Context, rollback uint64) error {
-	idb := ctx.DB
-	if idb.getPrevCalcDoneBH() >= rollback {
-		return &RollbackLowBlockHeightError{idb.getPrevCalcDoneBH(), rollback}
+	limit := ctx.DB.getRollbackLimitBH()
+	if limit >= rollback {
+		return &RollbackLowBlockHeightError{limit, rollback}
 	}
-	return nil
+
+	// claim DB must have backup of rollback block height
+	var cbInfo ClaimBackupInfo
+	bucket, _ := ctx.DB.getClaimBackupDB().GetBucket(db.PrefixManagement)
+	bs, _ := bucket.Get(cbInfo.ID())
+	cbInfo.SetBytes(bs)
+	_, err := checkClaimDBRollback(&cbInfo, rollback)
+	return err
 }
 
 func checkAccountDBRollback(ctx *Context, rollback uint64) bool {
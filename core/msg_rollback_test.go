@@ -3,6 +3,8 @@ package core
 import (
 	"testing"
 
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -64,6 +66,50 @@ func TestMsgRollback_checkAccountDBRollback(t *testing.T) {
 	assert.False(t, checkAccountDBRollback(ctx, calcBlockHeight+1))
 }
 
+func TestMsgRollback_DoRollBack_MultiTerm(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetBackupCount(3)
+
+	// terms are longer than claim backup period
+	ctx.network.ClaimBackupPeriod = 5
+
+	ia := makeIA()
+	hash := make([]byte, BlockHashSize)
+	for _, calcBH := range []uint64{10, 20, 30, 40} {
+		emulateCalculation(ctx, ia, calcBH, true)
+		for bh := calcBH + 1; bh <= calcBH+10; bh++ {
+			if bh == 15 {
+				pc := newPreCommit(bh, hash, 0, hash, ia.Address)
+				assert.NoError(t, pc.write(ctx.DB.getPreCommitDB(), common.NewHexIntFromUint64(testNetwork.ClaimMinIScore)))
+				assert.NoError(t, DoCommitClaim(ctx, &CommitClaim{Success: true, Address: ia.Address,
+					BlockHeight: bh, BlockHash: hash}))
+			}
+			assert.NoError(t, DoCommitBlock(ctx, &CommitBlock{Success: true, BlockHeight: bh, BlockHash: hash}))
+		}
+	}
+	claimBucket, _ := ctx.DB.getClaimDB().GetBucket(db.PrefixIScore)
+	bs, _ := claimBucket.Get(ia.Address.Bytes())
+	assert.NotNil(t, bs)
+
+	// checkpoint of calculation canceled by rollback
+	cp := newCalcCheckpoint(0, 50, hash, nil, ctx.DB.info.DBCount, nil, 0, new(Statistics))
+	assert.NoError(t, ctx.DB.writeCalcCheckpoint(cp))
+
+	// rollback 3 terms
+	assert.Equal(t, uint64(10), ctx.DB.getRollbackLimitBH())
+	assert.Error(t, DoRollBack(ctx, &RollBackRequest{BlockHeight: 10, BlockHash: hash}))
+	assert.NoError(t, DoRollBack(ctx, &RollBackRequest{BlockHeight: 11, BlockHash: hash}))
+	assert.Equal(t, uint64(10), ctx.DB.getCalcDoneBH())
+	assert.Equal(t, uint64(10), readIScore(t, ctx.DB.getCalculateDB(ia.Address), ia))
+	assert.Equal(t, uint64(11), ctx.DB.getCurrentBlockInfo().BlockHeight)
+	assert.False(t, ctx.DB.hasCalcCheckpoint(50, hash, nil))
+
+	// claim was rolled back
+	bs, _ = claimBucket.Get(ia.Address.Bytes())
+	assert.Nil(t, bs)
+}
+
 func TestRollback_newChannel(t *testing.T) {
 	var c CancelCalculation
 
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"github.com/icon-project/rewardcalculator/common/db"
 	"testing"
+	"time"
 
 	"github.com/icon-project/rewardcalculator/common"
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ func TestMsg_DoQuery(t *testing.T) {
 	address := common.NewAddressFromString("hx11")
 	dbContent0 := IScoreAccount { Address: *address }
 	dbContent0.BlockHeight = 100
-	dbContent0.IScore.SetUint64(claimMinIScore + 100)
+	dbContent0.IScore.SetUint64(testNetwork.ClaimMinIScore + 100)
 	blockHeight := uint64(101)
 	blockHash := []byte("1a1")
 	txHash := make([]byte, TXHashSize)
@@ -74,7 +75,7 @@ func TestMsg_DoQuery(t *testing.T) {
 
 	// commit to claim DB
 	writePreCommitToClaimDB(ctx.DB.getPreCommitDB(), ctx.DB.getClaimDB(), ctx.DB.getClaimBackupDB(),
-		claim.BlockHeight, claim.BlockHash)
+		claim.BlockHeight, claim.BlockHash, ctx.DB.getRollbackLimitBH(), ctx.network.ClaimBackupPeriod)
 
 	// Query to claimed Account after commit
 	resp = DoQuery(ctx, query)
@@ -82,6 +83,44 @@ func TestMsg_DoQuery(t *testing.T) {
 	assert.Equal(t, int64(100), resp.IScore.Int64())
 }
 
+func TestMsg_DoQuery_ConsistentView(t *testing.T) {
+	address := common.NewAddressFromString("hx11")
+	ia := IScoreAccount{Address: *address}
+	ia.BlockHeight = 100
+	ia.IScore.SetUint64(testNetwork.ClaimMinIScore + 100)
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	bucket, _ := ctx.DB.getQueryDB(*address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+
+	// QUERY waits while claim DB is being changed
+	ctx.DB.viewLock.Lock()
+	respCh := make(chan *ResponseQuery)
+	go func() {
+		respCh <- DoQuery(ctx, &Query{Address: *address})
+	}()
+
+	select {
+	case <-respCh:
+		assert.Fail(t, "QUERY must wait for write lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	var claim Claim
+	claim.Address = *address
+	claim.Data.BlockHeight = 101
+	claim.Data.IScore.SetUint64(testNetwork.ClaimMinIScore)
+	cBucket, _ := ctx.DB.getClaimDB().GetBucket(db.PrefixIScore)
+	cBucket.Set(claim.ID(), claim.Bytes())
+	ctx.DB.viewLock.Unlock()
+
+	resp := <-respCh
+	assert.Equal(t, ia.BlockHeight, resp.BlockHeight)
+	assert.Equal(t, int64(100), resp.IScore.Int64())
+}
+
 func TestMsg_DoInit(t *testing.T) {
 	ctx := initTest(1)
 	defer finalizeTest(ctx)
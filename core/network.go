@@ -0,0 +1,191 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+const (
+	NetworkMainnet = "mainnet"
+	NetworkEuljiro = "euljiro"
+	NetworkYeouido = "yeouido"
+)
+
+// NetworkProfile has IISS economic constants of a network.
+// It is written to management DB when I-Score DB is created and can't be changed after.
+type NetworkProfile struct {
+	Name              string
+	BlocksPerYear     uint64
+	GVDivider         uint64
+	IScoreMultiplier  uint64
+	MinRewardRep      uint64
+	ClaimMinIScore    uint64
+	ClaimBackupPeriod uint64 // the number of blocks to keep claim backup
+	NumMainPRep       uint64
+	NumSubPRep        uint64
+}
+
+var mainnetProfile = NetworkProfile{
+	Name:              NetworkMainnet,
+	BlocksPerYear:     15552000,
+	GVDivider:         10000,
+	IScoreMultiplier:  1000,
+	MinRewardRep:      200,
+	ClaimMinIScore:    1000,
+	ClaimBackupPeriod: 43120*2 - 1,
+	NumMainPRep:       22,
+	NumSubPRep:        78,
+}
+
+// networkProfiles has built-in profiles. Testnets use the economic constants of mainnet.
+// Other networks set NetworkProfile in configuration file
+var networkProfiles = map[string]NetworkProfile{
+	NetworkMainnet: mainnetProfile,
+	NetworkEuljiro: withName(mainnetProfile, NetworkEuljiro),
+	NetworkYeouido: withName(mainnetProfile, NetworkYeouido),
+}
+
+func withName(np NetworkProfile, name string) NetworkProfile {
+	np.Name = name
+	return np
+}
+
+// GetNetworkProfile returns the built-in profile of network
+func GetNetworkProfile(name string) (*NetworkProfile, error) {
+	np, ok := networkProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid network %s. %v", name, GetNetworkList())
+	}
+	return &np, nil
+}
+
+// GetNetworkList returns names of built-in profiles
+func GetNetworkList() []string {
+	names := make([]string, 0, len(networkProfiles))
+	for name := range networkProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RewardDivider divides delegation amount * period * reward rate to get I-Score of delegation reward
+func (np *NetworkProfile) RewardDivider() uint64 {
+	return np.BlocksPerYear * np.GVDivider / np.IScoreMultiplier
+}
+
+// MinDelegation is the minimum delegation amount which gets delegation reward with minimum reward rate
+func (np *NetworkProfile) MinDelegation() uint64 {
+	return np.BlocksPerYear / np.IScoreMultiplier * (np.GVDivider / np.MinRewardRep)
+}
+
+func (np *NetworkProfile) Validate() error {
+	if len(np.Name) == 0 {
+		return fmt.Errorf("network name is empty")
+	}
+	if np.BlocksPerYear == 0 || np.GVDivider == 0 || np.IScoreMultiplier == 0 || np.MinRewardRep == 0 ||
+		np.ClaimMinIScore == 0 || np.ClaimBackupPeriod == 0 || np.NumMainPRep == 0 {
+		return fmt.Errorf("invalid network profile %s. values must be positive except NumSubPRep", np.String())
+	}
+	if np.RewardDivider() == 0 || np.MinDelegation() == 0 {
+		return fmt.Errorf("invalid network profile %s. MinDelegation and reward divider must be positive",
+			np.String())
+	}
+	return nil
+}
+
+// sameConstants returns true if np has the same economic constants with other
+func (np *NetworkProfile) sameConstants(other *NetworkProfile) bool {
+	return withName(*np, "") == withName(*other, "")
+}
+
+func (np *NetworkProfile) ID() []byte {
+	return []byte("")
+}
+
+func (np *NetworkProfile) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(np); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (np *NetworkProfile) String() string {
+	b, err := json.Marshal(np)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (np *NetworkProfile) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, np)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// readNetworkProfile returns the profile of management DB. nil if there is no profile
+func readNetworkProfile(mngDB db.Database) (*NetworkProfile, error) {
+	bucket, _ := mngDB.GetBucket(db.PrefixNetworkProfile)
+	np := new(NetworkProfile)
+	bs, err := bucket.Get(np.ID())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	if err = np.SetBytes(bs); err != nil {
+		return nil, err
+	}
+	return np, nil
+}
+
+// ReadNetworkProfile returns the profile of management DB. I-Score DB without profile uses mainnet
+func ReadNetworkProfile(mngDB db.Database) (*NetworkProfile, error) {
+	np, err := readNetworkProfile(mngDB)
+	if err != nil || np != nil {
+		return np, err
+	}
+	return GetNetworkProfile(NetworkMainnet)
+}
+
+// loadNetworkProfile returns the profile of management DB. np is written to management DB if there is no profile.
+// With nil np, it returns the profile of management DB or mainnet.
+// I-Score DB which was calculated without profile has used the constants of mainnet.
+func loadNetworkProfile(mngDB db.Database, np *NetworkProfile, calculated bool) (*NetworkProfile, error) {
+	bucket, _ := mngDB.GetBucket(db.PrefixNetworkProfile)
+	stored, err := readNetworkProfile(mngDB)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil {
+		if np != nil && *stored != *np {
+			return nil, fmt.Errorf("I-Score DB is for network profile %s. Can't change it to %s",
+				stored.String(), np.String())
+		}
+		return stored, nil
+	}
+
+	if np == nil {
+		np, _ = GetNetworkProfile(NetworkMainnet)
+	}
+	if calculated && !np.sameConstants(&mainnetProfile) {
+		return nil, fmt.Errorf("I-Score DB was calculated with the constants of %s. Can't change it to %s",
+			mainnetProfile.String(), np.String())
+	}
+
+	value, _ := np.Bytes()
+	if err = bucket.Set(np.ID(), value); err != nil {
+		return nil, err
+	}
+	log.Printf("Write network profile %s", np.String())
+	return np, nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+// testNetwork is the network profile of contexts of tests
+var testNetwork, _ = GetNetworkProfile(NetworkMainnet)
+
+func TestNetwork_GetNetworkProfile(t *testing.T) {
+	np, err := GetNetworkProfile(NetworkMainnet)
+	assert.NoError(t, err)
+	assert.Equal(t, NetworkMainnet, np.Name)
+	assert.Equal(t, uint64(15552000*10000/1000), np.RewardDivider())
+	assert.Equal(t, uint64(15552000/1000*(10000/200)), np.MinDelegation())
+	assert.NoError(t, np.Validate())
+
+	// returns a copy
+	np.BlocksPerYear = 1
+	np, _ = GetNetworkProfile(NetworkMainnet)
+	assert.Equal(t, uint64(15552000), np.BlocksPerYear)
+
+	_, err = GetNetworkProfile("private")
+	assert.Error(t, err)
+	assert.Equal(t, []string{NetworkEuljiro, NetworkMainnet, NetworkYeouido}, GetNetworkList())
+
+	np, err = GetNetworkProfile(NetworkYeouido)
+	assert.NoError(t, err)
+	assert.True(t, np.sameConstants(&mainnetProfile))
+	assert.Equal(t, uint64(22), np.NumMainPRep)
+	assert.Equal(t, uint64(78), np.NumSubPRep)
+
+	np.NumMainPRep = 0
+	assert.Error(t, np.Validate())
+	np.NumMainPRep = 22
+	np.NumSubPRep = 0
+	assert.NoError(t, np.Validate())
+
+	np.MinRewardRep = np.GVDivider + 1
+	assert.Error(t, np.Validate())
+}
+
+func TestNetwork_NewContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	private := mainnetProfile
+	private.Name = "private"
+	private.BlocksPerYear = mainnetProfile.BlocksPerYear / 2
+	private.ClaimBackupPeriod = 99
+
+	// new I-Score DB
+	ctx, err := NewContext(dir, testDBType, "test", 1, "", &private)
+	assert.NoError(t, err)
+	assert.Equal(t, private, *ctx.network)
+
+	// context of other I-Score DB has its own profile
+	other, err := NewContext(dir, testDBType, "other", 1, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, mainnetProfile, *other.network)
+	assert.Equal(t, private, *ctx.network)
+	CloseIScoreDB(other.DB)
+	CloseIScoreDB(ctx.DB)
+
+	// use profile of I-Score DB
+	ctx, err = NewContext(dir, testDBType, "test", 1, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, private, *ctx.network)
+	CloseIScoreDB(ctx.DB)
+
+	// can't change profile
+	mainnet, _ := GetNetworkProfile(NetworkMainnet)
+	_, err = NewContext(dir, testDBType, "test", 1, "", mainnet)
+	assert.Error(t, err)
+}
+
+func TestNetwork_loadNetworkProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	mngDB := db.Open(dir, testDBType, "network")
+	defer mngDB.Close()
+
+	private := mainnetProfile
+	private.Name = "private"
+	private.ClaimMinIScore = 1
+	renamed := withName(mainnetProfile, "renamed")
+
+	// I-Score DB which was calculated without profile
+	_, err = loadNetworkProfile(mngDB, &private, true)
+	assert.Error(t, err)
+	np, err := loadNetworkProfile(mngDB, &renamed, true)
+	assert.NoError(t, err)
+	assert.Equal(t, renamed, *np)
+
+	np, err = loadNetworkProfile(mngDB, nil, true)
+	assert.NoError(t, err)
+	assert.Equal(t, renamed, *np)
+	_, err = loadNetworkProfile(mngDB, &mainnetProfile, true)
+	assert.Error(t, err)
+}
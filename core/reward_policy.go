@@ -0,0 +1,308 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// RewardPolicy is the reward formula of an ICON Service revision.
+// IISS changes of a new revision are implemented as a new policy and registered to rewardPolicies.
+type RewardPolicy interface {
+	// Revision returns the first revision of ICON Service which uses the policy
+	Revision() uint64
+
+	// MinDelegation returns the minimum delegation amount of network np which gets delegation reward
+	MinDelegation(np *NetworkProfile) uint64
+
+	// DelegationReward returns delegation reward(beta3) of delegation for period with gv of network np
+	DelegationReward(np *NetworkProfile, delegation *common.HexInt, period uint64, gv *GovernanceVariable) *common.HexInt
+
+	// BlockProduceReward returns block produce reward(beta1) of the generator and of each validator
+	BlockProduceReward(gv *GovernanceVariable, validatorCount int) (*common.HexInt, *common.HexInt)
+
+	// PRepReward returns Main/Sub P-Rep reward(beta2) of delegated amount in total delegation for period with gv
+	PRepReward(delegated *common.HexInt, total *common.HexInt, period uint64, gv *GovernanceVariable) *common.HexInt
+
+	// PRepRewardBlockHeight returns block height of the account which got P-Rep reward for period to end.
+	// ia is nil if there is no account and rewardEnd is the end of the last period which has reward.
+	PRepRewardBlockHeight(ia *IScoreAccount, rewardEnd uint64, end uint64, blockHeight uint64) uint64
+}
+
+// rewardPolicies is sorted by revision
+var rewardPolicies = []RewardPolicy{
+	new(revision0Policy),
+	new(revision8Policy),
+}
+
+// GetRewardPolicy returns the policy of ICON Service revision
+func GetRewardPolicy(revision uint64) RewardPolicy {
+	index := sort.Search(len(rewardPolicies), func(i int) bool {
+		return rewardPolicies[i].Revision() > revision
+	})
+	return rewardPolicies[index-1]
+}
+
+// revision0Policy is the reward formula before revision 8
+type revision0Policy struct{}
+
+func (p *revision0Policy) Revision() uint64 {
+	return 0
+}
+
+func (p *revision0Policy) MinDelegation(np *NetworkProfile) uint64 {
+	return np.MinDelegation()
+}
+
+func (p *revision0Policy) DelegationReward(np *NetworkProfile, delegation *common.HexInt, period uint64,
+	gv *GovernanceVariable) *common.HexInt {
+	// reward = delegation amount * period * GV / rewardDivider
+	reward := common.NewHexIntFromUint64(period)
+	reward.Mul(&reward.Int, &delegation.Int)
+	reward.Mul(&reward.Int, &gv.RewardRep.Int)
+	reward.Div(&reward.Int, new(big.Int).SetUint64(np.RewardDivider()))
+	return reward
+}
+
+func (p *revision0Policy) BlockProduceReward(gv *GovernanceVariable,
+	validatorCount int) (*common.HexInt, *common.HexInt) {
+	generator := new(common.HexInt)
+	generator.Set(&gv.BlockProduceReward.Int)
+
+	// validators share block produce reward
+	validator := new(common.HexInt)
+	if validatorCount > 0 {
+		validator.Div(&gv.BlockProduceReward.Int, &common.NewHexInt(int64(validatorCount)).Int)
+	}
+	return generator, validator
+}
+
+func (p *revision0Policy) PRepReward(delegated *common.HexInt, total *common.HexInt, period uint64,
+	gv *GovernanceVariable) *common.HexInt {
+	// reward = period * GV * delegated amount / total delegation
+	reward := common.NewHexIntFromUint64(period)
+	reward.Mul(&reward.Int, &gv.PRepReward.Int)
+	reward.Mul(&reward.Int, &delegated.Int)
+	reward.Div(&reward.Int, &total.Int)
+	return reward
+}
+
+func (p *revision0Policy) PRepRewardBlockHeight(ia *IScoreAccount, rewardEnd uint64, end uint64,
+	blockHeight uint64) uint64 {
+	if ia == nil {
+		return end
+	}
+	return rewardEnd
+}
+
+// revision8Policy does not change block height of account with P-Rep reward.
+// Block height of account is for delegation reward only.
+type revision8Policy struct {
+	revision0Policy
+}
+
+func (p *revision8Policy) Revision() uint64 {
+	return Revision8
+}
+
+func (p *revision8Policy) PRepRewardBlockHeight(ia *IScoreAccount, rewardEnd uint64, end uint64,
+	blockHeight uint64) uint64 {
+	if ia == nil {
+		return blockHeight
+	}
+	return ia.BlockHeight
+}
+
+// rewardPolicySwitch changes reward policy at BlockHeight. It is written to management DB with Revision
+type rewardPolicySwitch struct {
+	BlockHeight uint64
+	Revision    uint64 // revision of ICON Service from BlockHeight
+	Policy      RewardPolicy
+}
+
+func newRewardPolicySwitch(blockHeight uint64, revision uint64) *rewardPolicySwitch {
+	return &rewardPolicySwitch{BlockHeight: blockHeight, Revision: revision, Policy: GetRewardPolicy(revision)}
+}
+
+func (s *rewardPolicySwitch) ID() []byte {
+	bs := make([]byte, 8)
+	id := common.Uint64ToBytes(s.BlockHeight)
+	copy(bs[len(bs)-len(id):], id)
+	return bs
+}
+
+func (s *rewardPolicySwitch) Bytes() ([]byte, error) {
+	return codec.MarshalToBytes(s.Revision)
+}
+
+func (s *rewardPolicySwitch) SetBytes(bs []byte) error {
+	if _, err := codec.UnmarshalFromBytes(bs, &s.Revision); err != nil {
+		return err
+	}
+	s.Policy = GetRewardPolicy(s.Revision)
+	return nil
+}
+
+func (s *rewardPolicySwitch) String() string {
+	return fmt.Sprintf("BlockHeight: %d, Revision: %d, Policy: %d", s.BlockHeight, s.Revision, s.Policy.Revision())
+}
+
+// loadRewardPolicySwitches reads reward policy switches from management DB in block height order
+func loadRewardPolicySwitches(dbi db.Database) ([]*rewardPolicySwitch, error) {
+	switches := make([]*rewardPolicySwitch, 0)
+
+	iter, err := dbi.GetIterator()
+	if err != nil {
+		return switches, err
+	}
+
+	prefix := util.BytesPrefix([]byte(db.PrefixRewardPolicySwitch))
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		s := new(rewardPolicySwitch)
+		if err = s.SetBytes(iter.Value()); err != nil {
+			log.Printf("Failed to load reward policy switch. %v", err)
+			continue
+		}
+		s.BlockHeight = common.BytesToUint64(iter.Key()[len(db.PrefixRewardPolicySwitch):])
+		switches = append(switches, s)
+	}
+	sort.Slice(switches, func(i, j int) bool {
+		return switches[i].BlockHeight < switches[j].BlockHeight
+	})
+
+	// finalize iterator
+	iter.Release()
+	if err = iter.Error(); err != nil {
+		log.Printf("There is error while load reward policy switch iteration. %+v", err)
+		return switches, err
+	}
+
+	return switches, nil
+}
+
+// writeRewardPolicySwitches writes added switches to management DB and deletes removed ones
+func (ctx *Context) writeRewardPolicySwitches(added []*rewardPolicySwitch, removed []*rewardPolicySwitch) {
+	bucket, _ := ctx.DB.management.GetBucket(db.PrefixRewardPolicySwitch)
+	for _, s := range removed {
+		if err := bucket.Delete(s.ID()); err != nil {
+			log.Printf("Failed to delete reward policy switch. %s. %v", s.String(), err)
+		}
+	}
+	for _, s := range added {
+		value, _ := s.Bytes()
+		if err := bucket.Set(s.ID(), value); err != nil {
+			log.Printf("Failed to write reward policy switch. %s. %v", s.String(), err)
+		}
+	}
+}
+
+// updateRevision sets revision of ICON Service with IISS header of the calculation of blockHeight.
+// Revision of header applies to the whole term unless RevisionBlockHeight is in the term.
+// Then the revision of the previous calculation applies before RevisionBlockHeight.
+// Previous terms keep their reward policy.
+func (ctx *Context) updateRevision(header *IISSHeader, blockHeight uint64) {
+	start := ctx.DB.getCalcDoneBH()
+
+	// switches in the term were made by canceled calculation
+	ctx.dropRewardPolicySwitches(start)
+	prev := ctx.getRewardPolicy(start)
+	prevRevision := ctx.Revision
+	ctx.Revision = header.Revision
+	if GetRewardPolicy(header.Revision).Revision() == prev.Revision() {
+		return
+	}
+
+	switchBH := start
+	if start < header.RevisionBlockHeight && header.RevisionBlockHeight <= blockHeight {
+		switchBH = header.RevisionBlockHeight
+	}
+	if len(ctx.rewardPolicySwitches) == 0 {
+		// policy before the first switch
+		base := newRewardPolicySwitch(0, prevRevision)
+		ctx.rewardPolicySwitches = append(ctx.rewardPolicySwitches, base)
+		ctx.writeRewardPolicySwitches([]*rewardPolicySwitch{base}, nil)
+	}
+	ctx.SetRewardPolicySwitch(switchBH, header.Revision)
+}
+
+// dropRewardPolicySwitches deletes switches from blockHeight
+func (ctx *Context) dropRewardPolicySwitches(blockHeight uint64) {
+	switches := make([]*rewardPolicySwitch, 0, len(ctx.rewardPolicySwitches))
+	removed := make([]*rewardPolicySwitch, 0)
+	for _, s := range ctx.rewardPolicySwitches {
+		if s.BlockHeight < blockHeight {
+			switches = append(switches, s)
+		} else {
+			removed = append(removed, s)
+		}
+	}
+	ctx.rewardPolicySwitches = switches
+	ctx.writeRewardPolicySwitches(nil, removed)
+}
+
+// rollbackRewardPolicySwitches deletes switches of terms after the calculation of blockHeight
+// and restores revision with the latest switch
+func (ctx *Context) rollbackRewardPolicySwitches(blockHeight uint64) {
+	ctx.dropRewardPolicySwitches(blockHeight)
+	if n := len(ctx.rewardPolicySwitches); n > 0 {
+		ctx.Revision = ctx.rewardPolicySwitches[n-1].Revision
+	}
+}
+
+// SetRewardPolicySwitch switches reward policy to the policy of revision from blockHeight.
+// Reward policy of a term is the policy of IISS header revision without switch.
+func (ctx *Context) SetRewardPolicySwitch(blockHeight uint64, revision uint64) {
+	// replace switches after blockHeight
+	ctx.dropRewardPolicySwitches(blockHeight)
+	s := newRewardPolicySwitch(blockHeight, revision)
+	ctx.rewardPolicySwitches = append(ctx.rewardPolicySwitches, s)
+	ctx.writeRewardPolicySwitches([]*rewardPolicySwitch{s}, nil)
+	log.Printf("Set reward policy switch. %s", s.String())
+}
+
+// getRewardPolicy returns reward policy at blockHeight
+func (ctx *Context) getRewardPolicy(blockHeight uint64) RewardPolicy {
+	for i := len(ctx.rewardPolicySwitches) - 1; i >= 0; i-- {
+		if ctx.rewardPolicySwitches[i].BlockHeight <= blockHeight {
+			return ctx.rewardPolicySwitches[i].Policy
+		}
+	}
+	return GetRewardPolicy(ctx.Revision)
+}
+
+// forEachRewardPeriod splits period [start, end) with governance variables and reward policy switches
+// and calls f with each period
+func (ctx *Context) forEachRewardPeriod(start uint64, end uint64,
+	f func(s uint64, e uint64, gv *GovernanceVariable, policy RewardPolicy)) {
+	for i, gv := range ctx.GV {
+		var s, e = start, end
+
+		if s < gv.BlockHeight {
+			s = gv.BlockHeight
+		}
+		if i+1 < len(ctx.GV) && ctx.GV[i+1].BlockHeight < end {
+			e = ctx.GV[i+1].BlockHeight
+		}
+
+		// split period with reward policy switches
+		for s < e {
+			policy := ctx.getRewardPolicy(s)
+			pe := e
+			for _, ps := range ctx.rewardPolicySwitches {
+				if s < ps.BlockHeight && ps.BlockHeight < pe {
+					pe = ps.BlockHeight
+					break
+				}
+			}
+			f(s, pe, gv, policy)
+			s = pe
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPolicyRevision uint64 = 100
+
+// testPolicy doubles delegation reward of revision 8
+type testPolicy struct {
+	revision8Policy
+}
+
+func (p *testPolicy) Revision() uint64 {
+	return testPolicyRevision
+}
+
+func (p *testPolicy) DelegationReward(np *NetworkProfile, delegation *common.HexInt, period uint64,
+	gv *GovernanceVariable) *common.HexInt {
+	reward := p.revision8Policy.DelegationReward(np, delegation, period, gv)
+	reward.Add(&reward.Int, &reward.Int)
+	return reward
+}
+
+func setTestPolicy() func() {
+	policies := rewardPolicies
+	rewardPolicies = append(append([]RewardPolicy(nil), policies...), new(testPolicy))
+	return func() {
+		rewardPolicies = policies
+	}
+}
+
+func TestRewardPolicy_GetRewardPolicy(t *testing.T) {
+	assert.Equal(t, uint64(0), GetRewardPolicy(0).Revision())
+	assert.Equal(t, uint64(0), GetRewardPolicy(RevisionMin-1).Revision())
+	assert.Equal(t, Revision8, GetRewardPolicy(Revision8).Revision())
+	assert.Equal(t, Revision8, GetRewardPolicy(testPolicyRevision).Revision())
+
+	defer setTestPolicy()()
+	assert.Equal(t, Revision8, GetRewardPolicy(testPolicyRevision-1).Revision())
+	assert.Equal(t, testPolicyRevision, GetRewardPolicy(testPolicyRevision).Revision())
+}
+
+func TestRewardPolicy_SetRewardPolicySwitch(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	setRevision(ctx, RevisionMin-1)
+
+	ctx.SetRewardPolicySwitch(100, Revision8)
+	ctx.SetRewardPolicySwitch(200, RevisionMin-1)
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(99).Revision())
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(100).Revision())
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(199).Revision())
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(200).Revision())
+
+	// replace switches after block height
+	ctx.SetRewardPolicySwitch(150, Revision8)
+	assert.Equal(t, 2, len(ctx.rewardPolicySwitches))
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(200).Revision())
+}
+
+func TestRewardPolicy_updateRevision(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	setRevision(ctx, RevisionMin-1)
+	ctx.DB.setCalcDoneBH(100)
+
+	header := &IISSHeader{Version: IISSDataVersion, BlockHeight: 200, Revision: Revision8, RevisionBlockHeight: 150}
+
+	// switch in the term
+	ctx.updateRevision(header, header.BlockHeight)
+	assert.Equal(t, Revision8, ctx.Revision)
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(0).Revision())
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(149).Revision())
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(150).Revision())
+
+	// calculation again without switch drops the switch in the term
+	header.RevisionBlockHeight = 0
+	ctx.updateRevision(header, header.BlockHeight)
+	assert.Equal(t, 2, len(ctx.rewardPolicySwitches))
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(99).Revision())
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(100).Revision())
+
+	// revision of the next term applies to the whole term. previous terms keep their policy
+	ctx.DB.setCalcDoneBH(200)
+	header.BlockHeight = 300
+	header.Revision = RevisionMin - 1
+	ctx.updateRevision(header, header.BlockHeight)
+	assert.Equal(t, RevisionMin-1, ctx.Revision)
+	assert.Equal(t, 3, len(ctx.rewardPolicySwitches))
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(0).Revision())
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(199).Revision())
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(200).Revision())
+
+	// same revision makes no switch
+	ctx.DB.setCalcDoneBH(300)
+	header.BlockHeight = 400
+	ctx.updateRevision(header, header.BlockHeight)
+	assert.Equal(t, 3, len(ctx.rewardPolicySwitches))
+}
+
+func TestRewardPolicy_RollbackAndRecalculate(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	setRevision(ctx, RevisionMin-1)
+
+	// term (100, 200] switches to revision 8 at 150
+	ctx.DB.setCalcDoneBH(100)
+	ctx.updateRevision(&IISSHeader{BlockHeight: 200, Revision: Revision8, RevisionBlockHeight: 150}, 200)
+	ctx.DB.setCalcDoneBH(200)
+
+	// term (200, 300] switches back to the previous revision
+	ctx.updateRevision(&IISSHeader{BlockHeight: 300, Revision: RevisionMin - 1}, 300)
+	ctx.DB.setCalcDoneBH(300)
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(250).Revision())
+
+	// rollback to 200
+	ctx.DB.setCalcDoneBH(200)
+	ctx.RollbackManagementDB(250)
+	assert.Equal(t, Revision8, ctx.Revision)
+	assert.Equal(t, 2, len(ctx.rewardPolicySwitches))
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(250).Revision())
+
+	// rollback to 100
+	ctx.DB.setCalcDoneBH(100)
+	ctx.RollbackManagementDB(120)
+	assert.Equal(t, RevisionMin-1, ctx.Revision)
+	assert.Equal(t, 1, len(ctx.rewardPolicySwitches))
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(150).Revision())
+
+	// recalculate (100, 200] with revision 8 for the whole term
+	ctx.updateRevision(&IISSHeader{BlockHeight: 200, Revision: Revision8}, 200)
+	assert.Equal(t, Revision8, ctx.Revision)
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(99).Revision())
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(100).Revision())
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(150).Revision())
+}
+
+func TestRewardPolicy_restart(t *testing.T) {
+	ctx := initTest(1)
+	defer func() { finalizeTest(ctx) }()
+	setRevision(ctx, RevisionMin-1)
+
+	// term (100, 200] switches to revision 8 at 150
+	ctx.DB.setCalcDoneBH(100)
+	ctx.updateRevision(&IISSHeader{BlockHeight: 200, Revision: Revision8, RevisionBlockHeight: 150}, 200)
+	ctx.DB.setCalcDoneBH(200)
+
+	// switches are loaded after restart
+	ctx = reopenContext(ctx, 1)
+	assert.Equal(t, Revision8, ctx.Revision)
+	assert.Equal(t, 2, len(ctx.rewardPolicySwitches))
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(149).Revision())
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(150).Revision())
+
+	// term (200, 300] switches back to the previous revision
+	ctx.updateRevision(&IISSHeader{BlockHeight: 300, Revision: RevisionMin - 1}, 300)
+	ctx.DB.setCalcDoneBH(300)
+	ctx = reopenContext(ctx, 1)
+	assert.Equal(t, RevisionMin-1, ctx.Revision)
+	assert.Equal(t, 3, len(ctx.rewardPolicySwitches))
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(199).Revision())
+	assert.Equal(t, uint64(0), ctx.getRewardPolicy(200).Revision())
+
+	// rollback after restart deletes switches of rolled back terms from DB
+	ctx.DB.setCalcDoneBH(200)
+	ctx.RollbackManagementDB(250)
+	ctx = reopenContext(ctx, 1)
+	assert.Equal(t, Revision8, ctx.Revision)
+	assert.Equal(t, 2, len(ctx.rewardPolicySwitches))
+	assert.Equal(t, Revision8, ctx.getRewardPolicy(250).Revision())
+}
+
+func TestRewardPolicy_forEachRewardPeriod(t *testing.T) {
+	type period struct {
+		s, e     uint64
+		gv       uint64
+		revision uint64
+	}
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	setRevision(ctx, RevisionMin-1)
+
+	for _, bh := range []uint64{0, 100} {
+		gv := new(GovernanceVariable)
+		gv.BlockHeight = bh
+		ctx.GV = append(ctx.GV, gv)
+	}
+	ctx.SetRewardPolicySwitch(50, Revision8)
+	ctx.SetRewardPolicySwitch(100, RevisionMin-1)
+	ctx.SetRewardPolicySwitch(150, Revision8)
+
+	periods := make([]period, 0)
+	ctx.forEachRewardPeriod(10, 200, func(s uint64, e uint64, gv *GovernanceVariable, policy RewardPolicy) {
+		periods = append(periods, period{s, e, gv.BlockHeight, policy.Revision()})
+	})
+	assert.Equal(t, []period{
+		{10, 50, 0, 0},
+		{50, 100, 0, Revision8},
+		{100, 150, 100, 0},
+		{150, 200, 100, Revision8},
+	}, periods)
+}
+
+func TestRewardPolicy_calculateDelegationReward(t *testing.T) {
+	const (
+		switchBH uint64 = 100
+		endBH    uint64 = 200
+	)
+	defer setTestPolicy()()
+
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	setRevision(ctx, Revision8)
+
+	gv := new(GovernanceVariable)
+	gv.RewardRep.SetUint64(testNetwork.MinRewardRep)
+	ctx.GV = append(ctx.GV, gv)
+
+	prep := new(PRepCandidate)
+	prep.Address = *common.NewAddressFromString("hxaa")
+	ctx.PRepCandidates[prep.Address] = prep
+
+	delegation := testNetwork.MinDelegation() * 10
+	dg := new(DelegateData)
+	dg.Address = prep.Address
+	dg.Delegate.SetUint64(delegation)
+	address := *common.NewAddressFromString("hx11")
+
+	reward := calculateDelegationReward(ctx, dg, 0, endBH, prep, address)
+	assert.Equal(t, delegation*testNetwork.MinRewardRep*endBH/testNetwork.RewardDivider(), reward.Uint64())
+
+	// delegation reward is doubled after switch
+	ctx.SetRewardPolicySwitch(switchBH, testPolicyRevision)
+	reward = calculateDelegationReward(ctx, dg, 0, endBH, prep, address)
+	expected := delegation * testNetwork.MinRewardRep * (switchBH + 2*(endBH-switchBH)) / testNetwork.RewardDivider()
+	assert.Equal(t, expected, reward.Uint64())
+
+	// not enough delegation
+	dg.Delegate.SetUint64(testNetwork.MinDelegation() - 1)
+	reward = calculateDelegationReward(ctx, dg, 0, endBH, prep, address)
+	assert.Equal(t, 0, reward.Sign())
+}